@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var availabilityCmd = &cobra.Command{
+	Use:   "availability",
+	Short: "List rooms free for the dates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, _ := cmd.Flags().GetString("start")
+		dateStart, err := parseDate("date_start", start)
+		if err != nil {
+			return err
+		}
+		end, _ := cmd.Flags().GetString("end")
+		dateEnd, err := parseDate("date_end", end)
+		if err != nil {
+			return err
+		}
+
+		rooms, err := services.Room.GetAvailable(cmd.Context(), dateStart, dateEnd)
+		if err != nil {
+			return err
+		}
+
+		return renderRooms(rooms)
+	},
+}
+
+func init() {
+	availabilityCmd.Flags().String("start", "", "start date, YYYY-MM-DD")
+	availabilityCmd.Flags().String("end", "", "end date, YYYY-MM-DD")
+	availabilityCmd.MarkFlagRequired("start")
+	availabilityCmd.MarkFlagRequired("end")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+var bookingsCmd = &cobra.Command{
+	Use:   "bookings",
+	Short: "Manage bookings",
+}
+
+var bookingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List bookings of a room or deleted bookings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deleted, _ := cmd.Flags().GetBool("deleted")
+		roomId, _ := cmd.Flags().GetInt("room")
+
+		var bookings []*model.Booking
+		var err error
+		if deleted {
+			bookings, err = services.Booking.GetDeleted(cmd.Context())
+		} else {
+			bookings, err = services.Booking.GetByRoomId(cmd.Context(), roomId)
+		}
+		if err != nil {
+			return err
+		}
+
+		return renderBookings(bookings)
+	},
+}
+
+var bookingsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a booking",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		booking := &model.Booking{}
+		booking.RoomId, _ = cmd.Flags().GetInt("room")
+		if cmd.Flags().Changed("guest") {
+			guestId, _ := cmd.Flags().GetInt("guest")
+			booking.GuestId = &guestId
+		}
+
+		var err error
+		start, _ := cmd.Flags().GetString("start")
+		if booking.DateStart, err = parseDate("date_start", start); err != nil {
+			return err
+		}
+		end, _ := cmd.Flags().GetString("end")
+		if booking.DateEnd, err = parseDate("date_end", end); err != nil {
+			return err
+		}
+
+		id, err := services.Booking.Create(cmd.Context(), booking)
+		if err != nil {
+			return err
+		}
+
+		return renderId("booking_id", id)
+	},
+}
+
+var bookingsCancelCmd = &cobra.Command{
+	Use:   "cancel BOOKING_ID",
+	Short: "Cancel a booking, it can be restored until purged",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return ErrWrongBookingId
+		}
+
+		operator, _ := cmd.Flags().GetString("operator")
+		if err := services.Booking.Delete(cmd.Context(), id, operator); err != nil {
+			return err
+		}
+
+		return renderOK()
+	},
+}
+
+func init() {
+	bookingsListCmd.Flags().Int("room", 0, "room id")
+	bookingsListCmd.Flags().Bool("deleted", false, "list deleted bookings instead")
+
+	bookingsCreateCmd.Flags().Int("room", 0, "room id")
+	bookingsCreateCmd.Flags().Int("guest", 0, "guest id")
+	bookingsCreateCmd.Flags().String("start", "", "start date, YYYY-MM-DD")
+	bookingsCreateCmd.Flags().String("end", "", "end date, YYYY-MM-DD")
+	bookingsCreateCmd.MarkFlagRequired("room")
+	bookingsCreateCmd.MarkFlagRequired("start")
+	bookingsCreateCmd.MarkFlagRequired("end")
+
+	bookingsCancelCmd.Flags().String("operator", os.Getenv("USER"), "operator cancelling the booking")
+
+	bookingsCmd.AddCommand(bookingsListCmd, bookingsCreateCmd, bookingsCancelCmd)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage API keys",
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keys, err := services.APIKey.GetAll(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return renderKeys(keys)
+	},
+}
+
+var keysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key, shown only once",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := &model.APIKey{}
+		key.Name, _ = cmd.Flags().GetString("name")
+		key.Role, _ = cmd.Flags().GetString("role")
+
+		created, err := services.APIKey.Create(cmd.Context(), key)
+		if err != nil {
+			return err
+		}
+
+		return renderCreatedKey(created)
+	},
+}
+
+var keysBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Create the first admin API key, shown only once",
+	Long: "Create the first admin API key, shown only once. It fails once an active admin key " +
+		"exists, further keys are created with that one.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		created, err := services.APIKey.Bootstrap(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+
+		return renderCreatedKey(created)
+	},
+}
+
+var keysRevokeCmd = &cobra.Command{
+	Use:   "revoke KEY_ID",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return ErrWrongKeyId
+		}
+
+		if err := services.APIKey.Revoke(cmd.Context(), id); err != nil {
+			return err
+		}
+
+		return renderOK()
+	},
+}
+
+func init() {
+	keysCreateCmd.Flags().String("name", "", "who or what uses the key")
+	keysCreateCmd.Flags().String("role", model.RoleReadOnly, "role: admin, front_desk or read_only")
+
+	keysBootstrapCmd.Flags().String("name", "admin", "who or what uses the key")
+
+	keysCmd.AddCommand(keysListCmd, keysCreateCmd, keysBootstrapCmd, keysRevokeCmd)
+}
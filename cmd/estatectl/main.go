@@ -0,0 +1,79 @@
+// Command estatectl is an admin tool working with rooms, bookings and API
+// keys directly through the service layer.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/architectv/estate-task/pkg/config"
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/service"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configFile string
+	profile    string
+	output     string
+
+	db       *sqlx.DB
+	services *service.Service
+)
+
+var rootCmd = &cobra.Command{
+	Use:          "estatectl",
+	Short:        "Manage hotel rooms and bookings",
+	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// check flags before connecting to the database
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return err
+		}
+		if output != tableOutput && output != jsonOutput {
+			return errWrongOutput
+		}
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		// the memory driver keeps data inside the server process
+		if cfg.DB.Driver == repository.MemoryDriver {
+			return fmt.Errorf("estatectl cannot work with the %s driver", cfg.DB.Driver)
+		}
+
+		db, err = repository.NewDB(cfg.DB.Driver, cfg.DB.Repository())
+		if err != nil {
+			return err
+		}
+		repos := repository.NewRepository(db, cfg.DB.TxConfig(), nil)
+		if cfg.DB.Driver == repository.PostgresDriver {
+			// nothing is cached here, but the servers learn about room changes
+			repos.Room = repository.NewRoomCache(repos.Room, 0, repository.NewNotifierPostgres(db))
+		}
+		services = service.NewService(repos, cfg.Webhooks.WebhookConfig(), cfg.Outbox.OutboxConfig())
+
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		return db.Close()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"config file (default configs/config.yml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "",
+		"config profile merged over the config file, like dev, test or prod")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", tableOutput,
+		"output format: table or json")
+
+	rootCmd.AddCommand(roomsCmd, bookingsCmd, availabilityCmd, keysCmd)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
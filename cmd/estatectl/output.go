@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+var errWrongOutput = errors.New("output should be table or json")
+
+// render prints value as indented JSON or header and rows as a table.
+func render(value interface{}, header []string, rows [][]string) error {
+	if output == jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(value)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+func renderRooms(rooms []*model.Room) error {
+	rows := make([][]string, 0, len(rooms))
+	for _, room := range rooms {
+		rows = append(rows, []string{
+			strconv.Itoa(room.Id),
+			room.Description,
+			strconv.Itoa(room.Price),
+		})
+	}
+
+	if rooms == nil {
+		rooms = []*model.Room{}
+	}
+	return render(rooms, []string{"ROOM_ID", "DESCRIPTION", "PRICE"}, rows)
+}
+
+func renderBookings(bookings []*model.Booking) error {
+	rows := make([][]string, 0, len(bookings))
+	for _, booking := range bookings {
+		guestId, deletedAt, deletedBy := "", "", ""
+		if booking.GuestId != nil {
+			guestId = strconv.Itoa(*booking.GuestId)
+		}
+		if booking.DeletedAt != nil {
+			deletedAt = booking.DeletedAt.Format(time.RFC3339)
+		}
+		if booking.DeletedBy != nil {
+			deletedBy = *booking.DeletedBy
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(booking.Id),
+			strconv.Itoa(booking.RoomId),
+			guestId,
+			booking.DateStart.Format(model.DateFormat),
+			booking.DateEnd.Format(model.DateFormat),
+			deletedAt,
+			deletedBy,
+		})
+	}
+
+	return render(model.WithRoom(bookings),
+		[]string{"BOOKING_ID", "ROOM_ID", "GUEST_ID", "DATE_START", "DATE_END", "DELETED_AT", "DELETED_BY"},
+		rows)
+}
+
+func renderKeys(keys []*model.APIKey) error {
+	rows := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		revokedAt := ""
+		if key.RevokedAt != nil {
+			revokedAt = key.RevokedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(key.Id),
+			key.Name,
+			key.Role,
+			key.Prefix,
+			key.CreatedAt.Format(time.RFC3339),
+			revokedAt,
+		})
+	}
+
+	if keys == nil {
+		keys = []*model.APIKey{}
+	}
+	return render(keys, []string{"KEY_ID", "NAME", "ROLE", "PREFIX", "CREATED_AT", "REVOKED_AT"}, rows)
+}
+
+func renderCreatedKey(key *model.APIKey) error {
+	return render(key, []string{"KEY_ID", "NAME", "ROLE", "KEY"},
+		[][]string{{strconv.Itoa(key.Id), key.Name, key.Role, key.Key}})
+}
+
+func renderId(field string, id int) error {
+	return render(map[string]int{field: id}, []string{strings.ToUpper(field)},
+		[][]string{{strconv.Itoa(id)}})
+}
+
+func renderOK() error {
+	return render("OK", []string{"OK"}, nil)
+}
+
+func parseDate(field, value string) (time.Time, error) {
+	date, err := time.Parse(model.DateFormat, value)
+	if err != nil {
+		return time.Time{}, ErrWrongDateFormat.WithField(field)
+	}
+
+	return date, nil
+}
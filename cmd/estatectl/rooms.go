@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+var roomsCmd = &cobra.Command{
+	Use:   "rooms",
+	Short: "Manage rooms",
+}
+
+var roomsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List rooms",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sortField, _ := cmd.Flags().GetString("sort")
+		rooms, err := services.Room.GetAll(cmd.Context(), sortField)
+		if err != nil {
+			return err
+		}
+
+		return renderRooms(rooms)
+	},
+}
+
+var roomsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a room",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		room := &model.Room{}
+		room.Description, _ = cmd.Flags().GetString("description")
+		room.Price, _ = cmd.Flags().GetInt("price")
+
+		id, err := services.Room.Create(cmd.Context(), room)
+		if err != nil {
+			return err
+		}
+
+		return renderId("room_id", id)
+	},
+}
+
+var roomsDeleteCmd = &cobra.Command{
+	Use:   "delete ROOM_ID",
+	Short: "Delete a room with its bookings",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return ErrWrongRoomId
+		}
+
+		if err := services.Room.Delete(cmd.Context(), id); err != nil {
+			return err
+		}
+
+		return renderOK()
+	},
+}
+
+func init() {
+	roomsListCmd.Flags().String("sort", "", "sort field: id or price, prefixed with - for descending order")
+
+	roomsCreateCmd.Flags().String("description", "", "room description")
+	roomsCreateCmd.Flags().Int("price", 0, "price per night")
+
+	roomsCmd.AddCommand(roomsListCmd, roomsCreateCmd, roomsDeleteCmd)
+}
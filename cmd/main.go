@@ -1,70 +1,322 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/architectv/estate-task/pkg/config"
 	"github.com/architectv/estate-task/pkg/handler"
+	"github.com/architectv/estate-task/pkg/metrics"
 	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/rpc"
 	"github.com/architectv/estate-task/pkg/service"
+	"github.com/architectv/estate-task/pkg/tracing"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
 func main() {
 	logrus.SetFormatter(new(logrus.JSONFormatter))
 
-	if err := initConfig(); err != nil {
+	configFile := flag.String("config", "", "config file (default configs/config.yml)")
+	profile := flag.String("profile", "", "config profile merged over the config file, like dev, test or prod")
+	flag.Parse()
+	args := flag.Args()
+
+	cfg, err := config.Load(*configFile, *profile)
+	if err != nil {
 		logrus.Fatalf("error initializing configs: %s", err.Error())
 	}
 
-	db, err := repository.NewPostgresDB(repository.Config{
-		Host:     viper.GetString("db.host"),
-		Port:     viper.GetString("db.port"),
-		Username: viper.GetString("db.username"),
-		DBName:   viper.GetString("db.dbname"),
-		SSLMode:  viper.GetString("db.sslmode"),
-		Password: viper.GetString("db.password"),
-	})
+	shutdownTracing, err := tracing.Init(cfg.Tracing.TracingConfig())
+	if err != nil {
+		logrus.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+
+	var db *sqlx.DB
+	var replicas *repository.Replicas
+	var repos *repository.Repository
+	switch driver := cfg.DB.Driver; driver {
+	case repository.MemoryDriver:
+		if len(args) > 0 && args[0] == "migrate" {
+			logrus.Fatalf("failed to migrate: the %s driver has no migrations", driver)
+		}
+		logrus.Warnf("using the %s driver, data will be lost on exit", driver)
+		repos = repository.NewMemoryRepository()
+	default:
+		db, err = repository.NewDB(driver, cfg.DB.Repository())
+		if err != nil {
+			logrus.Fatalf("failed to initialize db: %s", err.Error())
+		}
+
+		if len(args) > 0 && args[0] == "migrate" {
+			err := runMigrate(db, args[1:])
+			db.Close()
+			if err != nil {
+				logrus.Fatalf("failed to migrate: %s", err.Error())
+			}
+			return
+		}
+
+		if cfg.DB.MigrateOnStart {
+			if err := migrateOnStart(db); err != nil {
+				logrus.Fatalf("failed to apply migrations: %s", err.Error())
+			}
+		}
+		if len(cfg.DB.Replicas) > 0 {
+			replicas, err = repository.NewReplicas(cfg.DB.Repository(), cfg.DB.Replicas)
+			if err != nil {
+				logrus.Fatalf("failed to initialize replicas: %s", err.Error())
+			}
+			for name, replica := range replicas.DBs() {
+				if err := metrics.RegisterDB(replica.DB, name); err != nil {
+					logrus.Fatalf("failed to register replica metrics: %s", err.Error())
+				}
+			}
+		}
+		repos = repository.NewRepository(db, cfg.DB.TxConfig(), replicas)
+
+		dbName := cfg.DB.DBName
+		if driver == repository.SQLiteDriver {
+			dbName = cfg.DB.Path
+		}
+		if err := metrics.RegisterDB(db.DB, dbName); err != nil {
+			logrus.Fatalf("failed to register db metrics: %s", err.Error())
+		}
+	}
+
+	repos = repository.Instrument(repos)
+	stopRoomCache, err := cacheRooms(repos, db, cfg)
 	if err != nil {
-		logrus.Fatalf("failed to initialize db: %s", err.Error())
+		logrus.Fatalf("failed to initialize room cache: %s", err.Error())
 	}
 
-	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
+	services := service.NewService(repos, cfg.Webhooks.WebhookConfig(), cfg.Outbox.OutboxConfig())
+	if cfg.DB.Driver == repository.MemoryDriver {
+		// estatectl cannot reach the memory driver to bootstrap a key
+		key, err := services.APIKey.Bootstrap(context.Background(), "admin")
+		if err != nil {
+			logrus.Fatalf("failed to create admin API key: %s", err.Error())
+		}
+		// the key is kept out of the log, which may be shipped elsewhere
+		fmt.Fprintf(os.Stderr, "created admin API key %s, it is not shown again\n", key.Key)
+	}
 	handlers := handler.NewHandler(services)
 
-	app := fiber.New()
-	app.Use(logger.New())
+	app := fiber.New(fiber.Config{ErrorHandler: handler.ErrorHandler})
+	app.Use(handler.RequestID)
+	app.Use(handler.AccessLog)
+	app.Use(handler.Tracing)
+	app.Use(handler.Metrics)
+	app.Use(handler.Timeout(cfg.RequestTimeout))
+	app.Use(handler.ReadPrimary)
 	handlers.InitRoutes(app)
 
 	go func() {
-		if err := app.Listen(viper.GetString("port")); err != nil {
+		if err := app.Listen(cfg.Port); err != nil {
 			logrus.Fatalf("failed to listen: %s", err.Error())
 		}
 	}()
 
+	grpcServer := rpc.NewServer(services)
+	listener, err := net.Listen("tcp", cfg.GRPC.Port)
+	if err != nil {
+		logrus.Fatalf("failed to listen grpc: %s", err.Error())
+	}
+
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logrus.Fatalf("failed to serve grpc: %s", err.Error())
+		}
+	}()
+
 	logrus.Println("App started")
 
+	jobsDone := make(chan struct{})
+	go runPeriodically(cfg.Bookings.PurgeInterval, jobsDone, func() {
+		purgeDeletedBookings(services.Booking, cfg.Bookings.Retention)
+	})
+	go runPeriodically(cfg.Idempotency.PurgeInterval, jobsDone, func() {
+		purgeIdempotencyKeys(services.Idempotency, cfg.Idempotency.TTL)
+	})
+	go runPeriodically(cfg.Outbox.PollInterval, jobsDone, func() {
+		relayOutbox(services.Outbox, cfg.Outbox.BatchSize)
+	})
+	go runPeriodically(cfg.Outbox.PurgeInterval, jobsDone, func() {
+		purgePublishedEvents(services.Outbox, cfg.Outbox.Retention)
+	})
+	go runPeriodically(cfg.Webhooks.PollInterval, jobsDone, func() {
+		deliverWebhooks(services.Webhook, cfg.Webhooks.BatchSize)
+	})
+	if db != nil {
+		pool := repository.NewPoolWatcher(db.DB)
+		go runPeriodically(cfg.DB.PoolCheckInterval, jobsDone, func() {
+			warnPoolSaturation(pool)
+		})
+	}
+	if replicas != nil {
+		go runPeriodically(cfg.DB.ReplicaCheckInterval, jobsDone, func() {
+			replicas.Check(context.Background())
+		})
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
 	logrus.Println("Gracefully shutting down...")
+	close(jobsDone)
+
+	// fail readiness first, so that load balancers stop sending requests
+	// before the servers stop accepting them
+	services.Health.Drain()
+	time.Sleep(cfg.ShutdownDelay)
+
 	if err := app.Shutdown(); err != nil {
 		logrus.Errorf("error occurred on server shutting down: %s", err.Error())
 	}
+	grpcServer.GracefulStop()
+
+	if err := stopRoomCache(); err != nil {
+		logrus.Errorf("error occurred on room cache shutdown: %s", err.Error())
+	}
 
-	if err := db.Close(); err != nil {
-		logrus.Errorf("error occurred on db connection close: %s", err.Error())
+	if replicas != nil {
+		if err := replicas.Close(); err != nil {
+			logrus.Errorf("error occurred on replica connections close: %s", err.Error())
+		}
+	}
+
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logrus.Errorf("error occurred on db connection close: %s", err.Error())
+		}
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		logrus.Errorf("error occurred on tracing shutdown: %s", err.Error())
+	}
+}
+
+// cacheRooms puts the room cache in front of the room repository. With
+// Postgres the instances invalidate each other's caches, the returned
+// function stops listening for their changes.
+func cacheRooms(repos *repository.Repository, db *sqlx.DB, cfg *config.Config) (func() error, error) {
+	stop := func() error { return nil }
+	if cfg.RoomCache.TTL <= 0 {
+		return stop, nil
+	}
+
+	if db == nil || db.DriverName() != repository.PostgresDriver {
+		// the other drivers serve a single instance
+		repos.Room = repository.NewRoomCache(repos.Room, cfg.RoomCache.TTL, nil)
+		return stop, nil
+	}
+
+	cache := repository.NewRoomCache(repos.Room, cfg.RoomCache.TTL, repository.NewNotifierPostgres(db))
+	stop, err := repository.ListenRoomChanges(cfg.DB.Repository(), cache)
+	if err != nil {
+		return nil, err
+	}
+	repos.Room = cache
+
+	return stop, nil
+}
+
+// runPeriodically calls job every interval until done is closed.
+func runPeriodically(interval time.Duration, done <-chan struct{}, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
+
+// purgeDeletedBookings removes bookings that have been soft deleted
+// for longer than the retention period.
+func purgeDeletedBookings(bookings service.Booking, retention time.Duration) {
+	purged, err := bookings.Purge(context.Background(), retention)
+	if err != nil {
+		logrus.Errorf("failed to purge deleted bookings: %s", err.Error())
+		return
+	}
+	if purged > 0 {
+		logrus.Printf("Purged %d deleted bookings", purged)
 	}
 }
 
-func initConfig() error {
-	viper.AddConfigPath("configs")
-	viper.SetConfigName("config")
-	return viper.ReadInConfig()
+// purgeIdempotencyKeys removes idempotency keys older than ttl.
+func purgeIdempotencyKeys(idempotency service.Idempotency, ttl time.Duration) {
+	purged, err := idempotency.Purge(context.Background(), ttl)
+	if err != nil {
+		logrus.Errorf("failed to purge idempotency keys: %s", err.Error())
+		return
+	}
+	if purged > 0 {
+		logrus.Printf("Purged %d idempotency keys", purged)
+	}
+}
+
+// relayOutbox publishes due outbox events until none are left.
+func relayOutbox(outbox service.Outbox, batchSize int) {
+	for {
+		claimed, err := outbox.Relay(context.Background())
+		if err != nil {
+			logrus.Errorf("failed to relay outbox events: %s", err.Error())
+			return
+		}
+		if claimed < batchSize {
+			return
+		}
+	}
+}
+
+// purgePublishedEvents removes outbox events published longer than
+// retention ago.
+func purgePublishedEvents(outbox service.Outbox, retention time.Duration) {
+	purged, err := outbox.Purge(context.Background(), retention)
+	if err != nil {
+		logrus.Errorf("failed to purge outbox events: %s", err.Error())
+		return
+	}
+	if purged > 0 {
+		logrus.Printf("Purged %d outbox events", purged)
+	}
+}
+
+// deliverWebhooks sends due webhook deliveries until none are left.
+func deliverWebhooks(webhooks service.Webhook, batchSize int) {
+	for {
+		attempted, err := webhooks.Deliver(context.Background())
+		if err != nil {
+			logrus.Errorf("failed to deliver webhooks: %s", err.Error())
+			return
+		}
+		if attempted < batchSize {
+			return
+		}
+	}
+}
+
+// warnPoolSaturation warns when queries waited for a free connection of
+// the database pool since the last check.
+func warnPoolSaturation(pool *repository.PoolWatcher) {
+	waits, waited, stats := pool.Waited()
+	if waits > 0 {
+		logrus.Warnf("db pool saturated: %d queries waited %s for a connection, %d of %d connections in use",
+			waits, waited, stats.InUse, stats.MaxOpenConnections)
+	}
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+var errMigrateUsage = errors.New("usage: app migrate up|down|status|to N")
+
+// runMigrate runs the migrate subcommand and prints the resulting status.
+func runMigrate(db *sqlx.DB, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up()
+	case args[0] == "down" && len(args) == 1:
+		err = migrator.Down()
+	case args[0] == "to" && len(args) == 2:
+		version, parseErr := strconv.ParseUint(args[1], 10, 0)
+		if parseErr != nil {
+			return errMigrateUsage
+		}
+		err = migrator.To(uint(version))
+	case args[0] == "status" && len(args) == 1:
+	default:
+		return errMigrateUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	return printMigrationStatus(migrator)
+}
+
+func printMigrationStatus(migrator *repository.Migrator) error {
+	version, dirty, migrations, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		fmt.Printf("version: %d (dirty, fix the schema and run migrate to)\n", version)
+	} else {
+		fmt.Printf("version: %d\n", version)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, migration := range migrations {
+		status := "pending"
+		if migration.Applied {
+			status = "applied"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", migration.Version, migration.Name, status)
+	}
+
+	return w.Flush()
+}
+
+// migrateOnStart applies pending migrations before the servers start.
+func migrateOnStart(db *sqlx.DB) error {
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up()
+}
@@ -0,0 +1,63 @@
+// Package broker hands events over to message brokers like NATS or Kafka.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// AllTopics subscribes a handler to the messages of every topic.
+const AllTopics = "*"
+
+// Message is published to a topic. Messages with the same key must reach
+// subscribers in the order they were published, like a Kafka partition key.
+type Message struct {
+	Topic string
+	Key   string
+	Data  []byte
+}
+
+// Broker publishes messages. Publish returns once the broker has taken
+// the message, an error makes the caller publish it again.
+type Broker interface {
+	Publish(ctx context.Context, message *Message) error
+}
+
+// Handler processes a message received from a broker.
+type Handler func(ctx context.Context, message *Message) error
+
+// Local is a broker delivering messages to the handlers subscribed in the
+// same process. Handlers are called one by one while the message is
+// published, so a failed handler fails the publish.
+type Local struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewLocal() *Local {
+	return &Local{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe calls handler with every message published to topic, or to
+// any topic for AllTopics.
+func (b *Local) Subscribe(topic string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[topic] = append(b.handlers[topic], handler)
+}
+
+func (b *Local) Publish(ctx context.Context, message *Message) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[message.Topic]...), b.handlers[AllTopics]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, message); err != nil {
+			return fmt.Errorf("handle %s message: %w", message.Topic, err)
+		}
+	}
+
+	return nil
+}
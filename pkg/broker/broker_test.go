@@ -0,0 +1,39 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal_Publish(t *testing.T) {
+	b := NewLocal()
+
+	var got []string
+	b.Subscribe("room.created", func(ctx context.Context, message *Message) error {
+		got = append(got, "room:"+string(message.Data))
+		return nil
+	})
+	b.Subscribe(AllTopics, func(ctx context.Context, message *Message) error {
+		got = append(got, "all:"+string(message.Data))
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &Message{Topic: "room.created", Key: "room:1", Data: []byte("1")}))
+	assert.NoError(t, b.Publish(context.Background(), &Message{Topic: "booking.created", Key: "booking:2", Data: []byte("2")}))
+	assert.Equal(t, []string{"room:1", "all:1", "all:2"}, got)
+}
+
+func TestLocal_PublishError(t *testing.T) {
+	b := NewLocal()
+	failure := errors.New("failure")
+	b.Subscribe("room.created", func(ctx context.Context, message *Message) error {
+		return failure
+	})
+
+	err := b.Publish(context.Background(), &Message{Topic: "room.created"})
+	assert.ErrorIs(t, err, failure)
+	assert.NoError(t, b.Publish(context.Background(), &Message{Topic: "room.deleted"}))
+}
@@ -0,0 +1,431 @@
+// Package config loads the settings of the app and estatectl.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/service"
+	"github.com/architectv/estate-task/pkg/tracing"
+	"github.com/spf13/viper"
+)
+
+const (
+	// EnvPrefix starts the environment variables overriding config keys,
+	// like ESTATE_DB_PASSWORD for db.password.
+	EnvPrefix = "ESTATE"
+	// fileSuffix ends the variables naming a file to read a key from,
+	// like ESTATE_DB_PASSWORD_FILE for Docker secrets.
+	fileSuffix = "_FILE"
+	// profileEnv selects the profile when none is given.
+	profileEnv = EnvPrefix + "_PROFILE"
+)
+
+type Config struct {
+	Port           string        `mapstructure:"port"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	ShutdownDelay  time.Duration `mapstructure:"shutdown_delay"`
+
+	GRPC        GRPC        `mapstructure:"grpc"`
+	Bookings    Bookings    `mapstructure:"bookings"`
+	Idempotency Idempotency `mapstructure:"idempotency"`
+	RoomCache   RoomCache   `mapstructure:"room_cache"`
+	Webhooks    Webhooks    `mapstructure:"webhooks"`
+	Outbox      Outbox      `mapstructure:"outbox"`
+	Tracing     Tracing     `mapstructure:"tracing"`
+	DB          DB          `mapstructure:"db"`
+}
+
+type GRPC struct {
+	Port string `mapstructure:"port"`
+}
+
+type Bookings struct {
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+type Idempotency struct {
+	TTL           time.Duration `mapstructure:"ttl"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+type RoomCache struct {
+	// TTL is how long rooms are cached, zero disables the cache.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+type Webhooks struct {
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	Backoff      time.Duration `mapstructure:"backoff"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+	Lease        time.Duration `mapstructure:"lease"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+type Outbox struct {
+	// Sinks are the sinks events are relayed to: log, webhook or broker.
+	Sinks         []string      `mapstructure:"sinks"`
+	Backoff       time.Duration `mapstructure:"backoff"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+	Lease         time.Duration `mapstructure:"lease"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+	Retention     time.Duration `mapstructure:"retention"`
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+}
+
+type Tracing struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type DB struct {
+	Driver         string `mapstructure:"driver"`
+	Host           string `mapstructure:"host"`
+	Port           string `mapstructure:"port"`
+	Username       string `mapstructure:"username"`
+	Password       string `mapstructure:"password"`
+	DBName         string `mapstructure:"dbname"`
+	SSLMode        string `mapstructure:"sslmode"`
+	Path           string `mapstructure:"path"`
+	MigrateOnStart bool   `mapstructure:"migrate_on_start"`
+	TxIsolation    string `mapstructure:"tx_isolation"`
+	TxRetries      int    `mapstructure:"tx_retries"`
+
+	// Replicas are the read replicas of the database, as host or
+	// host:port, sharing its other connection settings.
+	Replicas             []string      `mapstructure:"replicas"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+
+	MaxOpenConns      int           `mapstructure:"max_open_conns"`
+	MaxIdleConns      int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
+	StatementTimeout  time.Duration `mapstructure:"statement_timeout"`
+	ConnectTimeout    time.Duration `mapstructure:"connect_timeout"`
+	PoolCheckInterval time.Duration `mapstructure:"pool_check_interval"`
+}
+
+// defaults are used for keys missing from the config files, so that they
+// can still be set from the environment.
+var defaults = map[string]interface{}{
+	"port":                       ":9000",
+	"request_timeout":            "10s",
+	"shutdown_delay":             "5s",
+	"grpc.port":                  ":9090",
+	"bookings.retention":         "720h",
+	"bookings.purge_interval":    "1h",
+	"idempotency.ttl":            "24h",
+	"idempotency.purge_interval": "1h",
+	"room_cache.ttl":             "1m",
+	"webhooks.max_attempts":      8,
+	"webhooks.backoff":           "10s",
+	"webhooks.max_backoff":       "1h",
+	"webhooks.lease":             "2m",
+	"webhooks.batch_size":        10,
+	"webhooks.timeout":           "10s",
+	"webhooks.poll_interval":     "1s",
+	"outbox.sinks":               []string{service.SinkWebhook},
+	"outbox.backoff":             "1s",
+	"outbox.max_backoff":         "5m",
+	"outbox.lease":               "1m",
+	"outbox.batch_size":          100,
+	"outbox.poll_interval":       "1s",
+	"outbox.retention":           "168h",
+	"outbox.purge_interval":      "1h",
+	"tracing.exporter":           tracing.NoneExporter,
+	"tracing.endpoint":           "localhost:4318",
+	"tracing.insecure":           false,
+	"tracing.file":               "traces.json",
+	"tracing.sample_ratio":       1,
+	"db.driver":                  repository.PostgresDriver,
+	"db.host":                    "",
+	"db.port":                    "5432",
+	"db.username":                "",
+	"db.password":                "",
+	"db.dbname":                  "",
+	"db.sslmode":                 "disable",
+	"db.path":                    "estate.db",
+	"db.migrate_on_start":        true,
+	"db.tx_isolation":            "serializable",
+	"db.tx_retries":              3,
+	"db.max_open_conns":          20,
+	"db.max_idle_conns":          10,
+	"db.conn_max_lifetime":       "30m",
+	"db.conn_max_idle_time":      "5m",
+	"db.statement_timeout":       "30s",
+	"db.connect_timeout":         "1m",
+	"db.pool_check_interval":     "1m",
+	"db.replicas":                []string{},
+	"db.replica_check_interval":  "10s",
+}
+
+// Load reads the config file at path, configs/config.yml if path is empty,
+// and merges the file of the profile over it. The profile defaults to
+// ESTATE_PROFILE, and its file sits next to the config file: config.dev.yml
+// for the dev profile. Every key can then be overridden by an environment
+// variable, or by a file named in a variable ending with _FILE.
+func Load(path, profile string) (*Config, error) {
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	if path == "" {
+		v.AddConfigPath("configs")
+		v.SetConfigName("config")
+	} else {
+		v.SetConfigFile(path)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	if profile == "" {
+		profile = os.Getenv(profileEnv)
+	}
+	if profile != "" {
+		if err := mergeProfile(v, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	if err := readSecretFiles(v); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func mergeProfile(v *viper.Viper, profile string) error {
+	used := v.ConfigFileUsed()
+	ext := filepath.Ext(used)
+	path := strings.TrimSuffix(used, ext) + "." + profile + ext
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("read profile %q: %w", profile, err)
+	}
+	defer file.Close()
+
+	if err := v.MergeConfig(file); err != nil {
+		return fmt.Errorf("read profile %q: %w", profile, err)
+	}
+
+	return nil
+}
+
+// readSecretFiles sets every key whose _FILE variable is given to the
+// content of that file.
+func readSecretFiles(v *viper.Viper) error {
+	for _, key := range v.AllKeys() {
+		env := EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		path, ok := os.LookupEnv(env + fileSuffix)
+		if !ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(env); ok {
+			return fmt.Errorf("both %s and %s are set", env, env+fileSuffix)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", env+fileSuffix, err)
+		}
+		v.Set(key, strings.TrimSpace(string(data)))
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, key, problem string) {
+		if !ok {
+			problems = append(problems, key+": "+problem)
+		}
+	}
+
+	check(c.Port != "", "port", "must be set")
+	check(c.RequestTimeout >= 0, "request_timeout", "must not be negative")
+	check(c.ShutdownDelay >= 0, "shutdown_delay", "must not be negative")
+	check(c.GRPC.Port != "", "grpc.port", "must be set")
+	check(c.Bookings.Retention > 0, "bookings.retention", "must be positive")
+	check(c.Bookings.PurgeInterval > 0, "bookings.purge_interval", "must be positive")
+	check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+	check(c.Idempotency.PurgeInterval > 0, "idempotency.purge_interval", "must be positive")
+	check(c.RoomCache.TTL >= 0, "room_cache.ttl", "must not be negative")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts", "must be positive")
+	check(c.Webhooks.Backoff > 0, "webhooks.backoff", "must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.Backoff, "webhooks.max_backoff", "must not be less than webhooks.backoff")
+	check(c.Webhooks.BatchSize > 0, "webhooks.batch_size", "must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout", "must be positive")
+	// a batch is sent one by one and must be done before others may claim it
+	check(c.Webhooks.Lease > c.Webhooks.Timeout*time.Duration(c.Webhooks.BatchSize), "webhooks.lease",
+		"must exceed webhooks.timeout times webhooks.batch_size")
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval", "must be positive")
+	check(len(c.Outbox.Sinks) > 0, "outbox.sinks", "must not be empty")
+	for _, sink := range c.Outbox.Sinks {
+		check(knownSink(sink), "outbox.sinks", fmt.Sprintf("unknown sink %q, want %s", sink,
+			strings.Join(service.SinkNames, ", ")))
+	}
+	check(c.Outbox.Backoff > 0, "outbox.backoff", "must be positive")
+	check(c.Outbox.MaxBackoff >= c.Outbox.Backoff, "outbox.max_backoff", "must not be less than outbox.backoff")
+	check(c.Outbox.Lease > 0, "outbox.lease", "must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size", "must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.poll_interval", "must be positive")
+	check(c.Outbox.Retention > 0, "outbox.retention", "must be positive")
+	check(c.Outbox.PurgeInterval > 0, "outbox.purge_interval", "must be positive")
+
+	switch c.Tracing.Exporter {
+	case tracing.NoneExporter, tracing.StdoutExporter:
+	case tracing.FileExporter:
+		check(c.Tracing.File != "", "tracing.file", "must be set for the file exporter")
+	case tracing.OTLPExporter:
+		check(c.Tracing.Endpoint != "", "tracing.endpoint", "must be set for the otlp exporter")
+	default:
+		check(false, "tracing.exporter", fmt.Sprintf("unknown exporter %q, want none, stdout, file or otlp",
+			c.Tracing.Exporter))
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio",
+		"must be between 0 and 1")
+
+	switch c.DB.Driver {
+	case repository.PostgresDriver:
+		check(c.DB.Host != "", "db.host", "must be set for the postgres driver")
+		check(c.DB.Port != "", "db.port", "must be set for the postgres driver")
+		check(c.DB.Username != "", "db.username", "must be set for the postgres driver")
+		check(c.DB.DBName != "", "db.dbname", "must be set for the postgres driver")
+		switch c.DB.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			check(false, "db.sslmode", fmt.Sprintf("unknown mode %q", c.DB.SSLMode))
+		}
+	case repository.SQLiteDriver:
+		check(c.DB.Path != "", "db.path", "must be set for the sqlite driver")
+	case repository.MemoryDriver:
+	default:
+		check(false, "db.driver", fmt.Sprintf("unknown driver %q, want postgres, sqlite or memory", c.DB.Driver))
+	}
+	if _, err := repository.ParseIsolation(c.DB.TxIsolation); err != nil {
+		check(false, "db.tx_isolation", err.Error())
+	}
+	check(c.DB.TxRetries >= 0, "db.tx_retries", "must not be negative")
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns", "must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns", "must not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns",
+		"must not exceed db.max_open_conns")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime", "must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time", "must not be negative")
+	check(c.DB.StatementTimeout >= 0, "db.statement_timeout", "must not be negative")
+	check(c.DB.ConnectTimeout >= 0, "db.connect_timeout", "must not be negative")
+	check(c.DB.PoolCheckInterval > 0, "db.pool_check_interval", "must be positive")
+	check(len(c.DB.Replicas) == 0 || c.DB.Driver == repository.PostgresDriver, "db.replicas",
+		"are only supported by the postgres driver")
+	for _, replica := range c.DB.Replicas {
+		check(replica != "", "db.replicas", "must not be empty")
+	}
+	check(c.DB.ReplicaCheckInterval > 0, "db.replica_check_interval", "must be positive")
+
+	if len(problems) > 0 {
+		return errors.New("invalid config:\n  " + strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// Repository returns the connection and pool settings of the database.
+func (db DB) Repository() repository.Config {
+	return repository.Config{
+		Host:     db.Host,
+		Port:     db.Port,
+		Username: db.Username,
+		Password: db.Password,
+		DBName:   db.DBName,
+		SSLMode:  db.SSLMode,
+		Path:     db.Path,
+		Pool: repository.Pool{
+			MaxOpenConns:    db.MaxOpenConns,
+			MaxIdleConns:    db.MaxIdleConns,
+			ConnMaxLifetime: db.ConnMaxLifetime,
+			ConnMaxIdleTime: db.ConnMaxIdleTime,
+		},
+		StatementTimeout: db.StatementTimeout,
+		ConnectTimeout:   db.ConnectTimeout,
+	}
+}
+
+// TxConfig returns the transaction settings. The isolation level must
+// have been validated.
+func (db DB) TxConfig() repository.TxConfig {
+	isolation, _ := repository.ParseIsolation(db.TxIsolation)
+
+	return repository.TxConfig{Isolation: isolation, Retries: db.TxRetries}
+}
+
+// WebhookConfig returns the settings of webhook delivery.
+func (w Webhooks) WebhookConfig() service.WebhookConfig {
+	return service.WebhookConfig{
+		MaxAttempts: w.MaxAttempts,
+		Backoff:     w.Backoff,
+		MaxBackoff:  w.MaxBackoff,
+		Lease:       w.Lease,
+		BatchSize:   w.BatchSize,
+		Timeout:     w.Timeout,
+	}
+}
+
+// OutboxConfig returns the settings of the outbox relay.
+func (o Outbox) OutboxConfig() service.OutboxConfig {
+	return service.OutboxConfig{
+		Sinks:      o.Sinks,
+		Backoff:    o.Backoff,
+		MaxBackoff: o.MaxBackoff,
+		Lease:      o.Lease,
+		BatchSize:  o.BatchSize,
+	}
+}
+
+func knownSink(name string) bool {
+	for _, known := range service.SinkNames {
+		if name == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TracingConfig returns the settings of the tracing exporter.
+func (t Tracing) TracingConfig() tracing.Config {
+	return tracing.Config{
+		Exporter:    t.Exporter,
+		Endpoint:    t.Endpoint,
+		Insecure:    t.Insecure,
+		File:        t.File,
+		SampleRatio: t.SampleRatio,
+	}
+}
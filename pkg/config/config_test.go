@@ -0,0 +1,205 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseConfig = `
+port: ":9000"
+
+db:
+    username: "postgres"
+    password: "1234"
+    host: "db"
+    dbname: "postgres"
+`
+
+// writeConfigs writes config.yml and the profile files to a new directory
+// and returns the path of config.yml.
+func writeConfigs(t *testing.T, profiles map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	require.Nil(t, os.WriteFile(path, []byte(baseConfig), 0644))
+	for profile, content := range profiles {
+		require.Nil(t, os.WriteFile(filepath.Join(dir, "config."+profile+".yml"), []byte(content), 0644))
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfigs(t, map[string]string{
+		"dev": "db:\n    host: \"localhost\"\n",
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := Load(path, "")
+		require.Nil(t, err)
+		assert.Equal(t, ":9000", cfg.Port)
+		assert.Equal(t, 10*time.Second, cfg.RequestTimeout)
+		assert.Equal(t, "db", cfg.DB.Host)
+		assert.Equal(t, "5432", cfg.DB.Port)
+		assert.Equal(t, "1234", cfg.DB.Password)
+		assert.True(t, cfg.DB.MigrateOnStart)
+		assert.Equal(t, 20, cfg.DB.MaxOpenConns)
+		assert.Equal(t, time.Minute, cfg.DB.ConnectTimeout)
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		cfg, err := Load(path, "dev")
+		require.Nil(t, err)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+		assert.Equal(t, "postgres", cfg.DB.Username)
+	})
+
+	t.Run("Profile From Env", func(t *testing.T) {
+		t.Setenv("ESTATE_PROFILE", "dev")
+
+		cfg, err := Load(path, "")
+		require.Nil(t, err)
+		assert.Equal(t, "localhost", cfg.DB.Host)
+	})
+
+	t.Run("Unknown Profile", func(t *testing.T) {
+		_, err := Load(path, "staging")
+		assert.Error(t, err)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("ESTATE_DB_PASSWORD", "secret")
+		t.Setenv("ESTATE_DB_MIGRATE_ON_START", "false")
+		t.Setenv("ESTATE_REQUEST_TIMEOUT", "3s")
+		t.Setenv("ESTATE_DB_REPLICAS", "replica1:5432,replica2")
+
+		cfg, err := Load(path, "dev")
+		require.Nil(t, err)
+		assert.Equal(t, "secret", cfg.DB.Password)
+		assert.False(t, cfg.DB.MigrateOnStart)
+		assert.Equal(t, 3*time.Second, cfg.RequestTimeout)
+		assert.Equal(t, []string{"replica1:5432", "replica2"}, cfg.DB.Replicas)
+	})
+
+	t.Run("Secret File", func(t *testing.T) {
+		secret := filepath.Join(t.TempDir(), "db_password")
+		require.Nil(t, os.WriteFile(secret, []byte("secret\n"), 0600))
+		t.Setenv("ESTATE_DB_PASSWORD_FILE", secret)
+
+		cfg, err := Load(path, "")
+		require.Nil(t, err)
+		assert.Equal(t, "secret", cfg.DB.Password)
+	})
+
+	t.Run("Secret File And Env", func(t *testing.T) {
+		t.Setenv("ESTATE_DB_PASSWORD_FILE", "/run/secrets/db_password")
+		t.Setenv("ESTATE_DB_PASSWORD", "secret")
+
+		_, err := Load(path, "")
+		assert.EqualError(t, err, "both ESTATE_DB_PASSWORD and ESTATE_DB_PASSWORD_FILE are set")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv("ESTATE_DB_SSLMODE", "strict")
+		t.Setenv("ESTATE_DB_TX_ISOLATION", "snapshot")
+		t.Setenv("ESTATE_BOOKINGS_PURGE_INTERVAL", "0s")
+
+		_, err := Load(path, "")
+		assert.EqualError(t, err, "invalid config:\n"+
+			"  bookings.purge_interval: must be positive\n"+
+			"  db.sslmode: unknown mode \"strict\"\n"+
+			"  db.tx_isolation: unknown isolation level \"snapshot\"")
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg, err := Load(writeConfigs(t, nil), "")
+	require.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		change  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "SQLite",
+			change: func(cfg *Config) { cfg.DB.Driver, cfg.DB.Host = "sqlite", "" },
+		},
+		{
+			name:   "Memory",
+			change: func(cfg *Config) { cfg.DB.Driver, cfg.DB.Host = "memory", "" },
+		},
+		{
+			name:    "Unknown Driver",
+			change:  func(cfg *Config) { cfg.DB.Driver = "mysql" },
+			wantErr: "invalid config:\n  db.driver: unknown driver \"mysql\", want postgres, sqlite or memory",
+		},
+		{
+			name:    "Replicas Without Postgres",
+			change:  func(cfg *Config) { cfg.DB.Driver, cfg.DB.Replicas = "sqlite", []string{"replica:5432"} },
+			wantErr: "invalid config:\n  db.replicas: are only supported by the postgres driver",
+		},
+		{
+			name: "Pool",
+			change: func(cfg *Config) {
+				cfg.DB.MaxOpenConns = 5
+				cfg.DB.MaxIdleConns = 10
+				cfg.DB.ConnectTimeout = -time.Second
+			},
+			wantErr: "invalid config:\n" +
+				"  db.max_idle_conns: must not exceed db.max_open_conns\n" +
+				"  db.connect_timeout: must not be negative",
+		},
+		{
+			name: "Webhooks",
+			change: func(cfg *Config) {
+				cfg.Webhooks.MaxBackoff = time.Second
+				cfg.Webhooks.Lease = time.Minute
+			},
+			wantErr: "invalid config:\n" +
+				"  webhooks.max_backoff: must not be less than webhooks.backoff\n" +
+				"  webhooks.lease: must exceed webhooks.timeout times webhooks.batch_size",
+		},
+		{
+			name: "Outbox",
+			change: func(cfg *Config) {
+				cfg.Outbox.Sinks = []string{"webhook", "kafka"}
+				cfg.Outbox.MaxBackoff = 0
+			},
+			wantErr: "invalid config:\n" +
+				"  outbox.sinks: unknown sink \"kafka\", want log, webhook, broker\n" +
+				"  outbox.max_backoff: must not be less than outbox.backoff",
+		},
+		{
+			name: "Tracing",
+			change: func(cfg *Config) {
+				cfg.Tracing.Exporter = "otlp"
+				cfg.Tracing.Endpoint = ""
+				cfg.Tracing.SampleRatio = 2
+			},
+			wantErr: "invalid config:\n" +
+				"  tracing.endpoint: must be set for the otlp exporter\n" +
+				"  tracing.sample_ratio: must be between 0 and 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := *cfg
+			test.change(&changed)
+
+			err := changed.Validate()
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
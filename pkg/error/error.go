@@ -1,13 +1,188 @@
 package error
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Error is a domain error with a stable machine-readable code
+// and the HTTP status it is reported with.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Field   string
+	Details map[string]interface{}
+}
+
+func NewError(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// ContextError returns context.DeadlineExceeded in place of err once the
+// deadline of ctx has passed, as database drivers report a cancelled query
+// with errors of their own. Domain errors are kept, and so is err when ctx
+// was merely cancelled, as middlewares cancel it before errors are rendered.
+func ContextError(ctx context.Context, err error) error {
+	var domainErr *Error
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.As(err, &domainErr) {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so that copies made by WithField and
+// WithDetail still match their sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField returns a copy of the error pointing at the given field.
+func (e *Error) WithField(field string) *Error {
+	err := e.clone()
+	err.Field = field
+	return err
+}
+
+// WithDetail returns a copy of the error with an extra detail.
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	err := e.clone()
+	err.Details[key] = value
+	return err
+}
+
+func (e *Error) clone() *Error {
+	err := *e
+	err.Details = make(map[string]interface{}, len(e.Details)+1)
+	for key, value := range e.Details {
+		err.Details[key] = value
+	}
+	return &err
+}
+
+// FieldError is a single failed check reported by ValidationError.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"detail"`
+}
+
+// ValidationError collects every field error found in a request.
+type ValidationError struct {
+	Errors []*Error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Field+": "+err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation or one of the collected errors.
+func (e *ValidationError) Is(target error) bool {
+	if ErrValidation.Is(target) {
+		return true
+	}
+	for _, err := range e.Errors {
+		if err.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap exposes the collected errors as a single ErrValidation
+// listing them in its details.
+func (e *ValidationError) Unwrap() error {
+	fields := make([]FieldError, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		fields = append(fields, FieldError{Field: err.Field, Code: err.Code, Message: err.Message})
+	}
+	return ErrValidation.WithDetail("errors", fields)
+}
 
 var (
-	ErrEmptyDescription = errors.New("description should not be empty")
-	ErrNotPositivePrice = errors.New("price should be positive number")
-	ErrWrongSortField   = errors.New("wrong sort param")
-	ErrWrongRoomId      = errors.New("wrong room_id")
-	ErrWrongDates       = errors.New("date_start should be before date_end")
-	ErrWrongBookingId   = errors.New("wrong booking_id")
-	ErrInternalService  = errors.New("something went wrong")
+	ErrEmptyDescription = NewError("empty_description", http.StatusBadRequest,
+		"description should not be empty").WithField("description")
+	ErrNotPositivePrice = NewError("not_positive_price", http.StatusBadRequest,
+		"price should be positive number").WithField("price")
+	ErrWrongSortField = NewError("wrong_sort_field", http.StatusBadRequest,
+		"wrong sort param").WithField("sort")
+	ErrWrongRoomId = NewError("wrong_room_id", http.StatusBadRequest,
+		"wrong room_id").WithField("room_id")
+	ErrWrongDates = NewError("wrong_dates", http.StatusBadRequest,
+		"date_start should be before date_end").WithField("date_end")
+	ErrWrongBookingId = NewError("wrong_booking_id", http.StatusBadRequest,
+		"wrong booking_id").WithField("booking_id")
+	ErrBookingConflict = NewError("booking_conflict", http.StatusConflict,
+		"booking dates overlap with an existing booking")
+	ErrWrongGuestId = NewError("wrong_guest_id", http.StatusBadRequest,
+		"wrong guest_id").WithField("guest_id")
+	ErrEmptyGuestName = NewError("empty_guest_name", http.StatusBadRequest,
+		"guest name should not be empty").WithField("name")
+	ErrWrongEmail = NewError("wrong_email", http.StatusBadRequest,
+		"wrong email").WithField("email")
+	ErrEmptyGuestContact = NewError("empty_guest_contact", http.StatusBadRequest,
+		"email or phone should be given")
+	ErrGuestAndGuestId = NewError("guest_and_guest_id", http.StatusBadRequest,
+		"only one of guest_id and guest should be given").WithField("guest")
+	ErrWrongBody = NewError("wrong_body", http.StatusBadRequest,
+		"request body is malformed")
+	ErrWrongParam = NewError("wrong_param", http.StatusBadRequest,
+		"request parameter is malformed")
+	ErrValidation = NewError("validation_failed", http.StatusBadRequest,
+		"request validation failed")
+	ErrRequiredField = NewError("required", http.StatusBadRequest,
+		"field is required")
+	ErrUnknownField = NewError("unknown_field", http.StatusBadRequest,
+		"unknown field")
+	ErrWrongFieldType = NewError("wrong_type", http.StatusBadRequest,
+		"field has wrong type")
+	ErrWrongDateFormat = NewError("wrong_date_format", http.StatusBadRequest,
+		"date should be in YYYY-MM-DD format")
+	ErrInternalService = NewError("internal_error", http.StatusInternalServerError,
+		"something went wrong")
+	ErrTimeout = NewError("timeout", http.StatusGatewayTimeout,
+		"request took too long")
+
+	ErrWrongWebhookId = NewError("wrong_webhook_id", http.StatusNotFound,
+		"wrong webhook_id").WithField("webhook_id")
+	ErrWrongDeliveryId = NewError("wrong_delivery_id", http.StatusNotFound,
+		"wrong delivery_id").WithField("delivery_id")
+	ErrWrongWebhookURL = NewError("wrong_webhook_url", http.StatusBadRequest,
+		"url should be an absolute http or https URL").WithField("url")
+	ErrEmptyWebhookEvents = NewError("empty_webhook_events", http.StatusBadRequest,
+		"events should not be empty").WithField("events")
+	ErrUnknownEvent = NewError("unknown_event", http.StatusBadRequest,
+		"unknown event type").WithField("events")
+
+	ErrUnauthorized = NewError("unauthorized", http.StatusUnauthorized,
+		"a valid API key is required")
+	ErrForbidden = NewError("forbidden", http.StatusForbidden,
+		"the API key is not allowed to do this")
+	ErrWrongKeyId = NewError("wrong_key_id", http.StatusNotFound,
+		"wrong key_id").WithField("key_id")
+	ErrEmptyKeyName = NewError("empty_key_name", http.StatusBadRequest,
+		"name should not be empty").WithField("name")
+	ErrUnknownRole = NewError("unknown_role", http.StatusBadRequest,
+		"role should be admin, front_desk or read_only").WithField("role")
+	ErrLastAdminKey = NewError("last_admin_key", http.StatusConflict,
+		"the last admin key cannot be revoked")
+	ErrAdminKeyExists = NewError("admin_key_exists", http.StatusConflict,
+		"an admin key already exists")
+
+	ErrIdempotencyKeyReused = NewError("idempotency_key_reused", http.StatusUnprocessableEntity,
+		"idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = NewError("idempotency_key_in_progress", http.StatusConflict,
+		"request with this idempotency key is still in progress")
 )
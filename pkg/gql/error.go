@@ -0,0 +1,49 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/logging"
+)
+
+// resolverError exposes the code of a domain error in the extensions
+// of a GraphQL error.
+type resolverError struct {
+	err *Error
+}
+
+func (e *resolverError) Error() string {
+	return e.err.Message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.err.Code}
+	if e.err.Field != "" {
+		extensions["field"] = e.err.Field
+	}
+
+	return extensions
+}
+
+// toResolverError hides errors that are not domain errors behind
+// ErrInternalService, except for an expired request context.
+func toResolverError(ctx context.Context, err error) error {
+	var domainErr *Error
+	if contextErr := ContextError(ctx, err); errors.Is(contextErr, context.DeadlineExceeded) {
+		domainErr = ErrTimeout
+	} else if !errors.As(contextErr, &domainErr) {
+		domainErr = ErrInternalService
+	}
+
+	// client errors are expected in normal operation
+	if domainErr.Status >= http.StatusInternalServerError {
+		logging.FromContext(ctx).Error(err.Error())
+	} else {
+		logging.FromContext(ctx).Warn(err.Error())
+	}
+
+	return &resolverError{domainErr}
+}
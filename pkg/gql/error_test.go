@@ -0,0 +1,55 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToResolverError(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	tests := []struct {
+		name             string
+		input            error
+		expectedCode     string
+		expectedLogLevel logrus.Level
+	}{
+		{
+			name:             "Client Error",
+			input:            ErrWrongRoomId,
+			expectedCode:     ErrWrongRoomId.Code,
+			expectedLogLevel: logrus.WarnLevel,
+		},
+		{
+			name:             "Unknown Error",
+			input:            errors.New("sql: connection refused"),
+			expectedCode:     ErrInternalService.Code,
+			expectedLogLevel: logrus.ErrorLevel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook.Reset()
+
+			err := toResolverError(context.Background(), test.input)
+
+			var resolverErr *resolverError
+			require.True(t, errors.As(err, &resolverErr))
+			assert.Equal(t, test.expectedCode, resolverErr.Extensions()["code"])
+
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, test.expectedLogLevel, entry.Level)
+			assert.Equal(t, test.input.Error(), entry.Message)
+		})
+	}
+}
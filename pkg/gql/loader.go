@@ -0,0 +1,76 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+)
+
+// bookingLoader batches the bookings lookups of rooms. Load only queues the
+// room and returns a thunk; the executor resolves thunks after the whole
+// level of the query is resolved, so the first thunk loads the bookings of
+// every queued room with one call. A loader lives for one request, so it
+// keeps the context of that request.
+type bookingLoader struct {
+	ctx      context.Context
+	bookings service.Booking
+
+	mu      sync.Mutex
+	pending []int
+	loaded  map[int][]*model.Booking
+	errs    map[int]error
+}
+
+func newBookingLoader(ctx context.Context, bookings service.Booking) *bookingLoader {
+	return &bookingLoader{
+		ctx:      ctx,
+		bookings: bookings,
+		loaded:   make(map[int][]*model.Booking),
+		errs:     make(map[int]error),
+	}
+}
+
+func (l *bookingLoader) Load(roomId int) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[roomId]; !ok {
+		l.pending = append(l.pending, roomId)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.flush()
+		if err := l.errs[roomId]; err != nil {
+			return nil, err
+		}
+		return l.loaded[roomId], nil
+	}
+}
+
+// flush loads the pending rooms. It must be called with mu held.
+func (l *bookingLoader) flush() {
+	if len(l.pending) == 0 {
+		return
+	}
+
+	roomIds := l.pending
+	l.pending = nil
+
+	bookings, err := l.bookings.GetByRoomIds(l.ctx, roomIds)
+	if err != nil {
+		// graphql-go drops the extensions of errors returned by thunks,
+		// but the message of the domain error is kept
+		err = toResolverError(l.ctx, err)
+	}
+	for _, roomId := range roomIds {
+		if err != nil {
+			l.errs[roomId] = err
+			continue
+		}
+		l.loaded[roomId] = append([]*model.Booking{}, bookings[roomId]...)
+	}
+}
@@ -0,0 +1,174 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	"github.com/graphql-go/graphql"
+)
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type contextKey struct{}
+
+// resolvers hold the services and the loaders of one request.
+type resolvers struct {
+	services *service.Service
+	bookings *bookingLoader
+}
+
+func fromContext(ctx context.Context) *resolvers {
+	return ctx.Value(contextKey{}).(*resolvers)
+}
+
+var bookingType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Booking",
+	Fields: graphql.Fields{
+		"booking_id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Booking).Id, nil
+			},
+		},
+		"room_id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Booking).RoomId, nil
+			},
+		},
+		"guest_id": &graphql.Field{
+			Type: graphql.Int,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Booking).GuestId, nil
+			},
+		},
+		"date_start": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "Date in YYYY-MM-DD format.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Booking).DateStart.Format(model.DateFormat), nil
+			},
+		},
+		"date_end": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "Date in YYYY-MM-DD format.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Booking).DateEnd.Format(model.DateFormat), nil
+			},
+		},
+	},
+})
+
+var roomType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Room",
+	Fields: graphql.Fields{
+		"room_id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Int),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Room).Id, nil
+			},
+		},
+		"description": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Room).Description, nil
+			},
+		},
+		"price": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Int),
+			Description: "Price per night.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*model.Room).Price, nil
+			},
+		},
+		"bookings": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(bookingType))),
+			Description: "Bookings of the room ordered by date_start.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return fromContext(p.Context).bookings.Load(p.Source.(*model.Room).Id), nil
+			},
+		},
+	},
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"rooms": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roomType))),
+			Args: graphql.FieldConfigArgument{
+				"sort": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Sort field: id or price, prefixed with a minus for descending order.",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				sortField, _ := p.Args["sort"].(string)
+				rooms, err := fromContext(p.Context).services.Room.GetAll(p.Context, sortField)
+				if err != nil {
+					return nil, toResolverError(p.Context, err)
+				}
+				return rooms, nil
+			},
+		},
+		"room": &graphql.Field{
+			Type: roomType,
+			Args: graphql.FieldConfigArgument{
+				"room_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				room, err := fromContext(p.Context).services.Room.GetById(p.Context, p.Args["room_id"].(int))
+				if err != nil {
+					return nil, toResolverError(p.Context, err)
+				}
+				return room, nil
+			},
+		},
+		"booking": &graphql.Field{
+			Type: bookingType,
+			Args: graphql.FieldConfigArgument{
+				"booking_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				booking, err := fromContext(p.Context).services.Booking.GetById(p.Context, p.Args["booking_id"].(int))
+				if err != nil {
+					return nil, toResolverError(p.Context, err)
+				}
+				return booking, nil
+			},
+		},
+	},
+})
+
+var schema = newSchema()
+
+func newSchema() graphql.Schema {
+	s, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Execute runs the request against the schema.
+func Execute(ctx context.Context, services *service.Service, req *Request) *graphql.Result {
+	ctx = context.WithValue(ctx, contextKey{}, &resolvers{
+		services: services,
+		bookings: newBookingLoader(ctx, services.Booking),
+	})
+
+	return graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+}
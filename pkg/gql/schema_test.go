@@ -0,0 +1,125 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom, b *mock_service.MockBooking)
+
+	tests := []struct {
+		name         string
+		input        *Request
+		mockBehavior mockBehavior
+		expected     string
+	}{
+		{
+			name:  "Rooms With Bookings",
+			input: &Request{Query: `{ rooms(sort: "id") { room_id price bookings { booking_id date_start date_end } } }`},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetAll(gomock.Any(), "id").Return([]*model.Room{
+					{Id: 1, Description: "description1", Price: 1000},
+					{Id: 2, Description: "description2", Price: 2000},
+					{Id: 3, Description: "description3", Price: 3000},
+				}, nil)
+				b.EXPECT().GetByRoomIds(gomock.Any(), []int{1, 2, 3}).Return(map[int][]*model.Booking{
+					1: {
+						{
+							Id:        1,
+							RoomId:    1,
+							DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+							DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+						},
+					},
+					3: {
+						{
+							Id:        2,
+							RoomId:    3,
+							DateStart: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC),
+							DateEnd:   time.Date(2022, 1, 7, 0, 0, 0, 0, time.UTC),
+						},
+					},
+				}, nil).Times(1)
+			},
+			expected: `{"data":{"rooms":[` +
+				`{"bookings":[{"booking_id":1,"date_end":"2022-01-02","date_start":"2021-12-30"}],"price":1000,"room_id":1},` +
+				`{"bookings":[],"price":2000,"room_id":2},` +
+				`{"bookings":[{"booking_id":2,"date_end":"2022-01-07","date_start":"2022-01-05"}],"price":3000,"room_id":3}]}}`,
+		},
+		{
+			name:  "Bookings Error",
+			input: &Request{Query: `{ rooms(sort: "id") { room_id bookings { booking_id } } }`},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetAll(gomock.Any(), "id").Return([]*model.Room{{Id: 1, Description: "description", Price: 1000}}, nil)
+				b.EXPECT().GetByRoomIds(gomock.Any(), []int{1}).Return(nil, errors.New("pq: connection refused"))
+			},
+			expected: `{"data":null,"errors":[{"message":"something went wrong",` +
+				`"locations":[{"line":1,"column":31}],"path":["rooms",0,"bookings"]}]}`,
+		},
+		{
+			name:  "Room",
+			input: &Request{Query: `query($id: Int!) { room(room_id: $id) { description } }`, Variables: map[string]interface{}{"id": 1}},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(&model.Room{Id: 1, Description: "description", Price: 1000}, nil)
+			},
+			expected: `{"data":{"room":{"description":"description"}}}`,
+		},
+		{
+			name:  "Wrong Room Id",
+			input: &Request{Query: `{ room(room_id: 1) { description } }`},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(nil, ErrWrongRoomId)
+			},
+			expected: `{"data":{"room":null},"errors":[{"message":"wrong room_id",` +
+				`"locations":[{"line":1,"column":3}],"path":["room"],` +
+				`"extensions":{"code":"wrong_room_id","field":"room_id"}}]}`,
+		},
+		{
+			name:  "Booking",
+			input: &Request{Query: `{ booking(booking_id: 1) { booking_id room_id guest_id } }`},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				b.EXPECT().GetById(gomock.Any(), 1).Return(&model.Booking{Id: 1, RoomId: 2}, nil)
+			},
+			expected: `{"data":{"booking":{"booking_id":1,"guest_id":null,"room_id":2}}}`,
+		},
+		{
+			name:  "Service Error",
+			input: &Request{Query: `{ rooms { room_id } }`},
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetAll(gomock.Any(), "").Return(nil, ErrInternalService)
+			},
+			expected: `{"data":null,"errors":[{"message":"something went wrong",` +
+				`"locations":[{"line":1,"column":3}],"path":["rooms"],` +
+				`"extensions":{"code":"internal_error"}}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(room, booking)
+
+			services := &service.Service{Room: room, Booking: booking}
+			result := Execute(context.Background(), services, test.input)
+
+			body, err := json.Marshal(result)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, string(body))
+		})
+	}
+}
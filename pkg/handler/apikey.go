@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) createKey(ctx *fiber.Ctx) error {
+	input := &model.APIKey{}
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
+	}
+
+	key, err := h.services.APIKey.Create(requestContext(ctx), input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(key)
+}
+
+func (h *Handler) getAllKeys(ctx *fiber.Ctx) error {
+	keys, err := h.services.APIKey.GetAll(requestContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(keys)
+}
+
+func (h *Handler) revokeKey(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	err = h.services.APIKey.Revoke(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON("OK")
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createKey(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockAPIKey, key *model.APIKey)
+
+	createdAt := time.Date(2021, time.January, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		inputKey             *model.APIKey
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			inputBody: `{"name": "reception", "role": "front_desk"}`,
+			inputKey:  &model.APIKey{Name: "reception", Role: model.RoleFrontDesk},
+			mockBehavior: func(r *mock_service.MockAPIKey, key *model.APIKey) {
+				r.EXPECT().Create(gomock.Any(), key).Return(&model.APIKey{
+					Id:        3,
+					Name:      "reception",
+					Role:      model.RoleFrontDesk,
+					Prefix:    "est_0123abcd",
+					Hash:      "hash",
+					CreatedAt: createdAt,
+					Key:       "est_0123abcdef",
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `{"key_id":3,"name":"reception","role":"front_desk","prefix":"est_0123abcd",` +
+				`"created_at":"2021-01-05T12:00:00Z","key":"est_0123abcdef"}`,
+		},
+		{
+			name:               "Unknown Role",
+			inputBody:          `{"name": "reception", "role": "owner"}`,
+			mockBehavior:       func(r *mock_service.MockAPIKey, key *model.APIKey) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/keys/",
+				`[{"field":"role","code":"unknown_role","detail":"role should be admin, front_desk or read_only"}]`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			keys := newAPIKeys(c)
+			test.mockBehavior(keys, test.inputKey)
+
+			services := &service.Service{APIKey: keys}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("POST", "/keys/", bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
+
+func TestHandler_revokeKey(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockAPIKey)
+
+	tests := []struct {
+		name                 string
+		path                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Ok",
+			path: "/keys/3",
+			mockBehavior: func(r *mock_service.MockAPIKey) {
+				r.EXPECT().Revoke(gomock.Any(), 3).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `"OK"`,
+		},
+		{
+			name: "Last Admin Key",
+			path: "/keys/1",
+			mockBehavior: func(r *mock_service.MockAPIKey) {
+				r.EXPECT().Revoke(gomock.Any(), 1).Return(ErrLastAdminKey)
+			},
+			expectedStatusCode:   fiber.StatusConflict,
+			expectedResponseBody: problemJSON(ErrLastAdminKey, "/keys/1"),
+		},
+		{
+			name:                 "Wrong Id",
+			path:                 "/keys/abc",
+			mockBehavior:         func(r *mock_service.MockAPIKey) {},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrWrongParam.WithField("id"), "/keys/abc"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			keys := newAPIKeys(c)
+			test.mockBehavior(keys)
+
+			services := &service.Service{APIKey: keys}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("DELETE", test.path, nil)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
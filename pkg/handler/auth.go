@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strings"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// apiKeyHeader carries the API key, as does a bearer Authorization
+	// header.
+	apiKeyHeader = "X-API-Key"
+	apiKeyLocal  = "apiKey"
+)
+
+// probePaths are not authenticated at all, so that a stale key sent by
+// a probe does not fail it.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// authenticate finds the API key of the request. Requests without a key
+// pass on, allow turns them away from the routes that need one.
+func (h *Handler) authenticate(ctx *fiber.Ctx) error {
+	key := requestKey(ctx)
+	if key == "" || probePaths[ctx.Path()] {
+		return ctx.Next()
+	}
+
+	found, err := h.services.APIKey.Authenticate(requestContext(ctx), key)
+	if err != nil {
+		ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return err
+	}
+	ctx.Locals(apiKeyLocal, found)
+
+	return ctx.Next()
+}
+
+// allow lets through the requests whose API key has the role or a more
+// privileged one.
+func allow(role string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := requestAPIKey(ctx)
+		if key == nil {
+			ctx.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return ErrUnauthorized
+		}
+		if !key.Allows(role) {
+			return ErrForbidden.WithDetail("role", role)
+		}
+
+		return ctx.Next()
+	}
+}
+
+// requestAPIKey returns the API key the request was authenticated with,
+// or nil.
+func requestAPIKey(ctx *fiber.Ctx) *model.APIKey {
+	key, _ := ctx.Locals(apiKeyLocal).(*model.APIKey)
+	return key
+}
+
+func requestKey(ctx *fiber.Ctx) string {
+	if key := ctx.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	scheme, token, ok := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
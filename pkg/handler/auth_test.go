@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// API keys accepted by newAPIKeys.
+const (
+	testKey     = "est_admin"
+	readOnlyKey = "est_read_only"
+)
+
+// newAPIKeys returns an API key service accepting testKey as an admin key
+// and readOnlyKey as a read-only one.
+func newAPIKeys(c *gomock.Controller) *mock_service.MockAPIKey {
+	keys := mock_service.NewMockAPIKey(c)
+	keys.EXPECT().Authenticate(gomock.Any(), testKey).AnyTimes().Return(
+		&model.APIKey{Id: 1, Name: "admin", Role: model.RoleAdmin}, nil)
+	keys.EXPECT().Authenticate(gomock.Any(), readOnlyKey).AnyTimes().Return(
+		&model.APIKey{Id: 2, Name: "auditor", Role: model.RoleReadOnly}, nil)
+	keys.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, ErrUnauthorized)
+
+	return keys
+}
+
+func TestHandler_auth(t *testing.T) {
+	tests := []struct {
+		name                 string
+		header               string
+		value                string
+		mockBehavior         func(r *mock_service.MockRoom)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:   "Ok",
+			header: apiKeyHeader,
+			value:  testKey,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `"OK"`,
+		},
+		{
+			name:   "Bearer",
+			header: fiber.HeaderAuthorization,
+			value:  "Bearer " + testKey,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `"OK"`,
+		},
+		{
+			name:                 "No Key",
+			mockBehavior:         func(r *mock_service.MockRoom) {},
+			expectedStatusCode:   fiber.StatusUnauthorized,
+			expectedResponseBody: problemJSON(ErrUnauthorized, "/rooms/1"),
+		},
+		{
+			name:                 "Wrong Key",
+			header:               apiKeyHeader,
+			value:                "est_wrong",
+			mockBehavior:         func(r *mock_service.MockRoom) {},
+			expectedStatusCode:   fiber.StatusUnauthorized,
+			expectedResponseBody: problemJSON(ErrUnauthorized, "/rooms/1"),
+		},
+		{
+			name:                 "Forbidden",
+			header:               apiKeyHeader,
+			value:                readOnlyKey,
+			mockBehavior:         func(r *mock_service.MockRoom) {},
+			expectedStatusCode:   fiber.StatusForbidden,
+			expectedResponseBody: problemJSON(ErrForbidden.WithDetail("role", model.RoleAdmin), "/rooms/1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockRoom(c)
+			test.mockBehavior(repo)
+
+			services := &service.Service{Room: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("DELETE", "/rooms/1", nil)
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+			if test.expectedStatusCode == fiber.StatusUnauthorized {
+				assert.Equal(t, "Bearer", w.Header.Get(fiber.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestHandler_publicRoutes(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	handler := Handler{&service.Service{APIKey: newAPIKeys(c)}}
+
+	r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	handler.InitRoutes(r)
+
+	for _, path := range []string{"/openapi.json", "/docs", "/metrics", "/healthz"} {
+		w, err := r.Test(httptest.NewRequest("GET", path, nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusOK, w.StatusCode, path)
+	}
+}
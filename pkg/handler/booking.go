@@ -5,21 +5,19 @@ import (
 
 	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (h *Handler) createBooking(ctx *fiber.Ctx) error {
 	input := &model.Booking{}
-	if err := ctx.BodyParser(input); err != nil {
-		return sendError(ctx, fiber.StatusBadRequest, err)
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
 	}
 
-	id, err := h.services.Booking.Create(input)
+	id, err := h.services.Booking.Create(requestContext(ctx), input)
 	if err != nil {
-		if err == ErrWrongRoomId || err == ErrWrongDates {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON(fiber.Map{"booking_id": id})
@@ -28,15 +26,13 @@ func (h *Handler) createBooking(ctx *fiber.Ctx) error {
 func (h *Handler) deleteBooking(ctx *fiber.Ctx) error {
 	id, err := strconv.Atoi(ctx.Params("id"))
 	if err != nil {
-		return sendError(ctx, fiber.StatusBadRequest, err)
+		return ErrWrongParam.WithField("id")
 	}
 
-	err = h.services.Booking.Delete(id)
+	// the booking is marked with the name of the API key that cancels it
+	err = h.services.Booking.Delete(requestContext(ctx), id, requestAPIKey(ctx).Name)
 	if err != nil {
-		if err == ErrWrongBookingId {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON("OK")
@@ -45,16 +41,36 @@ func (h *Handler) deleteBooking(ctx *fiber.Ctx) error {
 func (h *Handler) getBookingsByRoomId(ctx *fiber.Ctx) error {
 	roomId, err := strconv.Atoi(ctx.Query("room_id"))
 	if err != nil {
-		return sendError(ctx, fiber.StatusBadRequest, err)
+		return ErrWrongParam.WithField("room_id")
 	}
 
-	bookings, err := h.services.Booking.GetByRoomId(roomId)
+	bookings, err := h.services.Booking.GetByRoomId(requestContext(ctx), roomId)
 	if err != nil {
-		if err == ErrWrongRoomId {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON(bookings)
 }
+
+func (h *Handler) getDeletedBookings(ctx *fiber.Ctx) error {
+	bookings, err := h.services.Booking.GetDeleted(requestContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WithRoom(bookings))
+}
+
+func (h *Handler) restoreBooking(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	err = h.services.Booking.Restore(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON("OK")
+}
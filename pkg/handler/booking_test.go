@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
 	"strconv"
@@ -38,7 +37,26 @@ func TestHandler_createBooking(t *testing.T) {
 				DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
 			},
 			mockBehavior: func(r *mock_service.MockBooking, booking *model.Booking) {
-				r.EXPECT().Create(booking).Return(1, nil)
+				r.EXPECT().Create(gomock.Any(), booking).Return(1, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"booking_id":1}`,
+		},
+		{
+			name: "Ok With Guest",
+			inputBody: `{"room_id": 1, "date_start": "2021-01-05", "date_end": "2021-01-08",` +
+				`"guest": {"name": "John Smith", "email": "john@example.com"}}`,
+			inputBooking: &model.Booking{
+				RoomId:    1,
+				DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				Guest: &model.Guest{
+					Name:  "John Smith",
+					Email: "john@example.com",
+				},
+			},
+			mockBehavior: func(r *mock_service.MockBooking, booking *model.Booking) {
+				r.EXPECT().Create(gomock.Any(), booking).Return(1, nil)
 			},
 			expectedStatusCode:   fiber.StatusOK,
 			expectedResponseBody: `{"booking_id":1}`,
@@ -49,7 +67,21 @@ func TestHandler_createBooking(t *testing.T) {
 			inputBooking:         &model.Booking{},
 			mockBehavior:         func(r *mock_service.MockBooking, booking *model.Booking) {},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: `{"error":"json: unexpected end of JSON input: "}`,
+			expectedResponseBody: problemJSON(ErrWrongBody.WithDetail("reason", "unexpected end of JSON input"), "/bookings/"),
+		},
+		{
+			name:               "Several Errors",
+			inputBody:          `{"date_start": "05.01.2021", "date_end": "2021-01-08", "guest": {"email": "john", "phone": 5}, "nights": 3}`,
+			inputBooking:       &model.Booking{},
+			mockBehavior:       func(r *mock_service.MockBooking, booking *model.Booking) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/bookings/",
+				`[{"field":"date_start","code":"wrong_date_format","detail":"date should be in YYYY-MM-DD format"},`+
+					`{"field":"guest.phone","code":"wrong_type","detail":"field has wrong type"},`+
+					`{"field":"nights","code":"unknown_field","detail":"unknown field"},`+
+					`{"field":"room_id","code":"required","detail":"field is required"},`+
+					`{"field":"guest.name","code":"empty_guest_name","detail":"guest name should not be empty"},`+
+					`{"field":"guest.email","code":"wrong_email","detail":"wrong email"}]`),
 		},
 		{
 			name:      "Wrong Room Id",
@@ -60,10 +92,10 @@ func TestHandler_createBooking(t *testing.T) {
 				DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
 			},
 			mockBehavior: func(r *mock_service.MockBooking, booking *model.Booking) {
-				r.EXPECT().Create(booking).Return(0, ErrWrongRoomId)
+				r.EXPECT().Create(gomock.Any(), booking).Return(0, ErrWrongRoomId)
 			},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrWrongRoomId),
+			expectedResponseBody: problemJSON(ErrWrongRoomId, "/bookings/"),
 		},
 		{
 			name:      "Service Error",
@@ -74,10 +106,10 @@ func TestHandler_createBooking(t *testing.T) {
 				DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
 			},
 			mockBehavior: func(r *mock_service.MockBooking, booking *model.Booking) {
-				r.EXPECT().Create(booking).Return(0, ErrInternalService)
+				r.EXPECT().Create(gomock.Any(), booking).Return(0, ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/bookings/"),
 		},
 	}
 
@@ -89,10 +121,10 @@ func TestHandler_createBooking(t *testing.T) {
 			repo := mock_service.NewMockBooking(c)
 			test.mockBehavior(repo, test.inputBooking)
 
-			services := &service.Service{Booking: repo}
+			services := &service.Service{Booking: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -101,6 +133,7 @@ func TestHandler_createBooking(t *testing.T) {
 				bytes.NewBufferString(test.inputBody),
 			)
 			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
@@ -130,7 +163,7 @@ func TestHandler_deleteBooking(t *testing.T) {
 			name:           "Ok",
 			inputBookingId: 1,
 			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
-				r.EXPECT().Delete(bookingId).Return(nil)
+				r.EXPECT().Delete(gomock.Any(), bookingId, "admin").Return(nil)
 			},
 			expectedStatusCode:   fiber.StatusOK,
 			expectedResponseBody: `"OK"`,
@@ -139,19 +172,19 @@ func TestHandler_deleteBooking(t *testing.T) {
 			name:           "Wrong Booking Id",
 			inputBookingId: 1,
 			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
-				r.EXPECT().Delete(bookingId).Return(ErrWrongBookingId)
+				r.EXPECT().Delete(gomock.Any(), bookingId, "admin").Return(ErrWrongBookingId)
 			},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrWrongBookingId),
+			expectedResponseBody: problemJSON(ErrWrongBookingId, "/bookings/1"),
 		},
 		{
 			name:           "Service Error",
 			inputBookingId: 1,
 			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
-				r.EXPECT().Delete(bookingId).Return(ErrInternalService)
+				r.EXPECT().Delete(gomock.Any(), bookingId, "admin").Return(ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/bookings/1"),
 		},
 	}
 
@@ -163,10 +196,10 @@ func TestHandler_deleteBooking(t *testing.T) {
 			repo := mock_service.NewMockBooking(c)
 			test.mockBehavior(repo, test.inputBookingId)
 
-			services := &service.Service{Booking: repo}
+			services := &service.Service{Booking: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -174,6 +207,7 @@ func TestHandler_deleteBooking(t *testing.T) {
 				"/bookings/"+strconv.Itoa(test.inputBookingId),
 				nil,
 			)
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
@@ -217,7 +251,7 @@ func TestHandler_getBookingsByRoomId(t *testing.T) {
 						DateEnd:   time.Date(2021, time.January, 28, 0, 0, 0, 0, time.UTC),
 					},
 				}
-				r.EXPECT().GetByRoomId(roomId).Return(bookings, nil)
+				r.EXPECT().GetByRoomId(gomock.Any(), roomId).Return(bookings, nil)
 			},
 			expectedStatusCode: fiber.StatusOK,
 			expectedResponseBody: `[{"booking_id":1,"date_start":"2021-01-05","date_end":"2021-01-08"},` +
@@ -227,19 +261,19 @@ func TestHandler_getBookingsByRoomId(t *testing.T) {
 			name:        "Wrong Room Id",
 			inputRoomId: 1,
 			mockBehavior: func(r *mock_service.MockBooking, roomId int) {
-				r.EXPECT().GetByRoomId(roomId).Return(nil, ErrWrongRoomId)
+				r.EXPECT().GetByRoomId(gomock.Any(), roomId).Return(nil, ErrWrongRoomId)
 			},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrWrongRoomId),
+			expectedResponseBody: problemJSON(ErrWrongRoomId, "/bookings/?room_id=1"),
 		},
 		{
 			name:        "Service Error",
 			inputRoomId: 1,
 			mockBehavior: func(r *mock_service.MockBooking, roomId int) {
-				r.EXPECT().GetByRoomId(roomId).Return(nil, ErrInternalService)
+				r.EXPECT().GetByRoomId(gomock.Any(), roomId).Return(nil, ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/bookings/?room_id=1"),
 		},
 	}
 
@@ -251,10 +285,10 @@ func TestHandler_getBookingsByRoomId(t *testing.T) {
 			repo := mock_service.NewMockBooking(c)
 			test.mockBehavior(repo, test.inputRoomId)
 
-			services := &service.Service{Booking: repo}
+			services := &service.Service{Booking: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -262,6 +296,162 @@ func TestHandler_getBookingsByRoomId(t *testing.T) {
 				"/bookings/?room_id="+strconv.Itoa(test.inputRoomId),
 				nil,
 			)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
+
+func TestHandler_getDeletedBookings(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	deletedAt := time.Date(2021, time.January, 2, 10, 0, 0, 0, time.UTC)
+	deletedBy := "operator"
+
+	tests := []struct {
+		name                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				bookings := []*model.Booking{
+					{
+						Id:        1,
+						RoomId:    2,
+						DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+						DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+						DeletedAt: &deletedAt,
+						DeletedBy: &deletedBy,
+					},
+				}
+				r.EXPECT().GetDeleted(gomock.Any()).Return(bookings, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `[{"booking_id":1,"room_id":2,"date_start":"2021-01-05","date_end":"2021-01-08",` +
+				`"deleted_at":"2021-01-02T10:00:00Z","deleted_by":"operator"}]`,
+		},
+		{
+			name: "Service Error",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().GetDeleted(gomock.Any()).Return(nil, ErrInternalService)
+			},
+			expectedStatusCode:   fiber.StatusInternalServerError,
+			expectedResponseBody: problemJSON(ErrInternalService, "/bookings/deleted"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockBooking(c)
+			test.mockBehavior(repo)
+
+			services := &service.Service{Booking: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("GET", "/bookings/deleted", nil)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
+
+func TestHandler_restoreBooking(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking, bookingId int)
+
+	tests := []struct {
+		name                 string
+		inputBookingId       int
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:           "Ok",
+			inputBookingId: 1,
+			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
+				r.EXPECT().Restore(gomock.Any(), bookingId).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `"OK"`,
+		},
+		{
+			name:           "Wrong Booking Id",
+			inputBookingId: 1,
+			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
+				r.EXPECT().Restore(gomock.Any(), bookingId).Return(ErrWrongBookingId)
+			},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrWrongBookingId, "/bookings/1/restore"),
+		},
+		{
+			name:           "Conflict",
+			inputBookingId: 1,
+			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
+				r.EXPECT().Restore(gomock.Any(), bookingId).Return(ErrBookingConflict)
+			},
+			expectedStatusCode:   fiber.StatusConflict,
+			expectedResponseBody: problemJSON(ErrBookingConflict, "/bookings/1/restore"),
+		},
+		{
+			name:           "Service Error",
+			inputBookingId: 1,
+			mockBehavior: func(r *mock_service.MockBooking, bookingId int) {
+				r.EXPECT().Restore(gomock.Any(), bookingId).Return(ErrInternalService)
+			},
+			expectedStatusCode:   fiber.StatusInternalServerError,
+			expectedResponseBody: problemJSON(ErrInternalService, "/bookings/1/restore"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockBooking(c)
+			test.mockBehavior(repo, test.inputBookingId)
+
+			services := &service.Service{Booking: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest(
+				"POST",
+				"/bookings/"+strconv.Itoa(test.inputBookingId)+"/restore",
+				nil,
+			)
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
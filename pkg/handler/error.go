@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:estate-task:problem:"
+)
+
+// problem is an RFC 7807 problem details object.
+type problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail"`
+	Instance string                 `json:"instance"`
+	Code     string                 `json:"code"`
+	Field    string                 `json:"field,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	// RequestId is the X-Request-ID of the failed request.
+	RequestId string `json:"request_id,omitempty"`
+}
+
+// errorLocal keeps the error of a failed request for the access log.
+const errorLocal = "error"
+
+// ErrorHandler renders errors returned by handlers as problem details.
+// Domain errors keep their code and status, fiber errors keep their status,
+// an expired request context is a timeout and anything else is reported as
+// an internal error. The error is logged with the access log line.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	c := requestContext(ctx)
+	domainErr := toDomainError(ContextError(c, err))
+	ctx.Locals(errorLocal, &loggedError{err: err, code: domainErr.Code})
+
+	body, err := json.Marshal(&problem{
+		Type:      problemTypePrefix + domainErr.Code,
+		Title:     utils.StatusMessage(domainErr.Status),
+		Status:    domainErr.Status,
+		Detail:    domainErr.Message,
+		Instance:  ctx.OriginalURL(),
+		Code:      domainErr.Code,
+		Field:     domainErr.Field,
+		Details:   domainErr.Details,
+		RequestId: logging.RequestID(c),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Status(domainErr.Status)
+	ctx.Set(fiber.HeaderContentType, problemContentType)
+	return ctx.Send(body)
+}
+
+// loggedError is the error of a request with the code it was reported with.
+type loggedError struct {
+	err  error
+	code string
+}
+
+func toDomainError(err error) *Error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code := strings.ReplaceAll(strings.ToLower(utils.StatusMessage(fiberErr.Code)), " ", "_")
+		return NewError(code, fiberErr.Code, fiberErr.Message)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+
+	return ErrInternalService
+}
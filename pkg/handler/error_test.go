@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// problemJSON renders the problem details body expected for err.
+func problemJSON(err *Error, instance string) string {
+	body := fmt.Sprintf(
+		`{"type":"urn:estate-task:problem:%s","title":"%s","status":%d,"detail":"%s","instance":"%s","code":"%s"`,
+		err.Code, utils.StatusMessage(err.Status), err.Status, err.Message, instance, err.Code)
+	if err.Field != "" {
+		body += fmt.Sprintf(`,"field":"%s"`, err.Field)
+	}
+	if len(err.Details) > 0 {
+		details, _ := json.Marshal(err.Details)
+		body += `,"details":` + string(details)
+	}
+
+	return body + "}"
+}
+
+// validationJSON renders the problem details body expected for a
+// validation error with the given JSON list of field errors.
+func validationJSON(instance, fieldErrors string) string {
+	return `{"type":"urn:estate-task:problem:validation_failed","title":"Bad Request","status":400,` +
+		`"detail":"request validation failed","instance":"` + instance + `","code":"validation_failed",` +
+		`"details":{"errors":` + fieldErrors + `}}`
+}
+
+func TestErrorHandler(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	tests := []struct {
+		name                 string
+		path                 string
+		err                  error
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedLogLevel     logrus.Level
+	}{
+		{
+			name:               "Domain Error",
+			path:               "/test",
+			err:                ErrWrongRoomId,
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: `{"type":"urn:estate-task:problem:wrong_room_id","title":"Bad Request",` +
+				`"status":400,"detail":"wrong room_id","instance":"/test","code":"wrong_room_id","field":"room_id"}`,
+			expectedLogLevel: logrus.WarnLevel,
+		},
+		{
+			name:               "Domain Error With Details",
+			path:               "/test",
+			err:                ErrWrongBody.WithDetail("reason", "unexpected EOF"),
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: `{"type":"urn:estate-task:problem:wrong_body","title":"Bad Request",` +
+				`"status":400,"detail":"request body is malformed","instance":"/test","code":"wrong_body",` +
+				`"details":{"reason":"unexpected EOF"}}`,
+			expectedLogLevel: logrus.WarnLevel,
+		},
+		{
+			name:               "Wrapped Domain Error",
+			path:               "/test",
+			err:                fmt.Errorf("restore: %w", ErrBookingConflict),
+			expectedStatusCode: fiber.StatusConflict,
+			expectedResponseBody: `{"type":"urn:estate-task:problem:booking_conflict","title":"Conflict",` +
+				`"status":409,"detail":"booking dates overlap with an existing booking","instance":"/test",` +
+				`"code":"booking_conflict"}`,
+			expectedLogLevel: logrus.WarnLevel,
+		},
+		{
+			name:               "Fiber Error",
+			path:               "/test",
+			err:                fiber.ErrUnprocessableEntity,
+			expectedStatusCode: fiber.StatusUnprocessableEntity,
+			expectedResponseBody: `{"type":"urn:estate-task:problem:unprocessable_entity","title":"Unprocessable Entity",` +
+				`"status":422,"detail":"Unprocessable Entity","instance":"/test","code":"unprocessable_entity"}`,
+			expectedLogLevel: logrus.WarnLevel,
+		},
+		{
+			name:               "Unknown Error",
+			path:               "/test",
+			err:                errors.New("pq: connection refused"),
+			expectedStatusCode: fiber.StatusInternalServerError,
+			expectedResponseBody: `{"type":"urn:estate-task:problem:internal_error","title":"Internal Server Error",` +
+				`"status":500,"detail":"something went wrong","instance":"/test","code":"internal_error"}`,
+			expectedLogLevel: logrus.ErrorLevel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			r.Use(AccessLog)
+			r.Get("/test", func(ctx *fiber.Ctx) error {
+				return test.err
+			})
+
+			req := httptest.NewRequest("GET", test.path, nil)
+
+			hook.Reset()
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, problemContentType, w.Header.Get(fiber.HeaderContentType))
+			assert.Equal(t, test.expectedResponseBody, body)
+			// the error is logged once, with the access log line
+			if assert.Len(t, hook.Entries, 1) {
+				entry := hook.LastEntry()
+				assert.Equal(t, test.expectedLogLevel, entry.Level)
+				assert.Equal(t, test.err.Error(), entry.Data[logrus.ErrorKey])
+				assert.Contains(t, test.expectedResponseBody, fmt.Sprintf(`"code":"%s"`, entry.Data["code"]))
+			}
+		})
+	}
+}
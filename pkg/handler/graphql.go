@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"encoding/json"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/gql"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) graphql(ctx *fiber.Ctx) error {
+	input := &gql.Request{}
+	if err := json.Unmarshal(ctx.Body(), input); err != nil {
+		return ErrWrongBody.WithDetail("reason", err.Error())
+	}
+
+	return ctx.JSON(gql.Execute(requestContext(ctx), h.services, input))
+}
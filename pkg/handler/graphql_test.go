@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_graphql(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom, b *mock_service.MockBooking)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			inputBody: `{"query": "{ rooms { room_id bookings { booking_id } } }"}`,
+			mockBehavior: func(r *mock_service.MockRoom, b *mock_service.MockBooking) {
+				r.EXPECT().GetAll(gomock.Any(), "").Return([]*model.Room{{Id: 1}, {Id: 2}}, nil)
+				b.EXPECT().GetByRoomIds(gomock.Any(), []int{1, 2}).Return(map[int][]*model.Booking{
+					2: {{Id: 5, RoomId: 2}},
+				}, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `{"data":{"rooms":[{"bookings":[],"room_id":1},` +
+				`{"bookings":[{"booking_id":5}],"room_id":2}]}}`,
+		},
+		{
+			name:               "Unknown Field",
+			inputBody:          `{"query": "{ rooms { floor } }"}`,
+			mockBehavior:       func(r *mock_service.MockRoom, b *mock_service.MockBooking) {},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `{"data":null,"errors":[{"message":"Cannot query field \"floor\" on type \"Room\".",` +
+				`"locations":[{"line":1,"column":11}]}]}`,
+		},
+		{
+			name:                 "Wrong Body",
+			inputBody:            `query`,
+			mockBehavior:         func(r *mock_service.MockRoom, b *mock_service.MockBooking) {},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrWrongBody.WithDetail("reason", "invalid character 'q' looking for beginning of value"), "/graphql"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(room, booking)
+
+			services := &service.Service{Room: room, Booking: booking, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest(
+				"POST",
+				"/graphql",
+				bytes.NewBufferString(test.inputBody),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) createGuest(ctx *fiber.Ctx) error {
+	input := &model.Guest{}
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
+	}
+
+	id, err := h.services.Guest.Create(requestContext(ctx), input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(fiber.Map{"guest_id": id})
+}
+
+func (h *Handler) findGuests(ctx *fiber.Ctx) error {
+	guests, err := h.services.Guest.Find(requestContext(ctx), ctx.Query("email"), ctx.Query("phone"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(guests)
+}
+
+func (h *Handler) getGuest(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	guest, err := h.services.Guest.GetById(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(guest)
+}
+
+func (h *Handler) getGuestBookings(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	bookings, err := h.services.Guest.GetBookings(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WithRoom(bookings))
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createGuest(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockGuest, guest *model.Guest)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		inputGuest           *model.Guest
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			inputBody: `{"name": "John Smith", "email": "john@example.com", "phone": "+10000000000"}`,
+			inputGuest: &model.Guest{
+				Name:  "John Smith",
+				Email: "john@example.com",
+				Phone: "+10000000000",
+			},
+			mockBehavior: func(r *mock_service.MockGuest, guest *model.Guest) {
+				r.EXPECT().Create(gomock.Any(), guest).Return(1, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"guest_id":1}`,
+		},
+		{
+			name:               "Empty Name",
+			inputBody:          `{"email": "john@example.com"}`,
+			inputGuest:         &model.Guest{},
+			mockBehavior:       func(r *mock_service.MockGuest, guest *model.Guest) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/guests/",
+				`[{"field":"name","code":"empty_guest_name","detail":"guest name should not be empty"}]`),
+		},
+		{
+			name:      "Service Error",
+			inputBody: `{"name": "John Smith"}`,
+			inputGuest: &model.Guest{
+				Name: "John Smith",
+			},
+			mockBehavior: func(r *mock_service.MockGuest, guest *model.Guest) {
+				r.EXPECT().Create(gomock.Any(), guest).Return(0, ErrInternalService)
+			},
+			expectedStatusCode:   fiber.StatusInternalServerError,
+			expectedResponseBody: problemJSON(ErrInternalService, "/guests/"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockGuest(c)
+			test.mockBehavior(repo, test.inputGuest)
+
+			services := &service.Service{Guest: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest(
+				"POST",
+				"/guests/",
+				bytes.NewBufferString(test.inputBody),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
+
+func TestHandler_findGuests(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockGuest)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Ok",
+			query: "?email=john@example.com",
+			mockBehavior: func(r *mock_service.MockGuest) {
+				guests := []*model.Guest{
+					{Id: 1, Name: "John Smith", Email: "john@example.com"},
+				}
+				r.EXPECT().Find(gomock.Any(), "john@example.com", "").Return(guests, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `[{"guest_id":1,"name":"John Smith","email":"john@example.com",` +
+				`"phone":"","document_type":"","document_number":""}]`,
+		},
+		{
+			name:  "Empty Contact",
+			query: "",
+			mockBehavior: func(r *mock_service.MockGuest) {
+				r.EXPECT().Find(gomock.Any(), "", "").Return(nil, ErrEmptyGuestContact)
+			},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrEmptyGuestContact, "/guests/"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockGuest(c)
+			test.mockBehavior(repo)
+
+			services := &service.Service{Guest: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("GET", "/guests/"+test.query, nil)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
+
+func TestHandler_getGuestBookings(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockGuest, guestId int)
+
+	tests := []struct {
+		name                 string
+		inputGuestId         int
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:         "Ok",
+			inputGuestId: 1,
+			mockBehavior: func(r *mock_service.MockGuest, guestId int) {
+				bookings := []*model.Booking{
+					{
+						Id:        1,
+						RoomId:    2,
+						GuestId:   &guestId,
+						DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+						DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+					},
+				}
+				r.EXPECT().GetBookings(gomock.Any(), guestId).Return(bookings, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `[{"booking_id":1,"room_id":2,"guest_id":1,"date_start":"2021-01-05","date_end":"2021-01-08"}]`,
+		},
+		{
+			name:         "Wrong Guest Id",
+			inputGuestId: 1,
+			mockBehavior: func(r *mock_service.MockGuest, guestId int) {
+				r.EXPECT().GetBookings(gomock.Any(), guestId).Return(nil, ErrWrongGuestId)
+			},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrWrongGuestId, "/guests/1/bookings"),
+		},
+		{
+			name:         "Service Error",
+			inputGuestId: 1,
+			mockBehavior: func(r *mock_service.MockGuest, guestId int) {
+				r.EXPECT().GetBookings(gomock.Any(), guestId).Return(nil, ErrInternalService)
+			},
+			expectedStatusCode:   fiber.StatusInternalServerError,
+			expectedResponseBody: problemJSON(ErrInternalService, "/guests/1/bookings"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockGuest(c)
+			test.mockBehavior(repo, test.inputGuestId)
+
+			services := &service.Service{Guest: repo, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest(
+				"GET",
+				"/guests/"+strconv.Itoa(test.inputGuestId)+"/bookings",
+				nil,
+			)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package handler
 
 import (
+	"github.com/architectv/estate-task/pkg/model"
 	"github.com/architectv/estate-task/pkg/service"
 	"github.com/gofiber/fiber/v2"
-	"github.com/sirupsen/logrus"
 )
 
 type Handler struct {
@@ -14,23 +14,60 @@ func NewHandler(services *service.Service) *Handler {
 	return &Handler{services: services}
 }
 
+// InitRoutes registers the routes. Docs, metrics and health checks are
+// open, every other route needs an API key with the role given to it.
 func (h *Handler) InitRoutes(router fiber.Router) {
+	router.Use(h.authenticate)
+	router.Use(h.idempotency)
+
+	router.Get("/openapi.json", h.getOpenAPI)
+	router.Get("/docs", h.getSwaggerUI)
+	router.Get("/docs/*", h.getSwaggerAsset)
+
+	router.Post("/graphql", allow(model.RoleReadOnly), h.graphql)
+	router.Get("/metrics", h.getMetrics)
+	router.Get("/healthz", h.getLiveness)
+	router.Get("/readyz", h.getReadiness)
+
+	admin := allow(model.RoleAdmin)
+	frontDesk := allow(model.RoleFrontDesk)
+	readOnly := allow(model.RoleReadOnly)
+
 	rooms := router.Group("/rooms")
 	{
-		rooms.Post("/", h.createRoom)
-		rooms.Delete("/:id", h.deleteRoom)
-		rooms.Get("/", h.getAllRooms)
+		rooms.Post("/", admin, h.createRoom)
+		rooms.Delete("/:id", admin, h.deleteRoom)
+		rooms.Get("/", readOnly, h.getAllRooms)
 	}
 	bookings := router.Group("/bookings")
 	{
-		bookings.Post("/", h.createBooking)
-		bookings.Delete("/:id", h.deleteBooking)
-		bookings.Get("/", h.getBookingsByRoomId)
+		bookings.Post("/", frontDesk, h.createBooking)
+		bookings.Delete("/:id", frontDesk, h.deleteBooking)
+		bookings.Get("/", readOnly, h.getBookingsByRoomId)
+		bookings.Get("/deleted", readOnly, h.getDeletedBookings)
+		bookings.Post("/:id/restore", frontDesk, h.restoreBooking)
+	}
+	guests := router.Group("/guests")
+	{
+		guests.Post("/", frontDesk, h.createGuest)
+		guests.Get("/", readOnly, h.findGuests)
+		guests.Get("/:id", readOnly, h.getGuest)
+		guests.Get("/:id/bookings", readOnly, h.getGuestBookings)
+	}
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.Post("/", admin, secretResponse, h.createWebhook)
+		webhooks.Get("/", admin, h.getAllWebhooks)
+		webhooks.Get("/:id", admin, h.getWebhook)
+		webhooks.Put("/:id", admin, h.updateWebhook)
+		webhooks.Delete("/:id", admin, h.deleteWebhook)
+		webhooks.Get("/:id/deliveries", admin, h.getWebhookDeliveries)
+		webhooks.Post("/deliveries/:id/replay", admin, h.replayDelivery)
+	}
+	keys := router.Group("/keys")
+	{
+		keys.Post("/", admin, secretResponse, h.createKey)
+		keys.Get("/", admin, h.getAllKeys)
+		keys.Delete("/:id", admin, h.revokeKey)
 	}
-}
-
-func sendError(ctx *fiber.Ctx, status int, err error) error {
-	logrus.Error(err.Error())
-	ctx.Status(status)
-	return ctx.JSON(fiber.Map{"error": err.Error()})
 }
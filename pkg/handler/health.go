@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// getLiveness reports that the process is up. It checks no dependencies,
+// so that a database outage does not get the app restarted.
+func (h *Handler) getLiveness(ctx *fiber.Ctx) error {
+	return ctx.JSON(&model.Health{Status: model.StatusOk})
+}
+
+// getReadiness reports whether the app can serve requests, with the
+// status of every check. It fails with 503 so that load balancers skip
+// the instance, and the errors of failed checks are logged.
+func (h *Handler) getReadiness(ctx *fiber.Ctx) error {
+	c := requestContext(ctx)
+	health := h.services.Health.Ready(c)
+	if !health.Ok() {
+		for name, check := range health.Checks {
+			if check.Status == model.StatusFail {
+				logging.FromContext(c).Warnf("readiness check %s failed: %s", name, check.Error)
+			}
+		}
+		ctx.Status(fiber.StatusServiceUnavailable)
+	}
+
+	return ctx.JSON(health)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_getLiveness(t *testing.T) {
+	handler := Handler{}
+
+	app := fiber.New()
+	app.Get("/healthz", handler.getLiveness)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	assert.Nil(t, err)
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"status":"ok"}`, string(body))
+}
+
+func TestHandler_getReadiness(t *testing.T) {
+	tests := []struct {
+		name                 string
+		health               *model.Health
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Ready",
+			health: &model.Health{Status: model.StatusOk, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusOk},
+				"migrations": {Status: model.StatusOk},
+			}},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"status":"ok","checks":{"database":{"status":"ok"},"migrations":{"status":"ok"}}}`,
+		},
+		{
+			name: "Not Ready",
+			health: &model.Health{Status: model.StatusFail, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusOk},
+				"migrations": {Status: model.StatusFail, Error: "schema version is 4, latest is 5"},
+			}},
+			expectedStatusCode: fiber.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"fail","checks":{"database":{"status":"ok"},` +
+				`"migrations":{"status":"fail"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			health := mock_service.NewMockHealth(c)
+			health.EXPECT().Ready(gomock.Any()).Return(test.health)
+
+			services := &service.Service{Health: health}
+			handler := Handler{services}
+
+			app := fiber.New()
+			app.Get("/readyz", handler.getReadiness)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil))
+			assert.Nil(t, err)
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, string(body))
+		})
+	}
+}
+
+func TestHandler_probesWithWrongKey(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	health := mock_service.NewMockHealth(c)
+	health.EXPECT().Ready(gomock.Any()).Return(&model.Health{Status: model.StatusOk})
+
+	handler := Handler{&service.Service{Health: health, APIKey: newAPIKeys(c)}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	handler.InitRoutes(app)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(apiKeyHeader, "expired")
+
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+}
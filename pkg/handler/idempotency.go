@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	secretResponseLocal       = "secretResponse"
+)
+
+// secretResponse marks a route whose response carries a secret shown only
+// once, such as an API key, which must not be stored for retries.
+func secretResponse(ctx *fiber.Ctx) error {
+	ctx.Locals(secretResponseLocal, true)
+	return ctx.Next()
+}
+
+// idempotency makes POST requests carrying an Idempotency-Key header safe
+// to retry: the first response is stored and returned again for retries
+// with the same key and body.
+func (h *Handler) idempotency(ctx *fiber.Ctx) error {
+	key := ctx.Get(idempotencyKeyHeader)
+	if key == "" || ctx.Method() != fiber.MethodPost {
+		return ctx.Next()
+	}
+
+	stored, err := h.services.Idempotency.Begin(requestContext(ctx), key, requestHash(ctx))
+	if err != nil {
+		return err
+	}
+
+	if stored != nil {
+		ctx.Status(*stored.StatusCode)
+		if stored.ContentType != nil {
+			ctx.Set(fiber.HeaderContentType, *stored.ContentType)
+		}
+		ctx.Set(idempotencyReplayedHeader, "true")
+		return ctx.Send(stored.Response)
+	}
+
+	// the key is completed or released even when the request timed out
+	keyCtx := context.WithoutCancel(requestContext(ctx))
+
+	if err := ctx.Next(); err != nil {
+		// render the error now so that its response can be stored
+		if err := ErrorHandler(ctx, err); err != nil {
+			h.releaseIdempotencyKey(keyCtx, key)
+			return err
+		}
+	}
+
+	response := ctx.Response()
+	// server errors are not final, let the client retry them, as well as
+	// requests refused for their API key, which may be retried with another.
+	// Secrets are not kept in the database in plain text, so responses
+	// carrying them are not stored either.
+	status := response.StatusCode()
+	if status >= fiber.StatusInternalServerError ||
+		status == fiber.StatusUnauthorized || status == fiber.StatusForbidden ||
+		ctx.Locals(secretResponseLocal) != nil {
+		h.releaseIdempotencyKey(keyCtx, key)
+		return nil
+	}
+
+	err = h.services.Idempotency.Complete(keyCtx, key, response.StatusCode(),
+		string(response.Header.ContentType()), response.Body())
+	if err != nil {
+		logging.FromContext(keyCtx).Errorf("failed to store response for idempotency key %q: %s", key, err.Error())
+	}
+
+	return nil
+}
+
+func (h *Handler) releaseIdempotencyKey(ctx context.Context, key string) {
+	if err := h.services.Idempotency.Release(ctx, key); err != nil {
+		logging.FromContext(ctx).Errorf("failed to release idempotency key %q: %s", key, err.Error())
+	}
+}
+
+// requestHash identifies a request by its API key, method, path and body,
+// so that a key reused by another client does not replay its response.
+func requestHash(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	if key := requestAPIKey(ctx); key != nil {
+		hash.Write([]byte(strconv.Itoa(key.Id)))
+	}
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{'\n'})
+	hash.Write(ctx.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_idempotency(t *testing.T) {
+	type mockBehavior func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string)
+
+	room := &model.Room{
+		Description: "test description",
+		Price:       1000,
+	}
+	statusCode := fiber.StatusOK
+	contentType := fiber.MIMEApplicationJSON
+
+	tests := []struct {
+		name                 string
+		key                  string
+		apiKey               string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedReplayed     string
+	}{
+		{
+			name: "No Key",
+			key:  "",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				r.EXPECT().Create(gomock.Any(), room).Return(1, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"room_id":1}`,
+		},
+		{
+			name: "First Request",
+			key:  "key",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(nil, nil)
+				r.EXPECT().Create(gomock.Any(), room).Return(1, nil)
+				i.EXPECT().Complete(gomock.Any(), key, fiber.StatusOK, contentType, []byte(`{"room_id":1}`)).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"room_id":1}`,
+		},
+		{
+			name: "Replay",
+			key:  "key",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(&model.IdempotencyKey{
+					Key:         key,
+					StatusCode:  &statusCode,
+					ContentType: &contentType,
+					Response:    []byte(`{"room_id":1}`),
+				}, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"room_id":1}`,
+			expectedReplayed:     "true",
+		},
+		{
+			name: "Reused Key",
+			key:  "key",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(nil, ErrIdempotencyKeyReused)
+			},
+			expectedStatusCode:   fiber.StatusUnprocessableEntity,
+			expectedResponseBody: problemJSON(ErrIdempotencyKeyReused, "/rooms/"),
+		},
+		{
+			name: "In Progress",
+			key:  "key",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(nil, ErrIdempotencyKeyInProgress)
+			},
+			expectedStatusCode:   fiber.StatusConflict,
+			expectedResponseBody: problemJSON(ErrIdempotencyKeyInProgress, "/rooms/"),
+		},
+		{
+			name: "Service Error",
+			key:  "key",
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(nil, nil)
+				r.EXPECT().Create(gomock.Any(), room).Return(0, ErrInternalService)
+				i.EXPECT().Release(gomock.Any(), key).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusInternalServerError,
+			expectedResponseBody: problemJSON(ErrInternalService, "/rooms/"),
+		},
+		{
+			name:   "Forbidden",
+			key:    "key",
+			apiKey: readOnlyKey,
+			mockBehavior: func(i *mock_service.MockIdempotency, r *mock_service.MockRoom, key string) {
+				i.EXPECT().Begin(gomock.Any(), key, gomock.Any()).Return(nil, nil)
+				i.EXPECT().Release(gomock.Any(), key).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusForbidden,
+			expectedResponseBody: problemJSON(ErrForbidden.WithDetail("role", model.RoleAdmin), "/rooms/"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			idempotency := mock_service.NewMockIdempotency(c)
+			repo := mock_service.NewMockRoom(c)
+			test.mockBehavior(idempotency, repo, test.key)
+
+			services := &service.Service{Room: repo, Idempotency: idempotency, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest(
+				"POST",
+				"/rooms/",
+				bytes.NewBufferString(`{"description": "test description", "price": 1000}`),
+			)
+			req.Header.Set("Content-type", "application/json")
+			if test.key != "" {
+				req.Header.Set(idempotencyKeyHeader, test.key)
+			}
+			if test.apiKey == "" {
+				test.apiKey = testKey
+			}
+			req.Header.Set(apiKeyHeader, test.apiKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			body := string(bytesBody)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, body)
+			assert.Equal(t, test.expectedReplayed, w.Header.Get(idempotencyReplayedHeader))
+		})
+	}
+}
+
+func TestHandler_idempotencySecretResponse(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	// the response carries the plain key, so it is not stored
+	idempotency := mock_service.NewMockIdempotency(c)
+	keys := newAPIKeys(c)
+	idempotency.EXPECT().Begin(gomock.Any(), "key", gomock.Any()).Return(nil, nil)
+	keys.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&model.APIKey{
+		Id:   3,
+		Name: "reception",
+		Role: model.RoleFrontDesk,
+		Key:  "est_0123abcdef",
+	}, nil)
+	idempotency.EXPECT().Release(gomock.Any(), "key").Return(nil)
+
+	handler := Handler{&service.Service{Idempotency: idempotency, APIKey: keys}}
+	r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	handler.InitRoutes(r)
+
+	req := httptest.NewRequest("POST", "/keys/", bytes.NewBufferString(`{"name": "reception", "role": "front_desk"}`))
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, "key")
+	req.Header.Set(apiKeyHeader, testKey)
+
+	w, err := r.Test(req, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusOK, w.StatusCode)
+}
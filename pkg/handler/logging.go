@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an id, taken from the X-Request-ID header
+// when the client sends a valid one. The id is returned in the response
+// header and logged with everything logged while serving the request.
+func RequestID(ctx *fiber.Ctx) error {
+	id := ctx.Get(requestIDHeader)
+	if !logging.ValidRequestID(id) {
+		id = uuid.NewString()
+	} else {
+		// the id outlives the request, while fiber reuses its buffers
+		id = utils.CopyString(id)
+	}
+
+	ctx.Set(requestIDHeader, id)
+	ctx.Locals(contextLocal, logging.WithRequestID(requestContext(ctx), id))
+
+	return ctx.Next()
+}
+
+// AccessLog writes one JSON line per request with its status, route and
+// latency, and the error and its code for failed requests. Errors are
+// rendered here rather than by the app, so that their status is known.
+func AccessLog(ctx *fiber.Ctx) error {
+	start := time.Now()
+	middleware := ctx.Route()
+
+	if err := ctx.Next(); err != nil {
+		if err := ctx.App().Config().ErrorHandler(ctx, err); err != nil {
+			return err
+		}
+	}
+
+	status := ctx.Response().StatusCode()
+	fields := logrus.Fields{
+		"method":     ctx.Method(),
+		"path":       ctx.Path(),
+		"route":      matchedRoute(ctx, middleware),
+		"status":     status,
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		"ip":         ctx.IP(),
+		"user_agent": ctx.Get(fiber.HeaderUserAgent),
+	}
+	if failed, ok := ctx.Locals(errorLocal).(*loggedError); ok {
+		fields[logrus.ErrorKey] = failed.err.Error()
+		fields["code"] = failed.code
+	}
+	entry := logging.FromContext(requestContext(ctx)).WithFields(fields)
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		entry.Error("request")
+	case status >= fiber.StatusBadRequest:
+		entry.Warn("request")
+	default:
+		entry.Info("request")
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	room := mock_service.NewMockRoom(c)
+	room.EXPECT().GetAll(gomock.Any(), "").DoAndReturn(
+		func(ctx context.Context, sortField string) ([]*model.Room, error) {
+			assert.Equal(t, "abc-123", logging.RequestID(ctx))
+			return []*model.Room{}, nil
+		})
+	room.EXPECT().Delete(gomock.Any(), 1).Return(ErrWrongRoomId)
+
+	handler := Handler{&service.Service{Room: room, APIKey: newAPIKeys(c)}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestID)
+	app.Use(AccessLog)
+	handler.InitRoutes(app)
+
+	t.Run("Given", func(t *testing.T) {
+		hook.Reset()
+		req := httptest.NewRequest("GET", "/rooms/", nil)
+		req.Header.Set(requestIDHeader, "abc-123")
+		req.Header.Set(apiKeyHeader, testKey)
+
+		resp, err := app.Test(req)
+		require.Nil(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "abc-123", resp.Header.Get(requestIDHeader))
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, logrus.InfoLevel, entry.Level)
+		assert.Equal(t, "abc-123", entry.Data[logging.RequestIDField])
+		assert.Equal(t, "GET", entry.Data["method"])
+		assert.Equal(t, "/rooms", entry.Data["route"])
+		assert.Equal(t, fiber.StatusOK, entry.Data["status"])
+		assert.Contains(t, entry.Data, "latency_ms")
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		hook.Reset()
+		req := httptest.NewRequest("DELETE", "/rooms/1", nil)
+		req.Header.Set(requestIDHeader, "not valid")
+		req.Header.Set(apiKeyHeader, testKey)
+
+		resp, err := app.Test(req)
+		require.Nil(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		id := resp.Header.Get(requestIDHeader)
+		assert.Len(t, id, 36)
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		var p problem
+		require.Nil(t, json.Unmarshal(body, &p))
+		assert.Equal(t, id, p.RequestId)
+
+		// the error is logged with the access log line
+		require.Len(t, hook.Entries, 1)
+		entry := hook.LastEntry()
+		assert.Equal(t, id, entry.Data[logging.RequestIDField])
+		assert.Equal(t, logrus.WarnLevel, entry.Level)
+		assert.Equal(t, "/rooms/:id", entry.Data["route"])
+		assert.Equal(t, ErrWrongRoomId.Message, entry.Data[logrus.ErrorKey])
+		assert.Equal(t, ErrWrongRoomId.Code, entry.Data["code"])
+	})
+
+	t.Run("Unmatched", func(t *testing.T) {
+		hook.Reset()
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/unknown", nil))
+		require.Nil(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, unmatchedRoute, hook.LastEntry().Data["route"])
+	})
+}
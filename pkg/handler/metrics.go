@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// unmatchedRoute labels requests that did not match any route, so that
+// scanning random paths does not create new series.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts requests and their latency by route and status. Errors
+// are rendered here rather than by the app, so that their status is known.
+func Metrics(ctx *fiber.Ctx) error {
+	start := time.Now()
+	middleware := ctx.Route()
+
+	if err := ctx.Next(); err != nil {
+		if err := ctx.App().Config().ErrorHandler(ctx, err); err != nil {
+			return err
+		}
+	}
+
+	// label values outlive the request, while fiber reuses its buffers
+	metrics.ObserveRequest(utils.CopyString(ctx.Method()), matchedRoute(ctx, middleware),
+		ctx.Response().StatusCode(), time.Since(start))
+
+	return nil
+}
+
+// matchedRoute returns the path of the route that handled the request.
+// Fiber merges middlewares registered one after another into a single
+// route, so the request stays on it when no handler matches.
+func matchedRoute(ctx *fiber.Ctx, middleware *fiber.Route) string {
+	if ctx.Route() == middleware {
+		return unmatchedRoute
+	}
+
+	return utils.CopyString(ctx.Route().Path)
+}
+
+func (h *Handler) getMetrics(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, string(metrics.ContentType))
+	return metrics.Write(ctx)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	room := mock_service.NewMockRoom(c)
+	room.EXPECT().GetAll(gomock.Any(), "").Return([]*model.Room{}, nil)
+	room.EXPECT().Delete(gomock.Any(), 1).Return(ErrWrongRoomId)
+
+	handler := Handler{&service.Service{Room: room, APIKey: newAPIKeys(c)}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Metrics)
+	handler.InitRoutes(app)
+
+	requests := []struct {
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{method: "GET", path: "/rooms/", expectedCode: fiber.StatusOK},
+		{method: "DELETE", path: "/rooms/1", expectedCode: fiber.StatusBadRequest},
+		{method: "GET", path: "/unknown", expectedCode: fiber.StatusNotFound},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set(apiKeyHeader, testKey)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.Equal(t, r.expectedCode, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMETextPlain))
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	for _, line := range []string{
+		`estate_http_requests_total{method="GET",route="/rooms",status="200"} 1`,
+		`estate_http_requests_total{method="DELETE",route="/rooms/:id",status="400"} 1`,
+		`estate_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`estate_http_request_duration_seconds_count{method="GET",route="/rooms",status="200"} 1`,
+		`# TYPE estate_bookings_created_total counter`,
+	} {
+		assert.Contains(t, string(body), line)
+	}
+}
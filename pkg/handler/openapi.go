@@ -0,0 +1,627 @@
+package handler
+
+import (
+	"path/filepath"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/gofiber/fiber/v2"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+type schema = map[string]interface{}
+
+func ref(name string) schema {
+	return schema{"$ref": "#/components/schemas/" + name}
+}
+
+func arrayOf(item schema) schema {
+	return schema{"type": "array", "items": item}
+}
+
+func jsonBody(s schema) schema {
+	return schema{
+		"required": true,
+		"content":  schema{fiber.MIMEApplicationJSON: schema{"schema": s}},
+	}
+}
+
+func responses(description string, s schema) schema {
+	return schema{
+		"200": schema{
+			"description": description,
+			"content":     schema{fiber.MIMEApplicationJSON: schema{"schema": s}},
+		},
+		"default": schema{"$ref": "#/components/responses/Problem"},
+	}
+}
+
+func param(name, in, description string, required bool, s schema) schema {
+	return schema{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"required":    required,
+		"schema":      s,
+	}
+}
+
+var (
+	idParam = func(description string) schema {
+		return param("id", "path", description, true, schema{"type": "integer"})
+	}
+	idempotencyKeyParam = param(idempotencyKeyHeader, "header",
+		"Key that makes retries of the request return the original response.",
+		false, schema{"type": "string"})
+	okSchema    = schema{"type": "string", "enum": []string{"OK"}}
+	eventSchema = schema{"type": "string", "enum": model.EventTypes}
+	roleSchema  = schema{"type": "string", "enum": model.Roles}
+	// noSecurity opens an operation to requests without an API key
+	noSecurity = []schema{}
+)
+
+// openAPI is the OpenAPI document of every route registered in InitRoutes.
+// The x-role of an operation is the least role its API key needs.
+var openAPI = schema{
+	"openapi": "3.0.3",
+	"info": schema{
+		"title":       "estate-task",
+		"description": "Hotel rooms and bookings management service.",
+		"version":     "1.0.0",
+	},
+	"security": []schema{{"ApiKey": []string{}}, {"BearerKey": []string{}}},
+	"paths": schema{
+		"/rooms": schema{
+			"post": schema{
+				"tags":        []string{"rooms"},
+				"summary":     "Create a room",
+				"operationId": "createRoom",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idempotencyKeyParam},
+				"requestBody": jsonBody(ref("RoomInput")),
+				"responses":   responses("Created room id", ref("RoomId")),
+			},
+			"get": schema{
+				"tags":        []string{"rooms"},
+				"summary":     "List rooms",
+				"operationId": "getAllRooms",
+				"x-role":      model.RoleReadOnly,
+				"parameters": []schema{
+					param("sort", "query", "Sort field, prefixed with a minus for descending order.",
+						false, schema{"type": "string", "enum": []string{"id", "-id", "price", "-price"}}),
+				},
+				"responses": responses("Rooms", arrayOf(ref("Room"))),
+			},
+		},
+		"/rooms/{id}": schema{
+			"delete": schema{
+				"tags":        []string{"rooms"},
+				"summary":     "Delete a room with its bookings",
+				"operationId": "deleteRoom",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Room id.")},
+				"responses":   responses("Room deleted", okSchema),
+			},
+		},
+		"/bookings": schema{
+			"post": schema{
+				"tags":        []string{"bookings"},
+				"summary":     "Create a booking",
+				"operationId": "createBooking",
+				"x-role":      model.RoleFrontDesk,
+				"parameters":  []schema{idempotencyKeyParam},
+				"requestBody": jsonBody(ref("BookingInput")),
+				"responses":   responses("Created booking id", ref("BookingId")),
+			},
+			"get": schema{
+				"tags":        []string{"bookings"},
+				"summary":     "List bookings of a room ordered by date_start",
+				"operationId": "getBookingsByRoomId",
+				"x-role":      model.RoleReadOnly,
+				"parameters": []schema{
+					param("room_id", "query", "Room id.", true, schema{"type": "integer"}),
+				},
+				"responses": responses("Bookings", arrayOf(ref("Booking"))),
+			},
+		},
+		"/bookings/{id}": schema{
+			"delete": schema{
+				"tags":        []string{"bookings"},
+				"summary":     "Soft delete a booking",
+				"operationId": "deleteBooking",
+				"x-role":      model.RoleFrontDesk,
+				"description": "The booking is marked as deleted by the name of the API key.",
+				"parameters":  []schema{idParam("Booking id.")},
+				"responses":   responses("Booking deleted", okSchema),
+			},
+		},
+		"/bookings/deleted": schema{
+			"get": schema{
+				"tags":        []string{"bookings"},
+				"summary":     "List deleted bookings, most recently deleted first",
+				"operationId": "getDeletedBookings",
+				"x-role":      model.RoleReadOnly,
+				"responses":   responses("Deleted bookings", arrayOf(ref("BookingWithRoom"))),
+			},
+		},
+		"/bookings/{id}/restore": schema{
+			"post": schema{
+				"tags":        []string{"bookings"},
+				"summary":     "Restore a deleted booking if its dates are still free",
+				"operationId": "restoreBooking",
+				"x-role":      model.RoleFrontDesk,
+				"parameters":  []schema{idParam("Deleted booking id."), idempotencyKeyParam},
+				"responses":   responses("Booking restored", okSchema),
+			},
+		},
+		"/guests": schema{
+			"post": schema{
+				"tags":        []string{"guests"},
+				"summary":     "Create a guest",
+				"operationId": "createGuest",
+				"x-role":      model.RoleFrontDesk,
+				"parameters":  []schema{idempotencyKeyParam},
+				"requestBody": jsonBody(ref("GuestInput")),
+				"responses":   responses("Created guest id", ref("GuestId")),
+			},
+			"get": schema{
+				"tags":        []string{"guests"},
+				"summary":     "Find guests by email or phone",
+				"operationId": "findGuests",
+				"x-role":      model.RoleReadOnly,
+				"parameters": []schema{
+					param("email", "query", "Email, case insensitive.", false, schema{"type": "string"}),
+					param("phone", "query", "Phone.", false, schema{"type": "string"}),
+				},
+				"responses": responses("Guests", arrayOf(ref("Guest"))),
+			},
+		},
+		"/guests/{id}": schema{
+			"get": schema{
+				"tags":        []string{"guests"},
+				"summary":     "Get a guest",
+				"operationId": "getGuest",
+				"x-role":      model.RoleReadOnly,
+				"parameters":  []schema{idParam("Guest id.")},
+				"responses":   responses("Guest", ref("Guest")),
+			},
+		},
+		"/guests/{id}/bookings": schema{
+			"get": schema{
+				"tags":        []string{"guests"},
+				"summary":     "Stay history of a guest ordered by date_start",
+				"operationId": "getGuestBookings",
+				"x-role":      model.RoleReadOnly,
+				"parameters":  []schema{idParam("Guest id.")},
+				"responses":   responses("Bookings", arrayOf(ref("BookingWithRoom"))),
+			},
+		},
+		"/webhooks": schema{
+			"post": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "Subscribe an endpoint to events",
+				"description": "The secret signing the deliveries is only returned here.",
+				"operationId": "createWebhook",
+				"x-role":      model.RoleAdmin,
+				"requestBody": jsonBody(ref("WebhookInput")),
+				"responses":   responses("Created webhook with its secret", ref("Webhook")),
+			},
+			"get": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "List webhooks",
+				"operationId": "getAllWebhooks",
+				"x-role":      model.RoleAdmin,
+				"responses":   responses("Webhooks", arrayOf(ref("Webhook"))),
+			},
+		},
+		"/webhooks/{id}": schema{
+			"get": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "Get a webhook",
+				"operationId": "getWebhook",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Webhook id.")},
+				"responses":   responses("Webhook", ref("Webhook")),
+			},
+			"put": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "Replace a webhook, keeping its secret unless a new one is given",
+				"operationId": "updateWebhook",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Webhook id.")},
+				"requestBody": jsonBody(ref("WebhookInput")),
+				"responses":   responses("Webhook updated", okSchema),
+			},
+			"delete": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "Delete a webhook with its deliveries",
+				"operationId": "deleteWebhook",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Webhook id.")},
+				"responses":   responses("Webhook deleted", okSchema),
+			},
+		},
+		"/webhooks/{id}/deliveries": schema{
+			"get": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "List deliveries of a webhook",
+				"operationId": "getWebhookDeliveries",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Webhook id.")},
+				"responses":   responses("Deliveries", arrayOf(ref("WebhookDelivery"))),
+			},
+		},
+		"/webhooks/deliveries/{id}/replay": schema{
+			"post": schema{
+				"tags":        []string{"webhooks"},
+				"summary":     "Send the event of a delivery again as a new delivery",
+				"operationId": "replayDelivery",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("Delivery id."), idempotencyKeyParam},
+				"responses":   responses("New delivery id", ref("DeliveryId")),
+			},
+		},
+		"/keys": schema{
+			"post": schema{
+				"tags":        []string{"keys"},
+				"summary":     "Create an API key",
+				"description": "The key itself is only returned here.",
+				"operationId": "createKey",
+				"x-role":      model.RoleAdmin,
+				"requestBody": jsonBody(ref("APIKeyInput")),
+				"responses":   responses("Created API key with the key", ref("APIKey")),
+			},
+			"get": schema{
+				"tags":        []string{"keys"},
+				"summary":     "List API keys, revoked ones included",
+				"operationId": "getAllKeys",
+				"x-role":      model.RoleAdmin,
+				"responses":   responses("API keys", arrayOf(ref("APIKey"))),
+			},
+		},
+		"/keys/{id}": schema{
+			"delete": schema{
+				"tags":        []string{"keys"},
+				"summary":     "Revoke an API key, except the last admin key",
+				"operationId": "revokeKey",
+				"x-role":      model.RoleAdmin,
+				"parameters":  []schema{idParam("API key id.")},
+				"responses":   responses("API key revoked", okSchema),
+			},
+		},
+		"/graphql": schema{
+			"post": schema{
+				"tags":        []string{"graphql"},
+				"summary":     "Query rooms with their bookings in one round trip",
+				"operationId": "graphql",
+				"x-role":      model.RoleReadOnly,
+				"requestBody": jsonBody(ref("GraphQLRequest")),
+				"responses":   responses("GraphQL result", ref("GraphQLResult")),
+			},
+		},
+		"/healthz": schema{
+			"get": schema{
+				"tags":        []string{"monitoring"},
+				"summary":     "Liveness probe",
+				"operationId": "getLiveness",
+				"security":    noSecurity,
+				"responses":   responses("The app is running", ref("Health")),
+			},
+		},
+		"/readyz": schema{
+			"get": schema{
+				"tags":        []string{"monitoring"},
+				"summary":     "Readiness probe checking the database and its migrations",
+				"operationId": "getReadiness",
+				"security":    noSecurity,
+				"responses": schema{
+					"200": schema{
+						"description": "The app is ready to serve requests",
+						"content":     schema{fiber.MIMEApplicationJSON: schema{"schema": ref("Health")}},
+					},
+					"503": schema{
+						"description": "A check failed or the app is shutting down",
+						"content":     schema{fiber.MIMEApplicationJSON: schema{"schema": ref("Health")}},
+					},
+				},
+			},
+		},
+		"/metrics": schema{
+			"get": schema{
+				"tags":        []string{"monitoring"},
+				"summary":     "Prometheus metrics",
+				"operationId": "getMetrics",
+				"security":    noSecurity,
+				"responses": schema{
+					"200": schema{
+						"description": "Metrics in the Prometheus text format",
+						"content":     schema{fiber.MIMETextPlain: schema{"schema": schema{"type": "string"}}},
+					},
+				},
+			},
+		},
+	},
+	"components": schema{
+		"securitySchemes": schema{
+			"ApiKey":    schema{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+			"BearerKey": schema{"type": "http", "scheme": "bearer"},
+		},
+		"responses": schema{
+			"Problem": schema{
+				"description": "Error",
+				"content":     schema{problemContentType: schema{"schema": ref("Problem")}},
+			},
+		},
+		"schemas": schema{
+			"Room": schema{
+				"type":     "object",
+				"required": []string{"room_id", "description", "price"},
+				"properties": schema{
+					"room_id":     schema{"type": "integer"},
+					"description": schema{"type": "string"},
+					"price":       schema{"type": "integer", "description": "Price per night."},
+				},
+			},
+			"RoomInput": schema{
+				"type":                 "object",
+				"required":             []string{"description", "price"},
+				"additionalProperties": false,
+				"properties": schema{
+					"description": schema{"type": "string", "minLength": 1},
+					"price":       schema{"type": "integer", "minimum": 1},
+				},
+			},
+			"RoomId": schema{
+				"type":       "object",
+				"required":   []string{"room_id"},
+				"properties": schema{"room_id": schema{"type": "integer"}},
+			},
+			"Booking": schema{
+				"type":     "object",
+				"required": []string{"booking_id", "date_start", "date_end"},
+				"properties": schema{
+					"booking_id": schema{"type": "integer"},
+					"guest_id":   schema{"type": "integer"},
+					"date_start": schema{"type": "string", "format": "date", "example": "2021-12-30"},
+					"date_end":   schema{"type": "string", "format": "date", "example": "2022-01-02"},
+					"deleted_at": schema{"type": "string", "format": "date-time"},
+					"deleted_by": schema{"type": "string"},
+				},
+			},
+			"BookingWithRoom": schema{
+				"allOf": []schema{
+					ref("Booking"),
+					{
+						"type":       "object",
+						"required":   []string{"room_id"},
+						"properties": schema{"room_id": schema{"type": "integer"}},
+					},
+				},
+			},
+			"BookingInput": schema{
+				"type":                 "object",
+				"required":             []string{"room_id", "date_start", "date_end"},
+				"additionalProperties": false,
+				"properties": schema{
+					"room_id":    schema{"type": "integer"},
+					"guest_id":   schema{"type": "integer"},
+					"guest":      ref("GuestInput"),
+					"date_start": schema{"type": "string", "format": "date", "example": "2021-12-30"},
+					"date_end":   schema{"type": "string", "format": "date", "example": "2022-01-02"},
+				},
+			},
+			"BookingId": schema{
+				"type":       "object",
+				"required":   []string{"booking_id"},
+				"properties": schema{"booking_id": schema{"type": "integer"}},
+			},
+			"Guest": schema{
+				"type":     "object",
+				"required": []string{"guest_id", "name", "email", "phone", "document_type", "document_number"},
+				"properties": schema{
+					"guest_id":        schema{"type": "integer"},
+					"name":            schema{"type": "string"},
+					"email":           schema{"type": "string"},
+					"phone":           schema{"type": "string"},
+					"document_type":   schema{"type": "string"},
+					"document_number": schema{"type": "string"},
+				},
+			},
+			"GuestInput": schema{
+				"type":                 "object",
+				"required":             []string{"name"},
+				"additionalProperties": false,
+				"properties": schema{
+					"name":            schema{"type": "string", "minLength": 1},
+					"email":           schema{"type": "string", "format": "email"},
+					"phone":           schema{"type": "string"},
+					"document_type":   schema{"type": "string"},
+					"document_number": schema{"type": "string"},
+				},
+			},
+			"GuestId": schema{
+				"type":       "object",
+				"required":   []string{"guest_id"},
+				"properties": schema{"guest_id": schema{"type": "integer"}},
+			},
+			"Webhook": schema{
+				"type":     "object",
+				"required": []string{"webhook_id", "url", "events", "active", "created_at"},
+				"properties": schema{
+					"webhook_id": schema{"type": "integer"},
+					"url":        schema{"type": "string", "format": "uri"},
+					"secret":     schema{"type": "string", "description": "Only returned on creation."},
+					"events":     arrayOf(eventSchema),
+					"active":     schema{"type": "boolean"},
+					"created_at": schema{"type": "string", "format": "date-time"},
+				},
+			},
+			"WebhookInput": schema{
+				"type":                 "object",
+				"required":             []string{"url", "events"},
+				"additionalProperties": false,
+				"properties": schema{
+					"url": schema{"type": "string", "format": "uri", "example": "https://example.com/hooks/estate"},
+					"secret": schema{"type": "string",
+						"description": "Key of the HMAC signature, generated when not given."},
+					"events": arrayOf(eventSchema),
+					"active": schema{"type": "boolean", "default": true},
+				},
+			},
+			"WebhookDelivery": schema{
+				"type": "object",
+				"required": []string{"delivery_id", "webhook_id", "event_id", "event_type", "payload",
+					"status", "attempts", "next_attempt_at", "created_at"},
+				"properties": schema{
+					"delivery_id":      schema{"type": "integer"},
+					"webhook_id":       schema{"type": "integer"},
+					"event_id":         schema{"type": "string"},
+					"event_type":       eventSchema,
+					"payload":          ref("Event"),
+					"status":           schema{"type": "string", "enum": []string{"pending", "delivered", "dead"}},
+					"attempts":         schema{"type": "integer"},
+					"next_attempt_at":  schema{"type": "string", "format": "date-time"},
+					"last_error":       schema{"type": "string"},
+					"last_status_code": schema{"type": "integer"},
+					"created_at":       schema{"type": "string", "format": "date-time"},
+					"delivered_at":     schema{"type": "string", "format": "date-time"},
+				},
+			},
+			"DeliveryId": schema{
+				"type":       "object",
+				"required":   []string{"delivery_id"},
+				"properties": schema{"delivery_id": schema{"type": "integer"}},
+			},
+			"Event": schema{
+				"type":        "object",
+				"description": "Body of webhook requests.",
+				"required":    []string{"event_id", "type", "created_at", "data"},
+				"properties": schema{
+					"event_id":   schema{"type": "string", "description": "Same for every attempt and webhook."},
+					"type":       eventSchema,
+					"created_at": schema{"type": "string", "format": "date-time"},
+					"data": schema{
+						"description": "The room or the booking with its room_id.",
+						"oneOf":       []schema{ref("Room"), ref("BookingWithRoom")},
+					},
+				},
+			},
+			"APIKey": schema{
+				"type":     "object",
+				"required": []string{"key_id", "name", "role", "prefix", "created_at"},
+				"properties": schema{
+					"key_id":     schema{"type": "integer"},
+					"name":       schema{"type": "string"},
+					"role":       roleSchema,
+					"prefix":     schema{"type": "string", "description": "Start of the key telling keys apart."},
+					"created_at": schema{"type": "string", "format": "date-time"},
+					"revoked_at": schema{"type": "string", "format": "date-time"},
+					"key":        schema{"type": "string", "description": "Only returned on creation."},
+				},
+			},
+			"APIKeyInput": schema{
+				"type":                 "object",
+				"required":             []string{"name", "role"},
+				"additionalProperties": false,
+				"properties": schema{
+					"name": schema{"type": "string", "minLength": 1, "example": "reception"},
+					"role": roleSchema,
+				},
+			},
+			"GraphQLRequest": schema{
+				"type":     "object",
+				"required": []string{"query"},
+				"properties": schema{
+					"query":         schema{"type": "string", "example": "{ rooms { room_id bookings { booking_id date_start } } }"},
+					"operationName": schema{"type": "string"},
+					"variables":     schema{"type": "object"},
+				},
+			},
+			"GraphQLResult": schema{
+				"type": "object",
+				"properties": schema{
+					"data":   schema{"type": "object"},
+					"errors": arrayOf(schema{"type": "object"}),
+				},
+			},
+			"Health": schema{
+				"type":     "object",
+				"required": []string{"status"},
+				"properties": schema{
+					"status": schema{"type": "string", "enum": []string{"ok", "fail"}},
+					"checks": schema{
+						"type": "object",
+						"additionalProperties": schema{
+							"type":     "object",
+							"required": []string{"status"},
+							"properties": schema{
+								"status": schema{"type": "string", "enum": []string{"ok", "fail"}},
+							},
+						},
+					},
+				},
+			},
+			"Problem": schema{
+				"type":     "object",
+				"required": []string{"type", "title", "status", "detail", "instance", "code"},
+				"properties": schema{
+					"type":     schema{"type": "string"},
+					"title":    schema{"type": "string"},
+					"status":   schema{"type": "integer"},
+					"detail":   schema{"type": "string"},
+					"instance": schema{"type": "string"},
+					"code":     schema{"type": "string", "description": "Stable machine-readable error code."},
+					"field":    schema{"type": "string"},
+					"details":  schema{"type": "object"},
+					"request_id": schema{"type": "string",
+						"description": "X-Request-ID of the request, to find it in the logs."},
+				},
+			},
+		},
+	},
+}
+
+// swaggerUIPage loads the Swagger UI assets served under /docs.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>estate-task API</title>
+  <link rel="stylesheet" type="text/css" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script src="/docs/swagger-ui-standalone-preset.js"></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({
+        url: "/openapi.json",
+        dom_id: "#swagger-ui",
+        presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+        layout: "StandaloneLayout"
+      });
+    };
+  </script>
+</body>
+</html>
+`
+
+func (h *Handler) getOpenAPI(ctx *fiber.Ctx) error {
+	return ctx.JSON(openAPI)
+}
+
+func (h *Handler) getSwaggerUI(ctx *fiber.Ctx) error {
+	ctx.Type("html")
+	return ctx.SendString(swaggerUIPage)
+}
+
+func (h *Handler) getSwaggerAsset(ctx *fiber.Ctx) error {
+	name := ctx.Params("*")
+	data, err := swaggerFiles.ReadFile(name)
+	if err != nil {
+		return fiber.ErrNotFound
+	}
+
+	ctx.Type(filepath.Ext(name))
+	return ctx.Send(data)
+}
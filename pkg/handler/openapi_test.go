@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// undocumentedRoutes serve the documentation itself.
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+	"GET /docs/*":       true,
+}
+
+func TestOpenAPI_routes(t *testing.T) {
+	r := fiber.New()
+	handler := Handler{}
+	handler.InitRoutes(r)
+
+	registered := map[string]bool{}
+	for _, routes := range r.Stack() {
+		for _, route := range routes {
+			// "/" is the idempotency middleware, HEAD mirrors every GET.
+			if route.Path == "/" || route.Method == fiber.MethodHead {
+				continue
+			}
+			key := route.Method + " " + route.Path
+			if undocumentedRoutes[key] {
+				continue
+			}
+			registered[route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+		}
+	}
+
+	documented := map[string]bool{}
+	for path, item := range openAPI["paths"].(schema) {
+		for method := range item.(schema) {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range registered {
+		assert.True(t, documented[route], "route %s is not documented in openapi.json", route)
+	}
+	for route := range documented {
+		assert.True(t, registered[route], "documented route %s is not registered", route)
+	}
+}
+
+func TestOpenAPI_schemas(t *testing.T) {
+	guestId := 3
+	deletedAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	deletedBy := "admin"
+	booking := &model.Booking{
+		Id:        1,
+		RoomId:    2,
+		GuestId:   &guestId,
+		DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+		DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		DeletedAt: &deletedAt,
+		DeletedBy: &deletedBy,
+	}
+	guest := &model.Guest{Id: 3, Name: "name", Email: "email", Phone: "phone",
+		DocumentType: "passport", DocumentNumber: "1234"}
+	room := &model.Room{Id: 1, Description: "description", Price: 1000}
+
+	tests := []struct {
+		name       string
+		schema     string
+		value      interface{}
+		properties []string
+	}{
+		{name: "Room", schema: "Room", value: room},
+		{name: "Booking", schema: "Booking", value: booking},
+		{
+			name:       "Booking With Room",
+			schema:     "Booking",
+			value:      model.WithRoom([]*model.Booking{booking})[0],
+			properties: []string{"room_id"},
+		},
+		{name: "Guest", schema: "Guest", value: guest},
+		{
+			name:   "Health",
+			schema: "Health",
+			value: &model.Health{Status: model.StatusFail, Checks: map[string]*model.Check{
+				"database": {Status: model.StatusFail, Error: "connection refused"},
+			}},
+		},
+	}
+
+	schemas := openAPI["components"].(schema)["schemas"].(schema)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.value)
+			assert.Nil(t, err)
+
+			var fields map[string]interface{}
+			assert.Nil(t, json.Unmarshal(data, &fields))
+
+			expected := append([]string{}, test.properties...)
+			properties := schemas[test.schema].(schema)["properties"].(schema)
+			for name, property := range properties {
+				expected = append(expected, name)
+				if property.(schema)["format"] == "date" {
+					_, err := time.Parse(model.DateFormat, fields[name].(string))
+					assert.Nil(t, err, "field %s does not match format date", name)
+				}
+			}
+
+			actual := []string{}
+			for name := range fields {
+				actual = append(actual, name)
+			}
+
+			sort.Strings(expected)
+			sort.Strings(actual)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestHandler_getOpenAPI(t *testing.T) {
+	tests := []struct {
+		name                string
+		path                string
+		expectedStatusCode  int
+		expectedContentType string
+	}{
+		{
+			name:                "Spec",
+			path:                "/openapi.json",
+			expectedStatusCode:  fiber.StatusOK,
+			expectedContentType: fiber.MIMEApplicationJSON,
+		},
+		{
+			name:                "Swagger UI",
+			path:                "/docs",
+			expectedStatusCode:  fiber.StatusOK,
+			expectedContentType: fiber.MIMETextHTML,
+		},
+		{
+			name:                "Swagger UI Asset",
+			path:                "/docs/swagger-ui-bundle.js",
+			expectedStatusCode:  fiber.StatusOK,
+			expectedContentType: fiber.MIMEApplicationJavaScript,
+		},
+		{
+			name:                "Unknown Asset",
+			path:                "/docs/unknown.js",
+			expectedStatusCode:  fiber.StatusNotFound,
+			expectedContentType: problemContentType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := Handler{}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("GET", test.path, nil)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			_, err = ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedContentType, w.Header.Get(fiber.HeaderContentType))
+		})
+	}
+}
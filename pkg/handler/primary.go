@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/architectv/estate-task/pkg/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// readPrimaryHeader asks to serve the request from the primary database.
+const readPrimaryHeader = "X-Read-Primary"
+
+// ReadPrimary pins requests with a true X-Read-Primary header to the
+// primary database, for clients that must read their own writes.
+func ReadPrimary(ctx *fiber.Ctx) error {
+	if primary, _ := strconv.ParseBool(ctx.Get(readPrimaryHeader)); primary {
+		ctx.Locals(contextLocal, service.ReadFromPrimary(requestContext(ctx)))
+	}
+
+	return ctx.Next()
+}
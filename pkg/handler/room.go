@@ -5,21 +5,19 @@ import (
 
 	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (h *Handler) createRoom(ctx *fiber.Ctx) error {
 	input := &model.Room{}
-	if err := ctx.BodyParser(input); err != nil {
-		return sendError(ctx, fiber.StatusBadRequest, err)
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
 	}
 
-	id, err := h.services.Room.Create(input)
+	id, err := h.services.Room.Create(requestContext(ctx), input)
 	if err != nil {
-		if err == ErrEmptyDescription || err == ErrNotPositivePrice {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON(fiber.Map{"room_id": id})
@@ -28,15 +26,12 @@ func (h *Handler) createRoom(ctx *fiber.Ctx) error {
 func (h *Handler) deleteRoom(ctx *fiber.Ctx) error {
 	id, err := strconv.Atoi(ctx.Params("id"))
 	if err != nil {
-		return sendError(ctx, fiber.StatusBadRequest, err)
+		return ErrWrongParam.WithField("id")
 	}
 
-	err = h.services.Room.Delete(id)
+	err = h.services.Room.Delete(requestContext(ctx), id)
 	if err != nil {
-		if err == ErrWrongRoomId {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON("OK")
@@ -45,12 +40,9 @@ func (h *Handler) deleteRoom(ctx *fiber.Ctx) error {
 func (h *Handler) getAllRooms(ctx *fiber.Ctx) error {
 	sortField := ctx.Query("sort")
 
-	rooms, err := h.services.Room.GetAll(sortField)
+	rooms, err := h.services.Room.GetAll(requestContext(ctx), sortField)
 	if err != nil {
-		if err == ErrWrongSortField {
-			return sendError(ctx, fiber.StatusBadRequest, err)
-		}
-		return sendError(ctx, fiber.StatusInternalServerError, err)
+		return err
 	}
 
 	return ctx.JSON(rooms)
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
 	"strconv"
@@ -36,7 +35,7 @@ func TestHandler_createRoom(t *testing.T) {
 				Price:       1000,
 			},
 			mockBehavior: func(r *mock_service.MockRoom, room *model.Room) {
-				r.EXPECT().Create(room).Return(1, nil)
+				r.EXPECT().Create(gomock.Any(), room).Return(1, nil)
 			},
 			expectedStatusCode:   fiber.StatusOK,
 			expectedResponseBody: `{"room_id":1}`,
@@ -47,33 +46,36 @@ func TestHandler_createRoom(t *testing.T) {
 			inputRoom:            &model.Room{},
 			mockBehavior:         func(r *mock_service.MockRoom, room *model.Room) {},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: `{"error":"json: unexpected end of JSON input: "}`,
+			expectedResponseBody: problemJSON(ErrWrongBody.WithDetail("reason", "unexpected end of JSON input"), "/rooms/"),
 		},
 		{
-			name:      "Empty Description",
-			inputBody: `{"description": "", "price": 1000}`,
-			inputRoom: &model.Room{
-				Description: "",
-				Price:       1000,
-			},
-			mockBehavior: func(r *mock_service.MockRoom, room *model.Room) {
-				r.EXPECT().Create(room).Return(0, ErrEmptyDescription)
-			},
-			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrEmptyDescription),
+			name:               "Empty Description",
+			inputBody:          `{"description": "", "price": 1000}`,
+			inputRoom:          &model.Room{},
+			mockBehavior:       func(r *mock_service.MockRoom, room *model.Room) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/rooms/",
+				`[{"field":"description","code":"empty_description","detail":"description should not be empty"}]`),
 		},
 		{
-			name:      "Wrong Price",
-			inputBody: `{"description": "test description", "price": -1}`,
-			inputRoom: &model.Room{
-				Description: "test description",
-				Price:       -1,
-			},
-			mockBehavior: func(r *mock_service.MockRoom, room *model.Room) {
-				r.EXPECT().Create(room).Return(0, ErrNotPositivePrice)
-			},
-			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrNotPositivePrice),
+			name:               "Wrong Price",
+			inputBody:          `{"description": "test description", "price": -1}`,
+			inputRoom:          &model.Room{},
+			mockBehavior:       func(r *mock_service.MockRoom, room *model.Room) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/rooms/",
+				`[{"field":"price","code":"not_positive_price","detail":"price should be positive number"}]`),
+		},
+		{
+			name:               "Several Errors",
+			inputBody:          `{"description": "", "price": "1000", "floor": 2}`,
+			inputRoom:          &model.Room{},
+			mockBehavior:       func(r *mock_service.MockRoom, room *model.Room) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/rooms/",
+				`[{"field":"floor","code":"unknown_field","detail":"unknown field"},`+
+					`{"field":"price","code":"wrong_type","detail":"field has wrong type"},`+
+					`{"field":"description","code":"empty_description","detail":"description should not be empty"}]`),
 		},
 		{
 			name:      "Service Error",
@@ -83,10 +85,10 @@ func TestHandler_createRoom(t *testing.T) {
 				Price:       1000,
 			},
 			mockBehavior: func(r *mock_service.MockRoom, room *model.Room) {
-				r.EXPECT().Create(room).Return(0, ErrInternalService)
+				r.EXPECT().Create(gomock.Any(), room).Return(0, ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/rooms/"),
 		},
 	}
 
@@ -98,10 +100,10 @@ func TestHandler_createRoom(t *testing.T) {
 			repo := mock_service.NewMockRoom(c)
 			test.mockBehavior(repo, test.inputRoom)
 
-			services := &service.Service{Room: repo}
+			services := &service.Service{Room: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -110,6 +112,7 @@ func TestHandler_createRoom(t *testing.T) {
 				bytes.NewBufferString(test.inputBody),
 			)
 			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
@@ -139,7 +142,7 @@ func TestHandler_deleteRoom(t *testing.T) {
 			name:        "Ok",
 			inputRoomId: 1,
 			mockBehavior: func(r *mock_service.MockRoom, roomId int) {
-				r.EXPECT().Delete(roomId).Return(nil)
+				r.EXPECT().Delete(gomock.Any(), roomId).Return(nil)
 			},
 			expectedStatusCode:   fiber.StatusOK,
 			expectedResponseBody: `"OK"`,
@@ -148,19 +151,19 @@ func TestHandler_deleteRoom(t *testing.T) {
 			name:        "Wrong Room Id",
 			inputRoomId: 1,
 			mockBehavior: func(r *mock_service.MockRoom, roomId int) {
-				r.EXPECT().Delete(roomId).Return(ErrWrongRoomId)
+				r.EXPECT().Delete(gomock.Any(), roomId).Return(ErrWrongRoomId)
 			},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrWrongRoomId),
+			expectedResponseBody: problemJSON(ErrWrongRoomId, "/rooms/1"),
 		},
 		{
 			name:        "Service Error",
 			inputRoomId: 1,
 			mockBehavior: func(r *mock_service.MockRoom, roomId int) {
-				r.EXPECT().Delete(roomId).Return(ErrInternalService)
+				r.EXPECT().Delete(gomock.Any(), roomId).Return(ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/rooms/1"),
 		},
 	}
 
@@ -172,10 +175,10 @@ func TestHandler_deleteRoom(t *testing.T) {
 			repo := mock_service.NewMockRoom(c)
 			test.mockBehavior(repo, test.inputRoomId)
 
-			services := &service.Service{Room: repo}
+			services := &service.Service{Room: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -183,6 +186,7 @@ func TestHandler_deleteRoom(t *testing.T) {
 				"/rooms/"+strconv.Itoa(test.inputRoomId),
 				nil,
 			)
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
@@ -217,7 +221,7 @@ func TestHandler_getAllRooms(t *testing.T) {
 					{Id: 2, Description: "description2", Price: 5000},
 					{Id: 3, Description: "description3", Price: 3000},
 				}
-				r.EXPECT().GetAll(sortField).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), sortField).Return(rooms, nil)
 			},
 			expectedStatusCode: fiber.StatusOK,
 			expectedResponseBody: `[{"room_id":1,"description":"description1","price":1000},` +
@@ -228,7 +232,7 @@ func TestHandler_getAllRooms(t *testing.T) {
 			name:      "Ok Empty List",
 			inputSort: "id",
 			mockBehavior: func(r *mock_service.MockRoom, sortField string) {
-				r.EXPECT().GetAll(sortField).Return(nil, nil)
+				r.EXPECT().GetAll(gomock.Any(), sortField).Return(nil, nil)
 			},
 			expectedStatusCode:   fiber.StatusOK,
 			expectedResponseBody: `null`,
@@ -237,19 +241,19 @@ func TestHandler_getAllRooms(t *testing.T) {
 			name:      "Wrong Sort Field",
 			inputSort: "wrong",
 			mockBehavior: func(r *mock_service.MockRoom, sortField string) {
-				r.EXPECT().GetAll(sortField).Return(nil, ErrWrongSortField)
+				r.EXPECT().GetAll(gomock.Any(), sortField).Return(nil, ErrWrongSortField)
 			},
 			expectedStatusCode:   fiber.StatusBadRequest,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrWrongSortField),
+			expectedResponseBody: problemJSON(ErrWrongSortField, "/rooms/?sort=wrong"),
 		},
 		{
 			name:      "Service Error",
 			inputSort: "id",
 			mockBehavior: func(r *mock_service.MockRoom, sortField string) {
-				r.EXPECT().GetAll(sortField).Return(nil, ErrInternalService)
+				r.EXPECT().GetAll(gomock.Any(), sortField).Return(nil, ErrInternalService)
 			},
 			expectedStatusCode:   fiber.StatusInternalServerError,
-			expectedResponseBody: fmt.Sprintf(`{"error":"%s"}`, ErrInternalService),
+			expectedResponseBody: problemJSON(ErrInternalService, "/rooms/?sort=id"),
 		},
 	}
 
@@ -261,10 +265,10 @@ func TestHandler_getAllRooms(t *testing.T) {
 			repo := mock_service.NewMockRoom(c)
 			test.mockBehavior(repo, test.inputSort)
 
-			services := &service.Service{Room: repo}
+			services := &service.Service{Room: repo, APIKey: newAPIKeys(c)}
 			handler := Handler{services}
 
-			r := fiber.New()
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
 			handler.InitRoutes(r)
 
 			req := httptest.NewRequest(
@@ -272,6 +276,7 @@ func TestHandler_getAllRooms(t *testing.T) {
 				"/rooms/?sort="+test.inputSort,
 				nil,
 			)
+			req.Header.Set(apiKeyHeader, testKey)
 
 			w, err := r.Test(req, -1)
 			assert.Nil(t, err)
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const contextLocal = "context"
+
+// Timeout gives every request a context that is cancelled after timeout,
+// derived from the context set by earlier middlewares.
+// Queries running with it are cancelled and the request fails with
+// ErrTimeout. A zero timeout disables the limit.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if timeout <= 0 {
+			return ctx.Next()
+		}
+
+		c, cancel := context.WithTimeout(requestContext(ctx), timeout)
+		defer cancel()
+
+		ctx.Locals(contextLocal, c)
+		return ctx.Next()
+	}
+}
+
+// requestContext returns the context services are called with.
+func requestContext(ctx *fiber.Ctx) context.Context {
+	if c, ok := ctx.Locals(contextLocal).(context.Context); ok {
+		return c
+	}
+
+	return context.Background()
+}
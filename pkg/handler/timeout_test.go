@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name                 string
+		timeout              time.Duration
+		mockBehavior         func(r *mock_service.MockRoom)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "Ok",
+			timeout: time.Second,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().GetAll(gomock.Any(), "").DoAndReturn(
+					func(ctx context.Context, sortField string) ([]*model.Room, error) {
+						_, hasDeadline := ctx.Deadline()
+						assert.True(t, hasDeadline)
+						return []*model.Room{}, nil
+					})
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+		{
+			name:    "Timeout",
+			timeout: time.Millisecond,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().GetAll(gomock.Any(), "").DoAndReturn(
+					func(ctx context.Context, sortField string) ([]*model.Room, error) {
+						<-ctx.Done()
+						return nil, ctx.Err()
+					})
+			},
+			expectedStatusCode:   fiber.StatusGatewayTimeout,
+			expectedResponseBody: problemJSON(ErrTimeout, "/rooms/"),
+		},
+		{
+			name:    "Disabled",
+			timeout: 0,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().GetAll(gomock.Any(), "").DoAndReturn(
+					func(ctx context.Context, sortField string) ([]*model.Room, error) {
+						_, hasDeadline := ctx.Deadline()
+						assert.False(t, hasDeadline)
+						return []*model.Room{}, nil
+					})
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `[]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room)
+
+			services := &service.Service{Room: room}
+			handler := Handler{services}
+
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Use(Timeout(test.timeout))
+			app.Get("/rooms/", handler.getAllRooms)
+
+			req := httptest.NewRequest("GET", "/rooms/", nil)
+			resp, err := app.Test(req)
+			assert.Nil(t, err)
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, string(body))
+		})
+	}
+}
+
+func TestTimeout_MiddlewareChain(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(RequestID)
+	app.Use(AccessLog)
+	app.Use(Tracing)
+	app.Use(Metrics)
+	app.Use(Timeout(time.Second))
+	app.Use(ReadPrimary)
+	app.Get("/teapot", func(ctx *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusTeapot, "short and stout")
+	})
+
+	requests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/teapot", expectedCode: fiber.StatusTeapot},
+		{path: "/unknown", expectedCode: fiber.StatusNotFound},
+	}
+	for _, r := range requests {
+		resp, err := app.Test(httptest.NewRequest("GET", r.path, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, r.expectedCode, resp.StatusCode)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/architectv/estate-task/pkg/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("handler")
+
+// headerCarrier reads the trace context from the request headers and
+// writes it to the response headers.
+type headerCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (c headerCarrier) Get(key string) string {
+	return c.ctx.Get(key)
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c.ctx.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.ctx.Request().Header.VisitAll(func(key, value []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Tracing starts a span for every request, continuing the trace of the
+// W3C traceparent header if it is given. The trace context of the span
+// is sent back in the response headers.
+func Tracing(ctx *fiber.Ctx) error {
+	middleware := ctx.Route()
+	propagator := otel.GetTextMapPropagator()
+	// span data outlives the request, while fiber reuses its buffers
+	method := utils.CopyString(ctx.Method())
+
+	c := propagator.Extract(requestContext(ctx), headerCarrier{ctx})
+	c, span := tracer.Start(c, "HTTP "+method, trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("url.path", utils.CopyString(ctx.Path())),
+		))
+	defer span.End()
+
+	ctx.Locals(contextLocal, c)
+	propagator.Inject(c, headerCarrier{ctx})
+
+	err := ctx.Next()
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	route := matchedRoute(ctx, middleware)
+	status := ctx.Response().StatusCode()
+	span.SetName(method + " " + route)
+	span.SetAttributes(
+		attribute.String("http.route", route),
+		attribute.Int("http.response.status_code", status),
+	)
+	if err != nil || status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, utils.StatusMessage(status))
+	}
+
+	return err
+}
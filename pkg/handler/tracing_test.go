@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	const (
+		traceId      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanId = "00f067aa0ba902b7"
+	)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	room := mock_service.NewMockRoom(c)
+	room.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+
+	handler := Handler{&service.Service{Room: room, APIKey: newAPIKeys(c)}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(Tracing)
+	handler.InitRoutes(app)
+
+	req := httptest.NewRequest("DELETE", "/rooms/1", nil)
+	req.Header.Set("traceparent", "00-"+traceId+"-"+parentSpanId+"-01")
+	req.Header.Set(apiKeyHeader, testKey)
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		span := spans[0]
+		assert.Equal(t, "DELETE /rooms/:id", span.Name())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+		assert.Equal(t, traceId, span.SpanContext().TraceID().String())
+		assert.Equal(t, parentSpanId, span.Parent().SpanID().String())
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", fiber.StatusOK))
+
+		// the response continues the trace with the request span
+		assert.Equal(t, "00-"+traceId+"-"+span.SpanContext().SpanID().String()+"-01",
+			resp.Header.Get("traceparent"))
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/unknown", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	spans = recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "GET unmatched", spans[1].Name())
+		assert.False(t, spans[1].Parent().IsValid())
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"strconv"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *Handler) createWebhook(ctx *fiber.Ctx) error {
+	input := &model.Webhook{Active: true}
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
+	}
+
+	webhook, err := h.services.Webhook.Create(requestContext(ctx), input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(webhook)
+}
+
+func (h *Handler) updateWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	input := &model.Webhook{Active: true}
+	if err := validation.Bind(ctx.Body(), input); err != nil {
+		return err
+	}
+	input.Id = id
+
+	err = h.services.Webhook.Update(requestContext(ctx), input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON("OK")
+}
+
+func (h *Handler) deleteWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	err = h.services.Webhook.Delete(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON("OK")
+}
+
+func (h *Handler) getAllWebhooks(ctx *fiber.Ctx) error {
+	webhooks, err := h.services.Webhook.GetAll(requestContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(webhooks)
+}
+
+func (h *Handler) getWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	webhook, err := h.services.Webhook.GetById(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(webhook)
+}
+
+func (h *Handler) getWebhookDeliveries(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	deliveries, err := h.services.Webhook.GetDeliveries(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(deliveries)
+}
+
+func (h *Handler) replayDelivery(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	if err != nil {
+		return ErrWrongParam.WithField("id")
+	}
+
+	deliveryId, err := h.services.Webhook.Replay(requestContext(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(fiber.Map{"delivery_id": deliveryId})
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_createWebhook(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockWebhook, webhook *model.Webhook)
+
+	createdAt := time.Date(2021, time.January, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		inputBody            string
+		inputWebhook         *model.Webhook
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			inputBody: `{"url": "https://example.com/hook", "events": ["booking.created", "booking.cancelled"]}`,
+			inputWebhook: &model.Webhook{
+				URL:    "https://example.com/hook",
+				Events: model.EventList{model.EventBookingCreated, model.EventBookingCancelled},
+				Active: true,
+			},
+			mockBehavior: func(r *mock_service.MockWebhook, webhook *model.Webhook) {
+				created := *webhook
+				created.Id = 1
+				created.Secret = "secret"
+				created.CreatedAt = createdAt
+				r.EXPECT().Create(gomock.Any(), webhook).Return(&created, nil)
+			},
+			expectedStatusCode: fiber.StatusOK,
+			expectedResponseBody: `{"webhook_id":1,"url":"https://example.com/hook","secret":"secret",` +
+				`"events":["booking.created","booking.cancelled"],"active":true,"created_at":"2021-01-05T12:00:00Z"}`,
+		},
+		{
+			name:               "Wrong URL",
+			inputBody:          `{"url": "example.com", "events": ["room.created"]}`,
+			mockBehavior:       func(r *mock_service.MockWebhook, webhook *model.Webhook) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/webhooks/",
+				`[{"field":"url","code":"wrong_webhook_url","detail":"url should be an absolute http or https URL"}]`),
+		},
+		{
+			name:               "Empty Events",
+			inputBody:          `{"url": "https://example.com/hook", "events": []}`,
+			mockBehavior:       func(r *mock_service.MockWebhook, webhook *model.Webhook) {},
+			expectedStatusCode: fiber.StatusBadRequest,
+			expectedResponseBody: validationJSON("/webhooks/",
+				`[{"field":"events","code":"empty_webhook_events","detail":"events should not be empty"}]`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooks := mock_service.NewMockWebhook(c)
+			test.mockBehavior(webhooks, test.inputWebhook)
+
+			services := &service.Service{Webhook: webhooks, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("POST", "/webhooks/", bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, string(bytesBody))
+		})
+	}
+}
+
+func TestHandler_updateWebhook(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockWebhook)
+
+	tests := []struct {
+		name                 string
+		path                 string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			path:      "/webhooks/1",
+			inputBody: `{"url": "https://example.com/hook", "events": ["room.deleted"], "active": false}`,
+			mockBehavior: func(r *mock_service.MockWebhook) {
+				r.EXPECT().Update(gomock.Any(), &model.Webhook{
+					Id:     1,
+					URL:    "https://example.com/hook",
+					Events: model.EventList{model.EventRoomDeleted},
+				}).Return(nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `"OK"`,
+		},
+		{
+			name:      "Wrong Webhook Id",
+			path:      "/webhooks/1",
+			inputBody: `{"url": "https://example.com/hook", "events": ["room.deleted"]}`,
+			mockBehavior: func(r *mock_service.MockWebhook) {
+				r.EXPECT().Update(gomock.Any(), gomock.Any()).Return(ErrWrongWebhookId)
+			},
+			expectedStatusCode:   fiber.StatusNotFound,
+			expectedResponseBody: problemJSON(ErrWrongWebhookId, "/webhooks/1"),
+		},
+		{
+			name:                 "Wrong Id Param",
+			path:                 "/webhooks/first",
+			inputBody:            `{"url": "https://example.com/hook", "events": ["room.deleted"]}`,
+			mockBehavior:         func(r *mock_service.MockWebhook) {},
+			expectedStatusCode:   fiber.StatusBadRequest,
+			expectedResponseBody: problemJSON(ErrWrongParam.WithField("id"), "/webhooks/first"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooks := mock_service.NewMockWebhook(c)
+			test.mockBehavior(webhooks)
+
+			services := &service.Service{Webhook: webhooks, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("PUT", test.path, bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, string(bytesBody))
+		})
+	}
+}
+
+func TestHandler_replayDelivery(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockWebhook)
+
+	tests := []struct {
+		name                 string
+		path                 string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Ok",
+			path: "/webhooks/deliveries/3/replay",
+			mockBehavior: func(r *mock_service.MockWebhook) {
+				r.EXPECT().Replay(gomock.Any(), 3).Return(4, nil)
+			},
+			expectedStatusCode:   fiber.StatusOK,
+			expectedResponseBody: `{"delivery_id":4}`,
+		},
+		{
+			name: "Wrong Delivery Id",
+			path: "/webhooks/deliveries/3/replay",
+			mockBehavior: func(r *mock_service.MockWebhook) {
+				r.EXPECT().Replay(gomock.Any(), 3).Return(0, ErrWrongDeliveryId)
+			},
+			expectedStatusCode:   fiber.StatusNotFound,
+			expectedResponseBody: problemJSON(ErrWrongDeliveryId, "/webhooks/deliveries/3/replay"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooks := mock_service.NewMockWebhook(c)
+			test.mockBehavior(webhooks)
+
+			services := &service.Service{Webhook: webhooks, APIKey: newAPIKeys(c)}
+			handler := Handler{services}
+
+			r := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			handler.InitRoutes(r)
+
+			req := httptest.NewRequest("POST", test.path, nil)
+			req.Header.Set(apiKeyHeader, testKey)
+
+			w, err := r.Test(req, -1)
+			assert.Nil(t, err)
+
+			bytesBody, err := ioutil.ReadAll(w.Body)
+			assert.Nil(t, err)
+
+			assert.Equal(t, test.expectedStatusCode, w.StatusCode)
+			assert.Equal(t, test.expectedResponseBody, string(bytesBody))
+		})
+	}
+}
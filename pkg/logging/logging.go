@@ -0,0 +1,56 @@
+// Package logging carries a request-scoped logrus entry in the context,
+// so that everything logged while serving a request can be correlated.
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestIDField is the log field holding the id of the request.
+	RequestIDField = "request_id"
+	// maxRequestIDLength bounds ids given by clients, longer ones are
+	// replaced rather than logged.
+	maxRequestIDLength = 128
+)
+
+type entryKey struct{}
+
+// WithRequestID returns a context whose entry logs the request id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, entryKey{}, FromContext(ctx).WithField(RequestIDField, requestID))
+}
+
+// FromContext returns the entry of the request served with ctx, or an
+// entry of the standard logger outside of a request.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// RequestID returns the id of the request served with ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := FromContext(ctx).Data[RequestIDField].(string)
+
+	return id
+}
+
+// ValidRequestID accepts ids given by clients that are printable ASCII
+// and short enough to log.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequestID(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", RequestID(ctx))
+	assert.Equal(t, logrus.StandardLogger(), FromContext(ctx).Logger)
+
+	ctx = WithRequestID(ctx, "abc")
+	assert.Equal(t, "abc", RequestID(ctx))
+	assert.Equal(t, "abc", FromContext(ctx).Data[RequestIDField])
+}
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		valid bool
+	}{
+		{name: "UUID", id: "0f8fad5b-d9cb-469f-a165-70867728950e", valid: true},
+		{name: "Empty", id: ""},
+		{name: "Too Long", id: strings.Repeat("a", 129)},
+		{name: "Space", id: "a b"},
+		{name: "Newline", id: "a\nb"},
+		{name: "Non ASCII", id: "запрос"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.valid, ValidRequestID(test.id))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package metrics collects the Prometheus metrics of the app.
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/common/expfmt"
+)
+
+const namespace = "estate"
+
+// ContentType is the content type of the metrics written by Write.
+var ContentType = expfmt.NewFormat(expfmt.TypeTextPlain)
+
+// Registry holds every metric exposed by the app.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_query_duration_seconds",
+		Help:      "Duration of repository calls by method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"repository", "method"})
+	replicaUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_replica_up",
+		Help:      "Whether the read replica passed its last health check.",
+	}, []string{"replica"})
+	webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts by status: delivered, failed or dead.",
+	}, []string{"status"})
+	outboxEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_events_total",
+		Help:      "Attempts to relay outbox events by status: published or failed.",
+	}, []string{"status"})
+
+	BookingsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_created_total",
+		Help:      "Bookings created.",
+	})
+	BookingsCancelled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_cancelled_total",
+		Help:      "Bookings cancelled.",
+	})
+	BookingsConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_conflicts_total",
+		Help:      "Bookings rejected because their dates overlap another booking.",
+	})
+
+	RoomCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "room_cache_hits_total",
+		Help:      "Room lookups and listings served from the cache.",
+	})
+	RoomCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "room_cache_misses_total",
+		Help:      "Room lookups and listings read from the database.",
+	})
+	RoomCacheInvalidations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "room_cache_invalidations_total",
+		Help:      "Room cache invalidations, local and from other instances.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, queryDuration, replicaUp, webhookDeliveries, outboxEvents,
+		BookingsCreated, BookingsCancelled, BookingsConflicts,
+		RoomCacheHits, RoomCacheMisses, RoomCacheInvalidations,
+	)
+}
+
+// RegisterDB exposes the connection pool stats of db.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records an HTTP request served by route.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a repository call started at start. It is meant
+// to be deferred.
+func ObserveQuery(repository, method string, start time.Time) {
+	queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+}
+
+// Write writes all metrics in the Prometheus text format.
+func Write(w io.Writer) error {
+	families, err := Registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	encoder := expfmt.NewEncoder(w, ContentType)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetReplicaUp records the result of the health check of a read replica.
+func SetReplicaUp(replica string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	replicaUp.WithLabelValues(replica).Set(value)
+}
+
+// ObserveWebhookDelivery records an attempt to deliver a webhook that
+// left the delivery in status, or failed it if it is still pending.
+func ObserveWebhookDelivery(status string) {
+	if status == "pending" {
+		status = "failed"
+	}
+	webhookDeliveries.WithLabelValues(status).Inc()
+}
+
+// ObserveOutboxEvent records an attempt to relay an outbox event, either
+// published or failed.
+func ObserveOutboxEvent(status string) {
+	outboxEvents.WithLabelValues(status).Inc()
+}
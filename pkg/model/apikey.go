@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+// Roles of API keys from the most to the least privileged. A role may do
+// everything the roles after it may.
+const (
+	RoleAdmin     = "admin"
+	RoleFrontDesk = "front_desk"
+	RoleReadOnly  = "read_only"
+)
+
+// Roles lists every role.
+var Roles = []string{RoleAdmin, RoleFrontDesk, RoleReadOnly}
+
+// APIKey grants its role to the requests carrying it. Only the hash of
+// the key is stored, the key itself is shown once when it is created.
+type APIKey struct {
+	Id        int        `json:"key_id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Role      string     `json:"role" db:"role"`
+	Prefix    string     `json:"prefix" db:"prefix"`
+	Hash      string     `json:"-" db:"hash"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	Key       string     `json:"key,omitempty" db:"-"`
+}
+
+func (k *APIKey) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"name": &k.Name,
+		"role": &k.Role,
+	}
+}
+
+func (k *APIKey) Validate(v *validation.Validator) {
+	v.Check(strings.TrimSpace(k.Name) != "", ErrEmptyKeyName)
+	v.Check(roleRank(k.Role) > 0, ErrUnknownRole)
+}
+
+// Allows reports whether the key may do what needs the role.
+func (k *APIKey) Allows(role string) bool {
+	return k.RevokedAt == nil && roleRank(role) > 0 && roleRank(k.Role) >= roleRank(role)
+}
+
+// roleRank orders the roles by privilege, unknown roles rank zero.
+func roleRank(role string) int {
+	for i, known := range Roles {
+		if role == known {
+			return len(Roles) - i
+		}
+	}
+
+	return 0
+}
@@ -2,54 +2,119 @@ package model
 
 import (
 	"encoding/json"
-	"errors"
 	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/validation"
 )
 
 // year-month-day
 const DateFormat = "2006-01-02"
 
 type Booking struct {
-	Id        int       `json:"booking_id" db:"id"`
-	RoomId    int       `json:"-" db:"room_id"`
-	DateStart time.Time `json:"date_start" db:"date_start"`
-	DateEnd   time.Time `json:"date_end" db:"date_end"`
+	Id        int        `json:"booking_id" db:"id"`
+	RoomId    int        `json:"-" db:"room_id"`
+	GuestId   *int       `json:"guest_id,omitempty" db:"guest_id"`
+	DateStart time.Time  `json:"date_start" db:"date_start"`
+	DateEnd   time.Time  `json:"date_end" db:"date_end"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *string    `json:"deleted_by,omitempty" db:"deleted_by"`
+
+	// Guest is a guest profile given inline when creating a booking.
+	Guest *Guest `json:"-" db:"-"`
+}
+
+// BookingWithRoom renders a booking together with its room_id,
+// for lists that are not scoped to a single room.
+type BookingWithRoom struct {
+	*Booking
+}
+
+// WithRoom wraps bookings so that their room_id is rendered.
+func WithRoom(bookings []*Booking) []BookingWithRoom {
+	wrapped := make([]BookingWithRoom, 0, len(bookings))
+	for _, booking := range bookings {
+		wrapped = append(wrapped, BookingWithRoom{booking})
+	}
+
+	return wrapped
 }
 
 func (b *Booking) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&struct {
-		Id        int    `json:"booking_id"`
-		DateStart string `json:"date_start"`
-		DateEnd   string `json:"date_end"`
+	return b.marshalJSON(false)
+}
+
+func (b BookingWithRoom) MarshalJSON() ([]byte, error) {
+	return b.marshalJSON(true)
+}
+
+func (b *Booking) marshalJSON(withRoom bool) ([]byte, error) {
+	buffer := &struct {
+		Id        int        `json:"booking_id"`
+		RoomId    int        `json:"room_id,omitempty"`
+		GuestId   *int       `json:"guest_id,omitempty"`
+		DateStart string     `json:"date_start"`
+		DateEnd   string     `json:"date_end"`
+		DeletedAt *time.Time `json:"deleted_at,omitempty"`
+		DeletedBy *string    `json:"deleted_by,omitempty"`
 	}{
 		Id:        b.Id,
+		GuestId:   b.GuestId,
 		DateStart: b.DateStart.Format(DateFormat),
 		DateEnd:   b.DateEnd.Format(DateFormat),
-	})
+		DeletedAt: b.DeletedAt,
+		DeletedBy: b.DeletedBy,
+	}
+	if withRoom {
+		buffer.RoomId = b.RoomId
+	}
+
+	return json.Marshal(buffer)
 }
 
 func (b *Booking) UnmarshalJSON(data []byte) error {
-	var buffer struct {
-		RoomId    int    `json:"room_id"`
-		DateStart string `json:"date_start"`
-		DateEnd   string `json:"date_end"`
+	return validation.Decode(data, b)
+}
+
+func (b *Booking) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"room_id":    &b.RoomId,
+		"guest_id":   &b.GuestId,
+		"guest":      &b.Guest,
+		"date_start": &date{&b.DateStart},
+		"date_end":   &date{&b.DateEnd},
+	}
+}
+
+func (b *Booking) Validate(v *validation.Validator) {
+	v.Check(b.RoomId != 0, ErrRequiredField.WithField("room_id"))
+	v.Check(!b.DateStart.IsZero(), ErrRequiredField.WithField("date_start"))
+	v.Check(!b.DateEnd.IsZero(), ErrRequiredField.WithField("date_end"))
+	if !b.DateStart.IsZero() && !b.DateEnd.IsZero() {
+		v.Check(b.DateStart.Before(b.DateEnd), ErrWrongDates)
 	}
-	if err := json.Unmarshal(data, &buffer); err != nil {
-		return err
+	v.Check(b.GuestId == nil || b.Guest == nil, ErrGuestAndGuestId)
+	if b.Guest != nil {
+		b.Guest.Validate(v.Nested("guest."))
 	}
+}
 
-	dateStart, err := time.Parse(DateFormat, buffer.DateStart)
-	if err != nil {
-		return errors.New("bad date_start")
+// date decodes a DateFormat string into the time it points to.
+type date struct {
+	t *time.Time
+}
+
+func (d *date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ErrWrongDateFormat
 	}
-	dateEnd, err := time.Parse(DateFormat, buffer.DateEnd)
+
+	t, err := time.Parse(DateFormat, value)
 	if err != nil {
-		return errors.New("bad date_end")
+		return ErrWrongDateFormat
 	}
-
-	b.RoomId = buffer.RoomId
-	b.DateStart = dateStart
-	b.DateEnd = dateEnd
+	*d.t = t
 
 	return nil
 }
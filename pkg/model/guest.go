@@ -0,0 +1,32 @@
+package model
+
+import (
+	"strings"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+type Guest struct {
+	Id             int    `json:"guest_id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	Email          string `json:"email" db:"email"`
+	Phone          string `json:"phone" db:"phone"`
+	DocumentType   string `json:"document_type" db:"document_type"`
+	DocumentNumber string `json:"document_number" db:"document_number"`
+}
+
+func (g *Guest) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"name":            &g.Name,
+		"email":           &g.Email,
+		"phone":           &g.Phone,
+		"document_type":   &g.DocumentType,
+		"document_number": &g.DocumentNumber,
+	}
+}
+
+func (g *Guest) Validate(v *validation.Validator) {
+	v.Check(strings.TrimSpace(g.Name) != "", ErrEmptyGuestName)
+	v.Check(g.Email == "" || strings.Contains(g.Email, "@"), ErrWrongEmail)
+}
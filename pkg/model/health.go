@@ -0,0 +1,25 @@
+package model
+
+// Statuses of a health check.
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is the result of checking one dependency. Error may name hosts
+// and driver details, so it is logged rather than shown to clients.
+type Check struct {
+	Status string `json:"status"`
+	Error  string `json:"-"`
+}
+
+// Health is the overall status with the checks it was made of.
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]*Check `json:"checks,omitempty"`
+}
+
+// Ok reports whether the overall status is ok.
+func (h *Health) Ok() bool {
+	return h.Status == StatusOk
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// IdempotencyKey is a client supplied key together with the request it was
+// first used for and the response that request produced.
+type IdempotencyKey struct {
+	Key         string    `db:"key"`
+	RequestHash string    `db:"request_hash"`
+	StatusCode  *int      `db:"status_code"`
+	ContentType *string   `db:"content_type"`
+	Response    []byte    `db:"response"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// Completed reports whether the response for the key has been stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != nil
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutboxEvent is an event saved in the transaction of the change it
+// announces and relayed to the sinks after the commit. Events of the same
+// aggregate, like a room or a booking, are relayed in the order of their ids.
+type OutboxEvent struct {
+	Id            int             `db:"id"`
+	AggregateType string          `db:"aggregate_type"`
+	AggregateId   int             `db:"aggregate_id"`
+	EventId       string          `db:"event_id"`
+	EventType     string          `db:"event_type"`
+	Payload       json.RawMessage `db:"payload"`
+	Attempts      int             `db:"attempts"`
+	NextAttemptAt time.Time       `db:"next_attempt_at"`
+	LastError     *string         `db:"last_error"`
+	CreatedAt     time.Time       `db:"created_at"`
+	PublishedAt   *time.Time      `db:"published_at"`
+}
+
+// AggregateKey identifies the aggregate of the event, like "booking:12".
+func (e *OutboxEvent) AggregateKey() string {
+	return e.AggregateType + ":" + strconv.Itoa(e.AggregateId)
+}
+
+// Aggregate returns the type of the aggregate an event type is about,
+// like "booking" for booking.created.
+func Aggregate(eventType string) string {
+	if i := strings.IndexByte(eventType, '.'); i >= 0 {
+		return eventType[:i]
+	}
+
+	return eventType
+}
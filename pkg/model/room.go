@@ -1,7 +1,24 @@
 package model
 
+import (
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
 type Room struct {
 	Id          int    `json:"room_id" db:"id"`
 	Description string `json:"description" db:"description"`
 	Price       int    `json:"price" db:"price"`
 }
+
+func (r *Room) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"description": &r.Description,
+		"price":       &r.Price,
+	}
+}
+
+func (r *Room) Validate(v *validation.Validator) {
+	v.Check(r.Description != "", ErrEmptyDescription)
+	v.Check(r.Price > 0, ErrNotPositivePrice)
+}
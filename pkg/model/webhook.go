@@ -0,0 +1,141 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+// Event types webhooks can subscribe to.
+const (
+	EventBookingCreated   = "booking.created"
+	EventBookingCancelled = "booking.cancelled"
+	EventBookingRestored  = "booking.restored"
+	EventRoomCreated      = "room.created"
+	EventRoomDeleted      = "room.deleted"
+)
+
+// EventTypes lists every event type.
+var EventTypes = []string{
+	EventBookingCreated, EventBookingCancelled, EventBookingRestored,
+	EventRoomCreated, EventRoomDeleted,
+}
+
+// Delivery statuses. Pending deliveries are retried until they succeed
+// or run out of attempts and become dead.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// EventList is a list of event types stored as a comma separated string.
+type EventList []string
+
+func (l EventList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+func (l *EventList) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EventList", src)
+	}
+
+	*l = nil
+	if s != "" {
+		*l = strings.Split(s, ",")
+	}
+
+	return nil
+}
+
+// Webhook is a subscription of an HTTP endpoint to events. Deliveries are
+// signed with the secret, which is only shown when the webhook is created.
+type Webhook struct {
+	Id        int       `json:"webhook_id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	Events    EventList `json:"events" db:"events"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (w *Webhook) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"url":    &w.URL,
+		"secret": &w.Secret,
+		"events": &w.Events,
+		"active": &w.Active,
+	}
+}
+
+func (w *Webhook) Validate(v *validation.Validator) {
+	u, err := url.Parse(w.URL)
+	v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", ErrWrongWebhookURL)
+	v.Check(len(w.Events) > 0, ErrEmptyWebhookEvents)
+	for _, event := range w.Events {
+		v.Check(knownEvent(event), ErrUnknownEvent.WithDetail("event", event))
+	}
+}
+
+// Subscribed reports whether the webhook receives events of the type.
+func (w *Webhook) Subscribed(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func knownEvent(eventType string) bool {
+	for _, known := range EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is the body sent to webhooks. Its id is the same for every
+// webhook and every attempt, so that receivers can drop duplicates.
+type Event struct {
+	Id        string      `json:"event_id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is an event queued for a webhook along with the state
+// of its delivery.
+type WebhookDelivery struct {
+	Id             int             `json:"delivery_id" db:"id"`
+	WebhookId      int             `json:"webhook_id" db:"webhook_id"`
+	EventId        string          `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
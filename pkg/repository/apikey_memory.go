@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type APIKeyMemory struct {
+	store *memoryStore
+}
+
+func NewAPIKeyMemory(store *memoryStore) *APIKeyMemory {
+	return &APIKeyMemory{store: store}
+}
+
+func (r *APIKeyMemory) Create(ctx context.Context, key *model.APIKey) (int, error) {
+	defer r.store.lock(ctx)()
+
+	// mirrors the unique constraint on the hash column
+	for _, found := range r.store.apiKeys {
+		if found.Hash == key.Hash {
+			return 0, fmt.Errorf("%s with hash %s already exists", apiKeysTable, key.Hash)
+		}
+	}
+
+	r.store.apiKeySeq++
+	created := copyAPIKey(key)
+	created.Id = r.store.apiKeySeq
+	created.Key = ""
+	r.store.apiKeys[created.Id] = created
+
+	return created.Id, nil
+}
+
+func (r *APIKeyMemory) GetAll(ctx context.Context) ([]*model.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	var keys []*model.APIKey
+	for _, key := range r.store.apiKeys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Id < keys[j].Id })
+
+	return keys, nil
+}
+
+func (r *APIKeyMemory) GetById(ctx context.Context, id int) (*model.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return &model.APIKey{}, sql.ErrNoRows
+	}
+
+	return copyAPIKey(key), nil
+}
+
+func (r *APIKeyMemory) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, key := range r.store.apiKeys {
+		if key.Hash == hash {
+			return copyAPIKey(key), nil
+		}
+	}
+
+	return &model.APIKey{}, sql.ErrNoRows
+}
+
+func (r *APIKeyMemory) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	if key, ok := r.store.apiKeys[id]; ok && key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+	}
+
+	return nil
+}
+
+func copyAPIKey(key *model.APIKey) *model.APIKey {
+	copied := *key
+	copied.RevokedAt = copyTime(key.RevokedAt)
+
+	return &copied
+}
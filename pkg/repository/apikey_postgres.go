@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyPostgres(db *sqlx.DB) *APIKeyPostgres {
+	return &APIKeyPostgres{db: db}
+}
+
+func (r *APIKeyPostgres) Create(ctx context.Context, key *model.APIKey) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (name, role, prefix, hash, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		apiKeysTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query,
+		key.Name, key.Role, key.Prefix, key.Hash, key.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *APIKeyPostgres) GetAll(ctx context.Context) ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY id", apiKeysTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &keys, query)
+
+	return keys, err
+}
+
+func (r *APIKeyPostgres) GetById(ctx context.Context, id int) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", apiKeysTable)
+	err := conn(ctx, r.db).GetContext(ctx, key, query, id)
+
+	return key, err
+}
+
+func (r *APIKeyPostgres) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash=$1", apiKeysTable)
+	err := conn(ctx, r.db).GetContext(ctx, key, query, hash)
+
+	return key, err
+}
+
+// Revoke marks the key revoked unless it already is, so that the time of
+// the first revocation is kept.
+func (r *APIKeyPostgres) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at=$2 WHERE id=$1 AND revoked_at IS NULL", apiKeysTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, revokedAt)
+
+	return err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestAPIKeyPostgres_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewAPIKeyPostgres(db)
+	createdAt := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    *model.APIKey
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "name", "role", "prefix", "hash", "created_at", "revoked_at"}).
+					AddRow(1, "reception", model.RoleFrontDesk, "est_0123abcd", "hash", createdAt, nil)
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE hash=(.+)", apiKeysTable)).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			want: &model.APIKey{
+				Id:        1,
+				Name:      "reception",
+				Role:      model.RoleFrontDesk,
+				Prefix:    "est_0123abcd",
+				Hash:      "hash",
+				CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE hash=(.+)", apiKeysTable)).
+					WithArgs("hash").
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetByHash(context.Background(), "hash")
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyPostgres_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewAPIKeyPostgres(db)
+	revokedAt := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET revoked_at=(.+) WHERE id=(.+) AND revoked_at IS NULL",
+					apiKeysTable)).
+					WithArgs(1, revokedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s", apiKeysTable)).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.Revoke(context.Background(), 1, revokedAt)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type BookingMemory struct {
+	store *memoryStore
+}
+
+func NewBookingMemory(store *memoryStore) *BookingMemory {
+	return &BookingMemory{store: store}
+}
+
+func (r *BookingMemory) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.rooms[booking.RoomId]; !ok {
+		return 0, errForeignKey(roomsTable, booking.RoomId)
+	}
+	if booking.GuestId != nil {
+		if _, ok := r.store.guests[*booking.GuestId]; !ok {
+			return 0, errForeignKey(guestsTable, *booking.GuestId)
+		}
+	}
+
+	r.store.bookingSeq++
+	created := model.Booking{
+		Id:        r.store.bookingSeq,
+		RoomId:    booking.RoomId,
+		GuestId:   copyInt(booking.GuestId),
+		DateStart: booking.DateStart,
+		DateEnd:   booking.DateEnd,
+	}
+	r.store.bookings[created.Id] = &created
+
+	return created.Id, nil
+}
+
+func (r *BookingMemory) Delete(ctx context.Context, id int, deletedBy string) error {
+	defer r.store.lock(ctx)()
+
+	booking, ok := r.store.bookings[id]
+	if ok && booking.DeletedAt == nil {
+		now := time.Now()
+		booking.DeletedAt = &now
+		booking.DeletedBy = &deletedBy
+	}
+
+	return nil
+}
+
+func (r *BookingMemory) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	defer r.store.rlock(ctx)()
+
+	bookings := r.store.bookingsWhere(func(booking *model.Booking) bool {
+		return booking.DeletedAt == nil && booking.RoomId == roomId
+	})
+	sortByDateStart(bookings)
+
+	return bookings, nil
+}
+
+// GetByRoomIds returns bookings of all the rooms at once.
+func (r *BookingMemory) GetByRoomIds(ctx context.Context, roomIds []int) ([]*model.Booking, error) {
+	ids := make(map[int]bool, len(roomIds))
+	for _, id := range roomIds {
+		ids[id] = true
+	}
+
+	defer r.store.rlock(ctx)()
+
+	bookings := r.store.bookingsWhere(func(booking *model.Booking) bool {
+		return booking.DeletedAt == nil && ids[booking.RoomId]
+	})
+	sortByDateStart(bookings)
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].RoomId < bookings[j].RoomId
+	})
+
+	return bookings, nil
+}
+
+func (r *BookingMemory) GetById(ctx context.Context, id int) (*model.Booking, error) {
+	defer r.store.rlock(ctx)()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.DeletedAt != nil {
+		return &model.Booking{}, sql.ErrNoRows
+	}
+
+	return copyBooking(booking), nil
+}
+
+func (r *BookingMemory) GetByGuestId(ctx context.Context, guestId int) ([]*model.Booking, error) {
+	defer r.store.rlock(ctx)()
+
+	bookings := r.store.bookingsWhere(func(booking *model.Booking) bool {
+		return booking.DeletedAt == nil && booking.GuestId != nil && *booking.GuestId == guestId
+	})
+	sortByDateStart(bookings)
+
+	return bookings, nil
+}
+
+func (r *BookingMemory) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	defer r.store.rlock(ctx)()
+
+	bookings := r.store.bookingsWhere(func(booking *model.Booking) bool {
+		return booking.DeletedAt != nil
+	})
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].DeletedAt.After(*bookings[j].DeletedAt)
+	})
+
+	return bookings, nil
+}
+
+func (r *BookingMemory) GetDeletedById(ctx context.Context, id int) (*model.Booking, error) {
+	defer r.store.rlock(ctx)()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.DeletedAt == nil {
+		return &model.Booking{}, sql.ErrNoRows
+	}
+
+	return copyBooking(booking), nil
+}
+
+func (r *BookingMemory) HasOverlap(ctx context.Context, roomId int, dateStart, dateEnd time.Time) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	return r.store.hasOverlap(roomId, dateStart, dateEnd), nil
+}
+
+func (r *BookingMemory) Restore(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.DeletedAt == nil {
+		return nil
+	}
+	if r.store.hasOverlap(booking.RoomId, booking.DateStart, booking.DateEnd) {
+		return ErrBookingConflict
+	}
+	booking.DeletedAt = nil
+	booking.DeletedBy = nil
+
+	return nil
+}
+
+func (r *BookingMemory) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var purged int64
+	for id, booking := range r.store.bookings {
+		if booking.DeletedAt != nil && booking.DeletedAt.Before(deletedBefore) {
+			delete(r.store.bookings, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// hasOverlap reports whether the room has an active booking intersecting
+// the dates. The caller must hold the lock.
+func (s *memoryStore) hasOverlap(roomId int, dateStart, dateEnd time.Time) bool {
+	for _, booking := range s.bookings {
+		if booking.RoomId == roomId && booking.DeletedAt == nil &&
+			booking.DateStart.Before(dateEnd) && booking.DateEnd.After(dateStart) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bookingsWhere returns copies of the matching bookings ordered by id.
+// The caller must hold the lock.
+func (s *memoryStore) bookingsWhere(match func(*model.Booking) bool) []*model.Booking {
+	var bookings []*model.Booking
+	for _, booking := range s.bookings {
+		if match(booking) {
+			bookings = append(bookings, copyBooking(booking))
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].Id < bookings[j].Id })
+
+	return bookings
+}
+
+func sortByDateStart(bookings []*model.Booking) {
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].DateStart.Before(bookings[j].DateStart)
+	})
+}
+
+func copyBooking(booking *model.Booking) *model.Booking {
+	copied := *booking
+	copied.GuestId = copyInt(booking.GuestId)
+	copied.Guest = nil
+	copied.DeletedAt = copyTime(booking.DeletedAt)
+	copied.DeletedBy = copyString(booking.DeletedBy)
+
+	return &copied
+}
+
+func copyInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+
+	return &copied
+}
+
+func copyString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+
+	return &copied
+}
+
+func copyTime(value *time.Time) *time.Time {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+
+	return &copied
+}
@@ -1,26 +1,31 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type BookingPostgres struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	replicas *Replicas
 }
 
 func NewBookingPostgres(db *sqlx.DB) *BookingPostgres {
 	return &BookingPostgres{db: db}
 }
 
-func (r *BookingPostgres) Create(booking *model.Booking) (int, error) {
+func (r *BookingPostgres) Create(ctx context.Context, booking *model.Booking) (int, error) {
 	var id int
 	query := fmt.Sprintf(
-		`INSERT INTO %s (room_id, date_start, date_end) VALUES ($1, $2, $3) RETURNING id`,
+		`INSERT INTO %s (room_id, date_start, date_end, guest_id) VALUES ($1, $2, $3, $4) RETURNING id`,
 		bookingsTable)
-	row := r.db.QueryRow(query, booking.RoomId, booking.DateStart, booking.DateEnd)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, booking.RoomId, booking.DateStart, booking.DateEnd, booking.GuestId)
 	if err := row.Scan(&id); err != nil {
 		return 0, err
 	}
@@ -28,27 +33,126 @@ func (r *BookingPostgres) Create(booking *model.Booking) (int, error) {
 	return id, nil
 }
 
-func (r *BookingPostgres) Delete(id int) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", bookingsTable)
-	_, err := r.db.Exec(query, id)
+func (r *BookingPostgres) Delete(ctx context.Context, id int, deletedBy string) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET deleted_at=now(), deleted_by=$2 WHERE id=$1 AND deleted_at IS NULL`,
+		bookingsTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, deletedBy)
 
 	return err
 }
 
-func (r *BookingPostgres) GetByRoomId(roomId int) ([]*model.Booking, error) {
+func (r *BookingPostgres) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE room_id=$1 AND deleted_at IS NULL ORDER BY date_start`,
+		bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &bookings, query, roomId)
+
+	return bookings, err
+}
+
+// GetByRoomIds returns bookings of all the rooms in one query.
+func (r *BookingPostgres) GetByRoomIds(ctx context.Context, roomIds []int) ([]*model.Booking, error) {
 	var bookings []*model.Booking
 
 	query := fmt.Sprintf(
-		`SELECT * FROM %s WHERE room_id=$1 ORDER BY date_start`, bookingsTable)
-	err := r.db.Select(&bookings, query, roomId)
+		`SELECT * FROM %s WHERE room_id = ANY($1) AND deleted_at IS NULL ORDER BY room_id, date_start`,
+		bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &bookings, query, pq.Array(roomIds))
 
 	return bookings, err
 }
 
-func (r *BookingPostgres) GetById(id int) (*model.Booking, error) {
+func (r *BookingPostgres) GetById(ctx context.Context, id int) (*model.Booking, error) {
 	booking := &model.Booking{}
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", bookingsTable)
-	err := r.db.Get(booking, query, id)
+	query := fmt.Sprintf(
+		"SELECT * FROM %s WHERE id=$1 AND deleted_at IS NULL", bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).GetContext(ctx, booking, query, id)
 
 	return booking, err
 }
+
+func (r *BookingPostgres) GetByGuestId(ctx context.Context, guestId int) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE guest_id=$1 AND deleted_at IS NULL ORDER BY date_start`,
+		bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &bookings, query, guestId)
+
+	return bookings, err
+}
+
+func (r *BookingPostgres) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`,
+		bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &bookings, query)
+
+	return bookings, err
+}
+
+func (r *BookingPostgres) GetDeletedById(ctx context.Context, id int) (*model.Booking, error) {
+	booking := &model.Booking{}
+	query := fmt.Sprintf(
+		"SELECT * FROM %s WHERE id=$1 AND deleted_at IS NOT NULL", bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).GetContext(ctx, booking, query, id)
+
+	return booking, err
+}
+
+func (r *BookingPostgres) HasOverlap(ctx context.Context, roomId int, dateStart, dateEnd time.Time) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf(
+		`SELECT EXISTS (SELECT 1 FROM %s WHERE room_id=$1 AND deleted_at IS NULL
+		AND date_start < $3 AND date_end > $2)`,
+		bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).GetContext(ctx, &exists, query, roomId, dateStart, dateEnd)
+
+	return exists, err
+}
+
+// Restore brings the booking back unless an active booking of its room
+// took its dates, which is checked by the same statement so that
+// concurrent restores and creations cannot double-book the room.
+func (r *BookingPostgres) Restore(ctx context.Context, id int) error {
+	query := fmt.Sprintf(
+		`UPDATE %[1]s b SET deleted_at=NULL, deleted_by=NULL WHERE b.id=$1 AND b.deleted_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM %[1]s o WHERE o.room_id=b.room_id AND o.deleted_at IS NULL
+		AND o.date_start < b.date_end AND o.date_end > b.date_start)`,
+		bookingsTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if restored, err := result.RowsAffected(); err != nil || restored > 0 {
+		return err
+	}
+
+	// nothing restored: either the booking is not deleted or its dates are taken
+	var deleted bool
+	query = fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id=$1 AND deleted_at IS NOT NULL)", bookingsTable)
+	if err := conn(ctx, r.db).GetContext(ctx, &deleted, query, id); err != nil {
+		return err
+	}
+	if deleted {
+		return ErrBookingConflict
+	}
+
+	return nil
+}
+
+func (r *BookingPostgres) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < $1", bookingsTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -1,12 +1,16 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	sqlmock "github.com/zhashkevych/go-sqlxmock"
 )
@@ -45,7 +49,7 @@ func TestBookingPostgres_Create(t *testing.T) {
 				booking := args.booking
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", bookingsTable)).
-					WithArgs(booking.RoomId, booking.DateStart, booking.DateEnd).
+					WithArgs(booking.RoomId, booking.DateStart, booking.DateEnd, booking.GuestId).
 					WillReturnRows(rows)
 			},
 			want:    1,
@@ -64,7 +68,7 @@ func TestBookingPostgres_Create(t *testing.T) {
 				booking := args.booking
 				rows := sqlmock.NewRows([]string{"id"})
 				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", bookingsTable)).
-					WithArgs(booking.RoomId, booking.DateStart, booking.DateEnd).
+					WithArgs(booking.RoomId, booking.DateStart, booking.DateEnd, booking.GuestId).
 					WillReturnRows(rows)
 			},
 			wantErr: true,
@@ -75,7 +79,7 @@ func TestBookingPostgres_Create(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			got, err := r.Create(test.input.booking)
+			got, err := r.Create(context.Background(), test.input.booking)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -96,7 +100,8 @@ func TestBookingPostgres_Delete(t *testing.T) {
 	r := NewBookingPostgres(db)
 
 	type args struct {
-		id int
+		id        int
+		deletedBy string
 	}
 	type mockBehavior func(args args)
 
@@ -109,22 +114,24 @@ func TestBookingPostgres_Delete(t *testing.T) {
 		{
 			name: "Ok",
 			input: args{
-				id: 1,
+				id:        1,
+				deletedBy: "operator",
 			},
 			mock: func(args args) {
-				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", bookingsTable)).
-					WithArgs(args.id).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET deleted_at=now(.+) WHERE (.+)", bookingsTable)).
+					WithArgs(args.id, args.deletedBy).WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
 		},
 		{
 			name: "Not Found",
 			input: args{
-				id: 1,
+				id:        1,
+				deletedBy: "operator",
 			},
 			mock: func(args args) {
-				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", bookingsTable)).
-					WithArgs(args.id).WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET deleted_at=now(.+) WHERE (.+)", bookingsTable)).
+					WithArgs(args.id, args.deletedBy).WillReturnError(sql.ErrNoRows)
 			},
 			wantErr: true,
 		},
@@ -134,7 +141,7 @@ func TestBookingPostgres_Delete(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			err := r.Delete(test.input.id)
+			err := r.Delete(context.Background(), test.input.id, test.input.deletedBy)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -220,7 +227,88 @@ func TestBookingPostgres_GetByRoomId(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			got, err := r.GetByRoomId(test.input.roomId)
+			got, err := r.GetByRoomId(context.Background(), test.input.roomId)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingPostgres_GetByRoomIds(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	type args struct {
+		roomIds []int
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    []*model.Booking
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				roomIds: []int{1, 2},
+			},
+			mock: func(args args) {
+				dateStart := time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC)
+				dateEnd := time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC)
+
+				rows := sqlmock.NewRows([]string{"id", "room_id", "date_start", "date_end"}).
+					AddRow(1, 1, dateStart, dateEnd).
+					AddRow(2, 2, dateStart, dateEnd)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE room_id = ANY(.+)", bookingsTable)).
+					WithArgs(pq.Array(args.roomIds)).WillReturnRows(rows)
+			},
+			want: []*model.Booking{
+				{
+					Id:        1,
+					RoomId:    1,
+					DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					Id:        2,
+					RoomId:    2,
+					DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				roomIds: []int{1, 2},
+			},
+			mock: func(args args) {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE room_id = ANY(.+)", bookingsTable)).
+					WithArgs(pq.Array(args.roomIds)).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.GetByRoomIds(context.Background(), test.input.roomIds)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -293,7 +381,75 @@ func TestBookingPostgres_GetById(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			got, err := r.GetById(test.input.id)
+			got, err := r.GetById(context.Background(), test.input.id)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingPostgres_GetDeleted(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	deletedAt := time.Date(2021, time.January, 2, 10, 0, 0, 0, time.UTC)
+	deletedBy := "operator"
+
+	type mockBehavior func()
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		want    []*model.Booking
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				dateStart := time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC)
+				dateEnd := time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC)
+				rows := sqlmock.NewRows([]string{"id", "room_id", "date_start", "date_end", "deleted_at", "deleted_by"}).
+					AddRow(1, 1, dateStart, dateEnd, deletedAt, deletedBy)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE deleted_at IS NOT NULL", bookingsTable)).
+					WillReturnRows(rows)
+			},
+			want: []*model.Booking{
+				{
+					Id:        1,
+					RoomId:    1,
+					DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+					DeletedAt: &deletedAt,
+					DeletedBy: &deletedBy,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE deleted_at IS NOT NULL", bookingsTable)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetDeleted(context.Background())
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -303,3 +459,245 @@ func TestBookingPostgres_GetById(t *testing.T) {
 		})
 	}
 }
+
+func TestBookingPostgres_HasOverlap(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	type args struct {
+		roomId    int
+		dateStart time.Time
+		dateEnd   time.Time
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Overlap",
+			input: args{
+				roomId:    1,
+				dateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery("SELECT EXISTS (.+)").
+					WithArgs(args.roomId, args.dateStart, args.dateEnd).WillReturnRows(rows)
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "No Overlap",
+			input: args{
+				roomId:    1,
+				dateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery("SELECT EXISTS (.+)").
+					WithArgs(args.roomId, args.dateStart, args.dateEnd).WillReturnRows(rows)
+			},
+			want:    false,
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.HasOverlap(context.Background(), test.input.roomId, test.input.dateStart, test.input.dateEnd)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingPostgres_Restore(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	type args struct {
+		id int
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s b SET deleted_at=NULL(.+) WHERE (.+) AND NOT EXISTS (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not Deleted",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s b SET deleted_at=NULL(.+) WHERE (.+) AND NOT EXISTS (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery(fmt.Sprintf("SELECT EXISTS (.+) FROM %s WHERE (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Conflict",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s b SET deleted_at=NULL(.+) WHERE (.+) AND NOT EXISTS (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery(fmt.Sprintf("SELECT EXISTS (.+) FROM %s WHERE (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnRows(rows)
+			},
+			wantErr: ErrBookingConflict,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s b SET deleted_at=NULL(.+) WHERE (.+) AND NOT EXISTS (.+)", bookingsTable)).
+					WithArgs(args.id).WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			err := r.Restore(context.Background(), test.input.id)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestBookingPostgres_Purge(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	type args struct {
+		deletedBefore time.Time
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				deletedBefore: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE deleted_at IS NOT NULL (.+)", bookingsTable)).
+					WithArgs(args.deletedBefore).WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				deletedBefore: time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE deleted_at IS NOT NULL (.+)", bookingsTable)).
+					WithArgs(args.deletedBefore).WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.Purge(context.Background(), test.input.deletedBefore)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingPostgres_GetByGuestId(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewBookingPostgres(db)
+
+	guestId := 3
+
+	rows := sqlmock.NewRows([]string{"id", "room_id", "guest_id", "date_start", "date_end"}).
+		AddRow(1, 2, guestId,
+			time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC))
+	mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE guest_id=(.+)", bookingsTable)).
+		WithArgs(guestId).WillReturnRows(rows)
+
+	got, err := r.GetByGuestId(context.Background(), guestId)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Booking{
+		{
+			Id:        1,
+			RoomId:    2,
+			GuestId:   &guestId,
+			DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+			DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}, got)
+}
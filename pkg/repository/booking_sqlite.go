@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type BookingSQLite struct {
+	db *sqlx.DB
+}
+
+func NewBookingSQLite(db *sqlx.DB) *BookingSQLite {
+	return &BookingSQLite{db: db}
+}
+
+func (r *BookingSQLite) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (room_id, date_start, date_end, guest_id) VALUES (?1, ?2, ?3, ?4) RETURNING id`,
+		bookingsTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, booking.RoomId, booking.DateStart.UTC(), booking.DateEnd.UTC(),
+		booking.GuestId)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *BookingSQLite) Delete(ctx context.Context, id int, deletedBy string) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET deleted_at=?3, deleted_by=?2 WHERE id=?1 AND deleted_at IS NULL`,
+		bookingsTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, deletedBy, time.Now().UTC())
+
+	return err
+}
+
+func (r *BookingSQLite) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE room_id=?1 AND deleted_at IS NULL ORDER BY date_start`,
+		bookingsTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &bookings, query, roomId)
+
+	return bookings, err
+}
+
+// GetByRoomIds returns bookings of all the rooms in one query.
+func (r *BookingSQLite) GetByRoomIds(ctx context.Context, roomIds []int) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+	if len(roomIds) == 0 {
+		return bookings, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(
+		`SELECT * FROM %s WHERE room_id IN (?) AND deleted_at IS NULL ORDER BY room_id, date_start`,
+		bookingsTable), roomIds)
+	if err != nil {
+		return nil, err
+	}
+	err = conn(ctx, r.db).SelectContext(ctx, &bookings, query, args...)
+
+	return bookings, err
+}
+
+func (r *BookingSQLite) GetById(ctx context.Context, id int) (*model.Booking, error) {
+	booking := &model.Booking{}
+	query := fmt.Sprintf(
+		"SELECT * FROM %s WHERE id=?1 AND deleted_at IS NULL", bookingsTable)
+	err := conn(ctx, r.db).GetContext(ctx, booking, query, id)
+
+	return booking, err
+}
+
+func (r *BookingSQLite) GetByGuestId(ctx context.Context, guestId int) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE guest_id=?1 AND deleted_at IS NULL ORDER BY date_start`,
+		bookingsTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &bookings, query, guestId)
+
+	return bookings, err
+}
+
+func (r *BookingSQLite) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	var bookings []*model.Booking
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`,
+		bookingsTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &bookings, query)
+
+	return bookings, err
+}
+
+func (r *BookingSQLite) GetDeletedById(ctx context.Context, id int) (*model.Booking, error) {
+	booking := &model.Booking{}
+	query := fmt.Sprintf(
+		"SELECT * FROM %s WHERE id=?1 AND deleted_at IS NOT NULL", bookingsTable)
+	err := conn(ctx, r.db).GetContext(ctx, booking, query, id)
+
+	return booking, err
+}
+
+func (r *BookingSQLite) HasOverlap(ctx context.Context, roomId int, dateStart, dateEnd time.Time) (bool, error) {
+	return hasOverlapSQLite(ctx, conn(ctx, r.db), roomId, dateStart, dateEnd)
+}
+
+// Restore checks the dates again and restores the booking in one
+// transaction, so a booking created meanwhile cannot be overlapped.
+func (r *BookingSQLite) Restore(ctx context.Context, id int) error {
+	return runInTx(ctx, r.db, nil, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+
+		booking := &model.Booking{}
+		query := fmt.Sprintf(
+			"SELECT * FROM %s WHERE id=?1 AND deleted_at IS NOT NULL", bookingsTable)
+		if err := tx.GetContext(ctx, booking, query, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		overlap, err := hasOverlapSQLite(ctx, tx, booking.RoomId, booking.DateStart, booking.DateEnd)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return ErrBookingConflict
+		}
+
+		query = fmt.Sprintf(
+			`UPDATE %s SET deleted_at=NULL, deleted_by=NULL WHERE id=?1`,
+			bookingsTable)
+		_, err = tx.ExecContext(ctx, query, id)
+
+		return err
+	})
+}
+
+func (r *BookingSQLite) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < ?1", bookingsTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, deletedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func hasOverlapSQLite(ctx context.Context, q sqlx.QueryerContext, roomId int, dateStart, dateEnd time.Time) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf(
+		`SELECT EXISTS (SELECT 1 FROM %s WHERE room_id=?1 AND deleted_at IS NULL
+		AND date_start < ?3 AND date_end > ?2)`,
+		bookingsTable)
+	err := sqlx.GetContext(ctx, q, &exists, query, roomId, dateStart.UTC(), dateEnd.UTC())
+
+	return exists, err
+}
@@ -0,0 +1,698 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConformance checks the behaviour every Room, Booking and Webhook
+// backend must share. newRepo returns an empty repository for each test.
+func testConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("Room", func(t *testing.T) { testRoomConformance(t, newRepo) })
+	t.Run("Booking", func(t *testing.T) { testBookingConformance(t, newRepo) })
+	t.Run("Webhook", func(t *testing.T) { testWebhookConformance(t, newRepo) })
+	t.Run("Outbox", func(t *testing.T) { testOutboxConformance(t, newRepo) })
+	t.Run("APIKey", func(t *testing.T) { testAPIKeyConformance(t, newRepo) })
+	t.Run("Transaction", func(t *testing.T) { testTransactionConformance(t, newRepo) })
+}
+
+func testTransactionConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("Commit", func(t *testing.T) {
+		repo := newRepo(t)
+
+		var id int
+		committed := false
+		err := repo.Transactor.WithinTx(context.Background(), func(ctx context.Context) error {
+			var err error
+			id, err = repo.Room.Create(ctx, &model.Room{Description: "Lux", Price: 5000})
+			AfterCommit(ctx, func() {
+				// hooks run outside of the transaction and see its rows
+				_, err := repo.Room.GetById(context.Background(), id)
+				assert.Nil(t, err)
+				committed = true
+			})
+			assert.False(t, committed)
+			return err
+		})
+		require.Nil(t, err)
+		assert.True(t, committed)
+
+		_, err = repo.Room.GetById(context.Background(), id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		bookingId := createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+
+		failed := errors.New("failed")
+		err := repo.Transactor.WithinTx(context.Background(), func(ctx context.Context) error {
+			AfterCommit(ctx, func() { t.Error("hook of a rolled back transaction is run") })
+			if _, err := repo.Room.Create(ctx, &model.Room{Description: "Lux", Price: 5000}); err != nil {
+				return err
+			}
+			if err := repo.Booking.Delete(ctx, bookingId, "admin"); err != nil {
+				return err
+			}
+			return failed
+		})
+		assert.Equal(t, failed, err)
+
+		rooms, err := repo.Room.GetAll(context.Background(), "id", false)
+		assert.Nil(t, err)
+		assert.Equal(t, []int{roomId}, roomIds(rooms))
+		_, err = repo.Booking.GetById(context.Background(), bookingId)
+		assert.Nil(t, err)
+	})
+}
+
+func testRoomConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("CreateAndGetById", func(t *testing.T) {
+		repo := newRepo(t)
+
+		id, err := repo.Room.Create(context.Background(), &model.Room{Description: "Lux", Price: 5000})
+		require.Nil(t, err)
+
+		room, err := repo.Room.GetById(context.Background(), id)
+		assert.Nil(t, err)
+		assert.Equal(t, &model.Room{Id: id, Description: "Lux", Price: 5000}, room)
+	})
+
+	t.Run("GetByIdNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Room.GetById(context.Background(), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		repo := newRepo(t)
+
+		rooms, err := repo.Room.GetAll(context.Background(), "id", false)
+		assert.Nil(t, err)
+		assert.Empty(t, rooms)
+
+		ids := createRooms(t, repo, 300, 100, 200)
+		tests := []struct {
+			field string
+			desc  bool
+			want  []int
+		}{
+			{"id", false, []int{ids[0], ids[1], ids[2]}},
+			{"id", true, []int{ids[2], ids[1], ids[0]}},
+			{"price", false, []int{ids[1], ids[2], ids[0]}},
+			{"price", true, []int{ids[0], ids[2], ids[1]}},
+		}
+		for _, test := range tests {
+			rooms, err := repo.Room.GetAll(context.Background(), test.field, test.desc)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, roomIds(rooms), "sort by %s, desc %v", test.field, test.desc)
+		}
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		repo := newRepo(t)
+		ids := createRooms(t, repo, 100, 200)
+		kept := createBooking(t, repo, ids[1], "2021-01-01", "2021-01-05")
+		removed := createBooking(t, repo, ids[0], "2021-01-01", "2021-01-05")
+		removedDeleted := createBooking(t, repo, ids[0], "2021-02-01", "2021-02-05")
+		require.Nil(t, repo.Booking.Delete(context.Background(), removedDeleted, "admin"))
+
+		assert.Nil(t, repo.Room.Delete(context.Background(), ids[0]))
+
+		_, err := repo.Room.GetById(context.Background(), ids[0])
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Booking.GetById(context.Background(), removed)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Booking.GetDeletedById(context.Background(), removedDeleted)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Booking.GetById(context.Background(), kept)
+		assert.Nil(t, err)
+	})
+
+	t.Run("GetAvailable", func(t *testing.T) {
+		repo := newRepo(t)
+		ids := createRooms(t, repo, 100, 200, 300)
+		createBooking(t, repo, ids[0], "2021-01-01", "2021-01-05")
+		deleted := createBooking(t, repo, ids[1], "2021-01-01", "2021-01-05")
+		require.Nil(t, repo.Booking.Delete(context.Background(), deleted, "admin"))
+		createBooking(t, repo, ids[2], "2021-01-05", "2021-01-10")
+
+		rooms, err := repo.Room.GetAvailable(context.Background(), date(t, "2021-01-03"), date(t, "2021-01-05"))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{ids[1], ids[2]}, roomIds(rooms))
+	})
+}
+
+func testBookingConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("CreateAndGetById", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		guestId, err := repo.Guest.Create(context.Background(), &model.Guest{Name: "Ivan"})
+		require.Nil(t, err)
+
+		id, err := repo.Booking.Create(context.Background(), &model.Booking{
+			RoomId:    roomId,
+			GuestId:   &guestId,
+			DateStart: date(t, "2021-01-01"),
+			DateEnd:   date(t, "2021-01-05"),
+		})
+		require.Nil(t, err)
+
+		booking, err := repo.Booking.GetById(context.Background(), id)
+		assert.Nil(t, err)
+		assert.Equal(t, id, booking.Id)
+		assert.Equal(t, roomId, booking.RoomId)
+		assert.Equal(t, &guestId, booking.GuestId)
+		assert.Equal(t, "2021-01-01", booking.DateStart.Format(model.DateFormat))
+		assert.Equal(t, "2021-01-05", booking.DateEnd.Format(model.DateFormat))
+		assert.Nil(t, booking.DeletedAt)
+	})
+
+	t.Run("CreateWithUnknownRoom", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Booking.Create(context.Background(), &model.Booking{
+			RoomId:    1,
+			DateStart: date(t, "2021-01-01"),
+			DateEnd:   date(t, "2021-01-05"),
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("GetByIdNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Booking.GetById(context.Background(), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Booking.GetDeletedById(context.Background(), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("GetByRoomIds", func(t *testing.T) {
+		repo := newRepo(t)
+		rooms := createRooms(t, repo, 100, 200, 300)
+		late := createBooking(t, repo, rooms[0], "2021-02-01", "2021-02-05")
+		early := createBooking(t, repo, rooms[0], "2021-01-01", "2021-01-05")
+		other := createBooking(t, repo, rooms[1], "2021-01-01", "2021-01-05")
+		createBooking(t, repo, rooms[2], "2021-01-01", "2021-01-05")
+		deleted := createBooking(t, repo, rooms[1], "2021-03-01", "2021-03-05")
+		require.Nil(t, repo.Booking.Delete(context.Background(), deleted, "admin"))
+
+		bookings, err := repo.Booking.GetByRoomId(context.Background(), rooms[0])
+		assert.Nil(t, err)
+		assert.Equal(t, []int{early, late}, bookingIds(bookings))
+
+		bookings, err = repo.Booking.GetByRoomIds(context.Background(), []int{rooms[1], rooms[0]})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{early, late, other}, bookingIds(bookings))
+
+		bookings, err = repo.Booking.GetByRoomId(context.Background(), 0)
+		assert.Nil(t, err)
+		assert.Empty(t, bookings)
+	})
+
+	t.Run("GetByGuestId", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		guestId, err := repo.Guest.Create(context.Background(), &model.Guest{Name: "Ivan"})
+		require.Nil(t, err)
+		createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+		ids := make([]int, 0, 2)
+		for _, dates := range [][2]string{{"2021-03-01", "2021-03-05"}, {"2021-02-01", "2021-02-05"}} {
+			id, err := repo.Booking.Create(context.Background(), &model.Booking{
+				RoomId:    roomId,
+				GuestId:   &guestId,
+				DateStart: date(t, dates[0]),
+				DateEnd:   date(t, dates[1]),
+			})
+			require.Nil(t, err)
+			ids = append(ids, id)
+		}
+
+		bookings, err := repo.Booking.GetByGuestId(context.Background(), guestId)
+		assert.Nil(t, err)
+		assert.Equal(t, []int{ids[1], ids[0]}, bookingIds(bookings))
+	})
+
+	t.Run("DeleteAndRestore", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		id := createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+
+		assert.Nil(t, repo.Booking.Delete(context.Background(), id, "admin"))
+
+		_, err := repo.Booking.GetById(context.Background(), id)
+		assert.Equal(t, sql.ErrNoRows, err)
+		booking, err := repo.Booking.GetDeletedById(context.Background(), id)
+		assert.Nil(t, err)
+		assert.NotNil(t, booking.DeletedAt)
+		assert.Equal(t, "admin", *booking.DeletedBy)
+
+		// deleting again keeps the original operator
+		assert.Nil(t, repo.Booking.Delete(context.Background(), id, "manager"))
+		bookings, err := repo.Booking.GetDeleted(context.Background())
+		assert.Nil(t, err)
+		require.Len(t, bookings, 1)
+		assert.Equal(t, "admin", *bookings[0].DeletedBy)
+
+		assert.Nil(t, repo.Booking.Restore(context.Background(), id))
+
+		booking, err = repo.Booking.GetById(context.Background(), id)
+		assert.Nil(t, err)
+		assert.Nil(t, booking.DeletedAt)
+		assert.Nil(t, booking.DeletedBy)
+		bookings, err = repo.Booking.GetDeleted(context.Background())
+		assert.Nil(t, err)
+		assert.Empty(t, bookings)
+	})
+
+	t.Run("RestoreConflict", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		id := createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+		require.Nil(t, repo.Booking.Delete(context.Background(), id, "admin"))
+		createBooking(t, repo, roomId, "2021-01-03", "2021-01-08")
+
+		assert.Equal(t, ErrBookingConflict, repo.Booking.Restore(context.Background(), id))
+
+		booking, err := repo.Booking.GetDeletedById(context.Background(), id)
+		assert.Nil(t, err)
+		assert.NotNil(t, booking.DeletedAt)
+	})
+
+	t.Run("HasOverlap", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		createBooking(t, repo, roomId, "2021-01-05", "2021-01-10")
+		deleted := createBooking(t, repo, roomId, "2021-01-15", "2021-01-20")
+		require.Nil(t, repo.Booking.Delete(context.Background(), deleted, "admin"))
+
+		tests := []struct {
+			start, end string
+			want       bool
+		}{
+			{"2021-01-01", "2021-01-05", false},
+			{"2021-01-01", "2021-01-06", true},
+			{"2021-01-06", "2021-01-07", true},
+			{"2021-01-09", "2021-01-12", true},
+			{"2021-01-10", "2021-01-12", false},
+			{"2021-01-15", "2021-01-20", false},
+		}
+		for _, test := range tests {
+			overlap, err := repo.Booking.HasOverlap(context.Background(), roomId, date(t, test.start), date(t, test.end))
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, overlap, "%s - %s", test.start, test.end)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		repo := newRepo(t)
+		roomId := createRooms(t, repo, 100)[0]
+		active := createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+		deleted := createBooking(t, repo, roomId, "2021-02-01", "2021-02-05")
+		require.Nil(t, repo.Booking.Delete(context.Background(), deleted, "admin"))
+
+		purged, err := repo.Booking.Purge(context.Background(), time.Now().Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		purged, err = repo.Booking.Purge(context.Background(), time.Now().Add(time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		_, err = repo.Booking.GetDeletedById(context.Background(), deleted)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Booking.GetById(context.Background(), active)
+		assert.Nil(t, err)
+	})
+}
+
+func testWebhookConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("CreateUpdateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Webhook.GetById(context.Background(), 1)
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		id := createWebhook(t, repo, model.EventBookingCreated, model.EventRoomDeleted)
+		webhook, err := repo.Webhook.GetById(context.Background(), id)
+		require.Nil(t, err)
+		assert.Equal(t, "http://example.com/hook", webhook.URL)
+		assert.Equal(t, "secret", webhook.Secret)
+		assert.Equal(t, model.EventList{model.EventBookingCreated, model.EventRoomDeleted}, webhook.Events)
+		assert.True(t, webhook.Active)
+		assert.False(t, webhook.CreatedAt.IsZero())
+
+		webhook.URL = "https://example.com/other"
+		webhook.Events = model.EventList{model.EventRoomCreated}
+		webhook.Active = false
+		require.Nil(t, repo.Webhook.Update(context.Background(), webhook))
+
+		webhooks, err := repo.Webhook.GetAll(context.Background())
+		require.Nil(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, "https://example.com/other", webhooks[0].URL)
+		assert.Equal(t, model.EventList{model.EventRoomCreated}, webhooks[0].Events)
+		assert.False(t, webhooks[0].Active)
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createWebhook(t, repo, model.EventRoomCreated)
+		deliveryId := createDelivery(t, repo, id, time.Now())
+
+		assert.Nil(t, repo.Webhook.Delete(context.Background(), id))
+
+		_, err := repo.Webhook.GetById(context.Background(), id)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.Webhook.GetDeliveryById(context.Background(), deliveryId)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("DeliveryOfUnknownWebhook", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.Webhook.CreateDelivery(context.Background(), &model.WebhookDelivery{
+			WebhookId: 1, EventId: "event", EventType: model.EventRoomCreated,
+			Payload: []byte(`{}`), Status: model.DeliveryPending, NextAttemptAt: time.Now(),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("ClaimDeliveries", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createWebhook(t, repo, model.EventRoomCreated)
+		now := time.Now()
+		first := createDelivery(t, repo, id, now.Add(-time.Minute))
+		second := createDelivery(t, repo, id, now.Add(-time.Second))
+		third := createDelivery(t, repo, id, now.Add(-time.Second))
+		createDelivery(t, repo, id, now.Add(time.Minute))
+
+		claimed, err := repo.Webhook.ClaimDeliveries(context.Background(), now, time.Minute, 2)
+		require.Nil(t, err)
+		assert.Equal(t, []int{first, second}, deliveryIds(claimed))
+		assert.Equal(t, `{"id":1}`, string(claimed[0].Payload))
+
+		// claimed deliveries are leased until they are updated
+		claimed, err = repo.Webhook.ClaimDeliveries(context.Background(), now, time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{third}, deliveryIds(claimed))
+
+		claimed, err = repo.Webhook.ClaimDeliveries(context.Background(), now.Add(2*time.Minute), time.Minute, 10)
+		require.Nil(t, err)
+		assert.Len(t, claimed, 4)
+	})
+
+	t.Run("UpdateDelivery", func(t *testing.T) {
+		repo := newRepo(t)
+		id := createWebhook(t, repo, model.EventRoomCreated)
+		deliveryId := createDelivery(t, repo, id, time.Now())
+
+		delivery, err := repo.Webhook.GetDeliveryById(context.Background(), deliveryId)
+		require.Nil(t, err)
+		lastError, statusCode, deliveredAt := "timeout", 500, time.Now()
+		delivery.Status = model.DeliveryDelivered
+		delivery.Attempts = 2
+		delivery.LastError = &lastError
+		delivery.LastStatusCode = &statusCode
+		delivery.DeliveredAt = &deliveredAt
+		require.Nil(t, repo.Webhook.UpdateDelivery(context.Background(), delivery))
+
+		deliveries, err := repo.Webhook.GetDeliveries(context.Background(), id)
+		require.Nil(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, model.DeliveryDelivered, deliveries[0].Status)
+		assert.Equal(t, 2, deliveries[0].Attempts)
+		assert.Equal(t, &lastError, deliveries[0].LastError)
+		assert.Equal(t, &statusCode, deliveries[0].LastStatusCode)
+		require.NotNil(t, deliveries[0].DeliveredAt)
+		assert.WithinDuration(t, deliveredAt, *deliveries[0].DeliveredAt, time.Second)
+
+		claimed, err := repo.Webhook.ClaimDeliveries(context.Background(), time.Now().Add(time.Hour), time.Minute, 10)
+		require.Nil(t, err)
+		assert.Empty(t, claimed)
+	})
+}
+
+func testOutboxConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("ClaimInAggregateOrder", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+		first := createOutboxEvent(t, repo, 1, now.Add(-time.Minute))
+		second := createOutboxEvent(t, repo, 1, now.Add(-time.Minute))
+		other := createOutboxEvent(t, repo, 2, now.Add(-time.Minute))
+
+		claimed, err := repo.Outbox.Claim(context.Background(), now, time.Minute, 1)
+		require.Nil(t, err)
+		assert.Equal(t, []int{first}, outboxIds(claimed))
+		assert.Equal(t, "room", claimed[0].AggregateType)
+		assert.Equal(t, `{"id":1}`, string(claimed[0].Payload))
+
+		// the second event of the room waits while the first is leased
+		claimed, err = repo.Outbox.Claim(context.Background(), now, time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{other}, outboxIds(claimed))
+
+		publishedAt := time.Now()
+		require.Nil(t, repo.Outbox.Update(context.Background(), &model.OutboxEvent{
+			Id: first, Attempts: 1, NextAttemptAt: now, PublishedAt: &publishedAt}))
+		claimed, err = repo.Outbox.Claim(context.Background(), now, time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{second}, outboxIds(claimed))
+	})
+
+	t.Run("FailedEventHoldsBackAggregate", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+		first := createOutboxEvent(t, repo, 1, now)
+		second := createOutboxEvent(t, repo, 1, now)
+
+		claimed, err := repo.Outbox.Claim(context.Background(), now, time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{first, second}, outboxIds(claimed))
+
+		lastError := "unavailable"
+		require.Nil(t, repo.Outbox.Update(context.Background(), &model.OutboxEvent{
+			Id: first, Attempts: 1, NextAttemptAt: now.Add(time.Hour), LastError: &lastError}))
+
+		// the lease of the second event is over, but the first is not due
+		claimed, err = repo.Outbox.Claim(context.Background(), now.Add(2*time.Minute), time.Minute, 10)
+		require.Nil(t, err)
+		assert.Empty(t, claimed)
+
+		claimed, err = repo.Outbox.Claim(context.Background(), now.Add(2*time.Hour), time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{first, second}, outboxIds(claimed))
+		assert.Equal(t, 1, claimed[0].Attempts)
+		assert.Equal(t, &lastError, claimed[0].LastError)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+		published := createOutboxEvent(t, repo, 1, now)
+		recent := createOutboxEvent(t, repo, 2, now)
+		pending := createOutboxEvent(t, repo, 3, now)
+
+		for id, publishedAt := range map[int]time.Time{published: now.Add(-2 * time.Hour), recent: now} {
+			publishedAt := publishedAt
+			require.Nil(t, repo.Outbox.Update(context.Background(), &model.OutboxEvent{
+				Id: id, NextAttemptAt: now, PublishedAt: &publishedAt}))
+		}
+
+		purged, err := repo.Outbox.Purge(context.Background(), now.Add(-time.Hour))
+		require.Nil(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		claimed, err := repo.Outbox.Claim(context.Background(), now, time.Minute, 10)
+		require.Nil(t, err)
+		assert.Equal(t, []int{pending}, outboxIds(claimed))
+	})
+}
+
+func testAPIKeyConformance(t *testing.T, newRepo func(t *testing.T) *Repository) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+
+		_, err := repo.APIKey.GetByHash(context.Background(), "hash")
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		id, err := repo.APIKey.Create(context.Background(), &model.APIKey{
+			Name: "reception", Role: model.RoleFrontDesk, Prefix: "est_0123", Hash: "hash",
+			CreatedAt: time.Now(),
+		})
+		require.Nil(t, err)
+
+		key, err := repo.APIKey.GetByHash(context.Background(), "hash")
+		require.Nil(t, err)
+		assert.Equal(t, id, key.Id)
+		assert.Equal(t, "reception", key.Name)
+		assert.Equal(t, model.RoleFrontDesk, key.Role)
+		assert.Equal(t, "est_0123", key.Prefix)
+		assert.WithinDuration(t, time.Now(), key.CreatedAt, time.Minute)
+		assert.Nil(t, key.RevokedAt)
+
+		keys, err := repo.APIKey.GetAll(context.Background())
+		require.Nil(t, err)
+		assert.Equal(t, []*model.APIKey{key}, keys)
+	})
+
+	t.Run("DuplicateHash", func(t *testing.T) {
+		repo := newRepo(t)
+		key := &model.APIKey{Name: "key", Role: model.RoleAdmin, Hash: "hash", CreatedAt: time.Now()}
+		_, err := repo.APIKey.Create(context.Background(), key)
+		require.Nil(t, err)
+
+		_, err = repo.APIKey.Create(context.Background(), key)
+		assert.Error(t, err)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repo := newRepo(t)
+		id, err := repo.APIKey.Create(context.Background(), &model.APIKey{
+			Name: "key", Role: model.RoleAdmin, Hash: "hash", CreatedAt: time.Now(),
+		})
+		require.Nil(t, err)
+
+		revokedAt := time.Now().Add(-time.Hour)
+		require.Nil(t, repo.APIKey.Revoke(context.Background(), id, revokedAt))
+		// revoking again keeps the first time
+		require.Nil(t, repo.APIKey.Revoke(context.Background(), id, time.Now()))
+
+		key, err := repo.APIKey.GetById(context.Background(), id)
+		require.Nil(t, err)
+		require.NotNil(t, key.RevokedAt)
+		assert.WithinDuration(t, revokedAt, *key.RevokedAt, time.Second)
+
+		_, err = repo.APIKey.GetById(context.Background(), id+1)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+}
+
+func createRooms(t *testing.T, repo *Repository, prices ...int) []int {
+	t.Helper()
+
+	ids := make([]int, 0, len(prices))
+	for _, price := range prices {
+		id, err := repo.Room.Create(context.Background(), &model.Room{Description: "Room", Price: price})
+		require.Nil(t, err)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func createBooking(t *testing.T, repo *Repository, roomId int, dateStart, dateEnd string) int {
+	t.Helper()
+
+	id, err := repo.Booking.Create(context.Background(), &model.Booking{
+		RoomId:    roomId,
+		DateStart: date(t, dateStart),
+		DateEnd:   date(t, dateEnd),
+	})
+	require.Nil(t, err)
+
+	return id
+}
+
+func createWebhook(t *testing.T, repo *Repository, events ...string) int {
+	t.Helper()
+
+	id, err := repo.Webhook.Create(context.Background(), &model.Webhook{
+		URL:       "http://example.com/hook",
+		Secret:    "secret",
+		Events:    events,
+		Active:    true,
+		CreatedAt: time.Now(),
+	})
+	require.Nil(t, err)
+
+	return id
+}
+
+func createDelivery(t *testing.T, repo *Repository, webhookId int, nextAttemptAt time.Time) int {
+	t.Helper()
+
+	id, err := repo.Webhook.CreateDelivery(context.Background(), &model.WebhookDelivery{
+		WebhookId:     webhookId,
+		EventId:       "event",
+		EventType:     model.EventRoomCreated,
+		Payload:       []byte(`{"id":1}`),
+		Status:        model.DeliveryPending,
+		NextAttemptAt: nextAttemptAt,
+	})
+	require.Nil(t, err)
+
+	return id
+}
+
+func createOutboxEvent(t *testing.T, repo *Repository, roomId int, nextAttemptAt time.Time) int {
+	t.Helper()
+
+	id, err := repo.Outbox.Create(context.Background(), &model.OutboxEvent{
+		AggregateType: "room",
+		AggregateId:   roomId,
+		EventId:       "event",
+		EventType:     model.EventRoomCreated,
+		Payload:       []byte(`{"id":1}`),
+		NextAttemptAt: nextAttemptAt,
+	})
+	require.Nil(t, err)
+
+	return id
+}
+
+func date(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse(model.DateFormat, value)
+	require.Nil(t, err)
+
+	return d
+}
+
+func roomIds(rooms []*model.Room) []int {
+	ids := make([]int, 0, len(rooms))
+	for _, room := range rooms {
+		ids = append(ids, room.Id)
+	}
+
+	return ids
+}
+
+func bookingIds(bookings []*model.Booking) []int {
+	ids := make([]int, 0, len(bookings))
+	for _, booking := range bookings {
+		ids = append(ids, booking.Id)
+	}
+
+	return ids
+}
+
+func deliveryIds(deliveries []*model.WebhookDelivery) []int {
+	ids := make([]int, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.Id)
+	}
+
+	return ids
+}
+
+func outboxIds(events []*model.OutboxEvent) []int {
+	ids := make([]int, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+
+	return ids
+}
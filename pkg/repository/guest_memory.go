@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type GuestMemory struct {
+	store *memoryStore
+}
+
+func NewGuestMemory(store *memoryStore) *GuestMemory {
+	return &GuestMemory{store: store}
+}
+
+func (r *GuestMemory) Create(ctx context.Context, guest *model.Guest) (int, error) {
+	defer r.store.lock(ctx)()
+
+	r.store.guestSeq++
+	created := *guest
+	created.Id = r.store.guestSeq
+	r.store.guests[created.Id] = &created
+
+	return created.Id, nil
+}
+
+func (r *GuestMemory) GetById(ctx context.Context, id int) (*model.Guest, error) {
+	defer r.store.rlock(ctx)()
+
+	guest, ok := r.store.guests[id]
+	if !ok {
+		return &model.Guest{}, sql.ErrNoRows
+	}
+	found := *guest
+
+	return &found, nil
+}
+
+func (r *GuestMemory) FindByContact(ctx context.Context, email, phone string) ([]*model.Guest, error) {
+	defer r.store.rlock(ctx)()
+
+	var guests []*model.Guest
+	for _, guest := range r.store.guests {
+		if (email != "" && strings.EqualFold(guest.Email, email)) ||
+			(phone != "" && guest.Phone == phone) {
+			found := *guest
+			guests = append(guests, &found)
+		}
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].Id < guests[j].Id })
+
+	return guests, nil
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestGuestPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewGuestPostgres(db)
+
+	type args struct {
+		guest *model.Guest
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				guest: &model.Guest{
+					Name:           "John Smith",
+					Email:          "john@example.com",
+					Phone:          "+10000000000",
+					DocumentType:   "passport",
+					DocumentNumber: "1234 567890",
+				},
+			},
+			mock: func(args args) {
+				guest := args.guest
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", guestsTable)).
+					WithArgs(guest.Name, guest.Email, guest.Phone, guest.DocumentType, guest.DocumentNumber).
+					WillReturnRows(rows)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "Empty Name",
+			input: args{
+				guest: &model.Guest{
+					Email: "john@example.com",
+				},
+			},
+			mock: func(args args) {
+				guest := args.guest
+				rows := sqlmock.NewRows([]string{"id"})
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", guestsTable)).
+					WithArgs(guest.Name, guest.Email, guest.Phone, guest.DocumentType, guest.DocumentNumber).
+					WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.Create(context.Background(), test.input.guest)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestGuestPostgres_GetById(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewGuestPostgres(db)
+
+	type args struct {
+		id int
+	}
+	type mockBehavior func(args args)
+
+	columns := []string{"id", "name", "email", "phone", "document_type", "document_number"}
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    *model.Guest
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "John Smith", "john@example.com", "+10000000000", "passport", "1234 567890")
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", guestsTable)).
+					WithArgs(args.id).WillReturnRows(rows)
+			},
+			want: &model.Guest{
+				Id:             1,
+				Name:           "John Smith",
+				Email:          "john@example.com",
+				Phone:          "+10000000000",
+				DocumentType:   "passport",
+				DocumentNumber: "1234 567890",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Not Found",
+			input: args{
+				id: 1,
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows(columns)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", guestsTable)).
+					WithArgs(args.id).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.GetById(context.Background(), test.input.id)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestGuestPostgres_FindByContact(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewGuestPostgres(db)
+
+	type args struct {
+		email string
+		phone string
+	}
+	type mockBehavior func(args args)
+
+	columns := []string{"id", "name", "email", "phone", "document_type", "document_number"}
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    []*model.Guest
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				email: "JOHN@example.com",
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "John Smith", "john@example.com", "+10000000000", "", "")
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", guestsTable)).
+					WithArgs(args.email, args.phone).WillReturnRows(rows)
+			},
+			want: []*model.Guest{
+				{
+					Id:    1,
+					Name:  "John Smith",
+					Email: "john@example.com",
+					Phone: "+10000000000",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Ok Empty List",
+			input: args{
+				phone: "+10000000000",
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows(columns)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", guestsTable)).
+					WithArgs(args.email, args.phone).WillReturnRows(rows)
+			},
+			want:    nil,
+			wantErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.FindByContact(context.Background(), test.input.email, test.input.phone)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type GuestSQLite struct {
+	db *sqlx.DB
+}
+
+func NewGuestSQLite(db *sqlx.DB) *GuestSQLite {
+	return &GuestSQLite{db: db}
+}
+
+func (r *GuestSQLite) Create(ctx context.Context, guest *model.Guest) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (name, email, phone, document_type, document_number)
+		VALUES (?1, ?2, ?3, ?4, ?5) RETURNING id`,
+		guestsTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, guest.Name, guest.Email, guest.Phone,
+		guest.DocumentType, guest.DocumentNumber)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *GuestSQLite) GetById(ctx context.Context, id int) (*model.Guest, error) {
+	guest := &model.Guest{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?1", guestsTable)
+	err := conn(ctx, r.db).GetContext(ctx, guest, query, id)
+
+	return guest, err
+}
+
+func (r *GuestSQLite) FindByContact(ctx context.Context, email, phone string) ([]*model.Guest, error) {
+	var guests []*model.Guest
+
+	query := fmt.Sprintf(
+		`SELECT * FROM %s WHERE (?1 <> '' AND lower(email)=lower(?1)) OR (?2 <> '' AND phone=?2)
+		ORDER BY id`,
+		guestsTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &guests, query, email, phone)
+
+	return guests, err
+}
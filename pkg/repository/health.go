@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// migrationsTable is where golang-migrate keeps the schema version.
+const migrationsTable = "schema_migrations"
+
+// HealthDB checks the database the repositories work with.
+type HealthDB struct {
+	db *sqlx.DB
+}
+
+func NewHealthDB(db *sqlx.DB) *HealthDB {
+	return &HealthDB{db: db}
+}
+
+func (h *HealthDB) Ping(ctx context.Context) error {
+	return h.db.PingContext(ctx)
+}
+
+// CheckMigrations fails when the last embedded migration is not applied
+// or a migration failed half way.
+func (h *HealthDB) CheckMigrations(ctx context.Context) error {
+	latest, err := latestMigration(h.db.DriverName())
+	if err != nil {
+		return err
+	}
+
+	var version uint
+	var dirty bool
+	query := fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", migrationsTable)
+	err = h.db.QueryRowContext(ctx, query).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no migrations applied, latest is %d", latest)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d failed half way", version)
+	}
+	if version != latest {
+		return fmt.Errorf("schema version is %d, latest is %d", version, latest)
+	}
+
+	return nil
+}
+
+// HealthMemory reports the in-memory backend as always available.
+type HealthMemory struct{}
+
+func NewHealthMemory() *HealthMemory {
+	return &HealthMemory{}
+}
+
+func (h *HealthMemory) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (h *HealthMemory) CheckMigrations(ctx context.Context) error {
+	return nil
+}
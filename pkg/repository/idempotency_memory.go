@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type IdempotencyMemory struct {
+	store *memoryStore
+}
+
+func NewIdempotencyMemory(store *memoryStore) *IdempotencyMemory {
+	return &IdempotencyMemory{store: store}
+}
+
+func (r *IdempotencyMemory) Reserve(ctx context.Context, key, requestHash string) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.idempotencyKeys[key]; ok {
+		return false, nil
+	}
+	r.store.idempotencyKeys[key] = &model.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   time.Now(),
+	}
+
+	return true, nil
+}
+
+func (r *IdempotencyMemory) GetByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	defer r.store.rlock(ctx)()
+
+	idempotencyKey, ok := r.store.idempotencyKeys[key]
+	if !ok {
+		return &model.IdempotencyKey{}, sql.ErrNoRows
+	}
+	found := *idempotencyKey
+	found.Response = append([]byte(nil), idempotencyKey.Response...)
+
+	return &found, nil
+}
+
+func (r *IdempotencyMemory) SaveResponse(ctx context.Context, key string, statusCode int, contentType string, response []byte) error {
+	defer r.store.lock(ctx)()
+
+	if idempotencyKey, ok := r.store.idempotencyKeys[key]; ok {
+		idempotencyKey.StatusCode = &statusCode
+		idempotencyKey.ContentType = &contentType
+		idempotencyKey.Response = append([]byte(nil), response...)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyMemory) Delete(ctx context.Context, key string) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.idempotencyKeys, key)
+
+	return nil
+}
+
+func (r *IdempotencyMemory) Purge(ctx context.Context, createdBefore time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var purged int64
+	for key, idempotencyKey := range r.store.idempotencyKeys {
+		if idempotencyKey.CreatedAt.Before(createdBefore) {
+			delete(r.store.idempotencyKeys, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
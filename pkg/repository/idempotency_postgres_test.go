@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestIdempotencyPostgres_Reserve(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewIdempotencyPostgres(db)
+
+	type args struct {
+		key         string
+		requestHash string
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Reserved",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("INSERT INTO %s (.+) ON CONFLICT (.+)", idempotencyKeysTable)).
+					WithArgs(args.key, args.requestHash).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "Already Exists",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("INSERT INTO %s (.+) ON CONFLICT (.+)", idempotencyKeysTable)).
+					WithArgs(args.key, args.requestHash).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("INSERT INTO %s (.+) ON CONFLICT (.+)", idempotencyKeysTable)).
+					WithArgs(args.key, args.requestHash).WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.Reserve(context.Background(), test.input.key, test.input.requestHash)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyPostgres_GetByKey(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewIdempotencyPostgres(db)
+
+	statusCode := 200
+	contentType := "application/json"
+	createdAt := time.Date(2021, time.January, 5, 10, 0, 0, 0, time.UTC)
+
+	type args struct {
+		key string
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    *model.IdempotencyKey
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				key: "key",
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows([]string{"key", "request_hash", "status_code", "content_type", "response", "created_at"}).
+					AddRow("key", "hash", statusCode, contentType, []byte(`{"room_id":1}`), createdAt)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", idempotencyKeysTable)).
+					WithArgs(args.key).WillReturnRows(rows)
+			},
+			want: &model.IdempotencyKey{
+				Key:         "key",
+				RequestHash: "hash",
+				StatusCode:  &statusCode,
+				ContentType: &contentType,
+				Response:    []byte(`{"room_id":1}`),
+				CreatedAt:   createdAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Not Found",
+			input: args{
+				key: "key",
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows([]string{"key", "request_hash", "status_code", "content_type", "response", "created_at"})
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", idempotencyKeysTable)).
+					WithArgs(args.key).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.GetByKey(context.Background(), test.input.key)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyPostgres_SaveResponse(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewIdempotencyPostgres(db)
+
+	type args struct {
+		key         string
+		statusCode  int
+		contentType string
+		response    []byte
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				key:         "key",
+				statusCode:  200,
+				contentType: "application/json",
+				response:    []byte(`{"room_id":1}`),
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", idempotencyKeysTable)).
+					WithArgs(args.key, args.statusCode, args.contentType, args.response).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				key:         "key",
+				statusCode:  200,
+				contentType: "application/json",
+				response:    []byte(`{"room_id":1}`),
+			},
+			mock: func(args args) {
+				mock.ExpectExec(fmt.Sprintf("UPDATE %s SET (.+) WHERE (.+)", idempotencyKeysTable)).
+					WithArgs(args.key, args.statusCode, args.contentType, args.response).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			err := r.SaveResponse(context.Background(), test.input.key, test.input.statusCode, test.input.contentType, test.input.response)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyPostgres_Purge(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewIdempotencyPostgres(db)
+
+	createdBefore := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE created_at < (.+)", idempotencyKeysTable)).
+		WithArgs(createdBefore).WillReturnResult(sqlmock.NewResult(0, 5))
+
+	got, err := r.Purge(context.Background(), createdBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got)
+}
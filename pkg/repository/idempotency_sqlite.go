@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencySQLite struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencySQLite(db *sqlx.DB) *IdempotencySQLite {
+	return &IdempotencySQLite{db: db}
+}
+
+func (r *IdempotencySQLite) Reserve(ctx context.Context, key, requestHash string) (bool, error) {
+	query := fmt.Sprintf(
+		`INSERT INTO %s (key, request_hash, created_at) VALUES (?1, ?2, ?3) ON CONFLICT (key) DO NOTHING`,
+		idempotencyKeysTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, key, requestHash, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencySQLite) GetByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	idempotencyKey := &model.IdempotencyKey{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE key=?1", idempotencyKeysTable)
+	err := conn(ctx, r.db).GetContext(ctx, idempotencyKey, query, key)
+
+	return idempotencyKey, err
+}
+
+func (r *IdempotencySQLite) SaveResponse(ctx context.Context, key string, statusCode int, contentType string, response []byte) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET status_code=?2, content_type=?3, response=?4 WHERE key=?1`,
+		idempotencyKeysTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, key, statusCode, contentType, response)
+
+	return err
+}
+
+func (r *IdempotencySQLite) Delete(ctx context.Context, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE key=?1", idempotencyKeysTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, key)
+
+	return err
+}
+
+func (r *IdempotencySQLite) Purge(ctx context.Context, createdBefore time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < ?1", idempotencyKeysTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, createdBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+// memoryStore holds the tables of the in-memory backend. The repositories
+// share one store so that room deletion can cascade to bookings.
+type memoryStore struct {
+	mu sync.RWMutex
+	// txMu runs transactions one at a time, reads and writes outside of
+	// them wait for the running transaction
+	txMu sync.RWMutex
+
+	memoryTables
+}
+
+// memoryTables are the tables and sequences of a store, copied when a
+// transaction begins and put back when it fails.
+type memoryTables struct {
+	rooms           map[int]*model.Room
+	bookings        map[int]*model.Booking
+	guests          map[int]*model.Guest
+	idempotencyKeys map[string]*model.IdempotencyKey
+	webhooks        map[int]*model.Webhook
+	deliveries      map[int]*model.WebhookDelivery
+	outbox          map[int]*model.OutboxEvent
+	apiKeys         map[int]*model.APIKey
+
+	roomSeq     int
+	bookingSeq  int
+	guestSeq    int
+	webhookSeq  int
+	deliverySeq int
+	outboxSeq   int
+	apiKeySeq   int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{memoryTables: memoryTables{
+		rooms:           make(map[int]*model.Room),
+		bookings:        make(map[int]*model.Booking),
+		guests:          make(map[int]*model.Guest),
+		idempotencyKeys: make(map[string]*model.IdempotencyKey),
+		webhooks:        make(map[int]*model.Webhook),
+		deliveries:      make(map[int]*model.WebhookDelivery),
+		outbox:          make(map[int]*model.OutboxEvent),
+		apiKeys:         make(map[int]*model.APIKey),
+	}}
+}
+
+// lock locks the store for a write and returns the unlock function.
+// Outside of a transaction the write also waits for the running one,
+// so that a rollback cannot undo it.
+func (s *memoryStore) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) != nil {
+		s.mu.Lock()
+		return s.mu.Unlock
+	}
+
+	s.txMu.Lock()
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		s.txMu.Unlock()
+	}
+}
+
+// rlock locks the store for a read and returns the unlock function.
+// Outside of a transaction the read waits for the running one, so that
+// it cannot see rows the transaction may still roll back.
+func (s *memoryStore) rlock(ctx context.Context) func() {
+	if ctx.Value(memoryTxKey{}) != nil {
+		s.mu.RLock()
+		return s.mu.RUnlock
+	}
+
+	s.txMu.RLock()
+	s.mu.RLock()
+	return func() {
+		s.mu.RUnlock()
+		s.txMu.RUnlock()
+	}
+}
+
+// snapshot copies the tables. The stored rows are never changed in place
+// through their pointer fields, so copying the structs is enough.
+func (s *memoryStore) snapshot() memoryTables {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tables := s.memoryTables
+	tables.rooms = make(map[int]*model.Room, len(s.rooms))
+	for id, room := range s.rooms {
+		copied := *room
+		tables.rooms[id] = &copied
+	}
+	tables.bookings = make(map[int]*model.Booking, len(s.bookings))
+	for id, booking := range s.bookings {
+		copied := *booking
+		tables.bookings[id] = &copied
+	}
+	tables.guests = make(map[int]*model.Guest, len(s.guests))
+	for id, guest := range s.guests {
+		copied := *guest
+		tables.guests[id] = &copied
+	}
+	tables.idempotencyKeys = make(map[string]*model.IdempotencyKey, len(s.idempotencyKeys))
+	for key, idempotencyKey := range s.idempotencyKeys {
+		copied := *idempotencyKey
+		tables.idempotencyKeys[key] = &copied
+	}
+	tables.webhooks = make(map[int]*model.Webhook, len(s.webhooks))
+	for id, webhook := range s.webhooks {
+		copied := *webhook
+		tables.webhooks[id] = &copied
+	}
+	tables.deliveries = make(map[int]*model.WebhookDelivery, len(s.deliveries))
+	for id, delivery := range s.deliveries {
+		copied := *delivery
+		tables.deliveries[id] = &copied
+	}
+	tables.outbox = make(map[int]*model.OutboxEvent, len(s.outbox))
+	for id, event := range s.outbox {
+		copied := *event
+		tables.outbox[id] = &copied
+	}
+	tables.apiKeys = make(map[int]*model.APIKey, len(s.apiKeys))
+	for id, apiKey := range s.apiKeys {
+		copied := *apiKey
+		tables.apiKeys[id] = &copied
+	}
+
+	return tables
+}
+
+// rollback puts back the tables of a snapshot.
+func (s *memoryStore) rollback(tables memoryTables) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memoryTables = tables
+}
+
+// NewMemoryRepository returns repositories keeping all data in memory.
+// It is meant for development and tests, nothing survives a restart.
+func NewMemoryRepository() *Repository {
+	store := newMemoryStore()
+
+	return &Repository{
+		Room:        NewRoomMemory(store),
+		Booking:     NewBookingMemory(store),
+		Guest:       NewGuestMemory(store),
+		Idempotency: NewIdempotencyMemory(store),
+		Webhook:     NewWebhookMemory(store),
+		Outbox:      NewOutboxMemory(store),
+		APIKey:      NewAPIKeyMemory(store),
+		Transactor:  NewTransactorMemory(store),
+		Health:      NewHealthMemory(),
+	}
+}
+
+type memoryTxKey struct{}
+
+// TransactorMemory runs transactions one at a time. The store is copied
+// when a transaction begins and the copy is put back if fn fails or panics.
+// Hooks registered with AfterCommit run once the transaction is committed.
+type TransactorMemory struct {
+	store *memoryStore
+}
+
+func NewTransactorMemory(store *memoryStore) *TransactorMemory {
+	return &TransactorMemory{store: store}
+}
+
+func (t *TransactorMemory) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memoryTxKey{}) != nil {
+		return fn(ctx)
+	}
+
+	hooks := &commitHooks{}
+	if err := t.run(context.WithValue(ctx, commitHooksKey{}, hooks), fn); err != nil {
+		return err
+	}
+	for _, hook := range hooks.fns {
+		hook()
+	}
+
+	return nil
+}
+
+// run runs fn in a transaction, rolling the store back unless fn succeeds.
+func (t *TransactorMemory) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.store.txMu.Lock()
+	defer t.store.txMu.Unlock()
+
+	tables := t.store.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			t.store.rollback(tables)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, true)); err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+// errForeignKey mirrors a foreign key violation of the Postgres schema.
+func errForeignKey(table string, id int) error {
+	return fmt.Errorf("%s %d does not exist", table, id)
+}
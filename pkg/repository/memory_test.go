@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository_conformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) *Repository {
+		return NewMemoryRepository()
+	})
+}
+
+func TestMemoryRepository_concurrentCreate(t *testing.T) {
+	repo := NewMemoryRepository()
+
+	const workers = 50
+	var wg sync.WaitGroup
+	ids := make(chan int, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := repo.Room.Create(context.Background(), &model.Room{Description: "Room", Price: 100})
+			assert.Nil(t, err)
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	unique := make(map[int]bool)
+	for id := range ids {
+		unique[id] = true
+	}
+	assert.Len(t, unique, workers)
+}
+
+func TestMemoryRepository_returnsCopies(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, err := repo.Room.Create(context.Background(), &model.Room{Description: "Room", Price: 100})
+	assert.Nil(t, err)
+
+	room, err := repo.Room.GetById(context.Background(), id)
+	assert.Nil(t, err)
+	room.Price = 1
+
+	room, err = repo.Room.GetById(context.Background(), id)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, room.Price)
+}
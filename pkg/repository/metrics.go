@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+// Instrument wraps the repositories so that the duration of every call
+// is recorded by repository and method.
+func Instrument(repos *Repository) *Repository {
+	return &Repository{
+		Room:        &roomMetrics{repos.Room},
+		Booking:     &bookingMetrics{repos.Booking},
+		Guest:       &guestMetrics{repos.Guest},
+		Idempotency: &idempotencyMetrics{repos.Idempotency},
+		Webhook:     &webhookMetrics{repos.Webhook},
+		Outbox:      &outboxMetrics{repos.Outbox},
+		APIKey:      &apiKeyMetrics{repos.APIKey},
+		Transactor:  repos.Transactor,
+		Health:      repos.Health,
+	}
+}
+
+type roomMetrics struct {
+	repo Room
+}
+
+func (r *roomMetrics) Create(ctx context.Context, room *model.Room) (int, error) {
+	defer metrics.ObserveQuery("room", "Create", time.Now())
+	return r.repo.Create(ctx, room)
+}
+
+func (r *roomMetrics) Delete(ctx context.Context, id int) error {
+	defer metrics.ObserveQuery("room", "Delete", time.Now())
+	return r.repo.Delete(ctx, id)
+}
+
+func (r *roomMetrics) GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error) {
+	defer metrics.ObserveQuery("room", "GetAll", time.Now())
+	return r.repo.GetAll(ctx, sortField, desc)
+}
+
+func (r *roomMetrics) GetById(ctx context.Context, id int) (*model.Room, error) {
+	defer metrics.ObserveQuery("room", "GetById", time.Now())
+	return r.repo.GetById(ctx, id)
+}
+
+func (r *roomMetrics) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	defer metrics.ObserveQuery("room", "GetAvailable", time.Now())
+	return r.repo.GetAvailable(ctx, dateStart, dateEnd)
+}
+
+type bookingMetrics struct {
+	repo Booking
+}
+
+func (r *bookingMetrics) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	defer metrics.ObserveQuery("booking", "Create", time.Now())
+	return r.repo.Create(ctx, booking)
+}
+
+func (r *bookingMetrics) Delete(ctx context.Context, id int, deletedBy string) error {
+	defer metrics.ObserveQuery("booking", "Delete", time.Now())
+	return r.repo.Delete(ctx, id, deletedBy)
+}
+
+func (r *bookingMetrics) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetByRoomId", time.Now())
+	return r.repo.GetByRoomId(ctx, roomId)
+}
+
+func (r *bookingMetrics) GetByRoomIds(ctx context.Context, roomIds []int) ([]*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetByRoomIds", time.Now())
+	return r.repo.GetByRoomIds(ctx, roomIds)
+}
+
+func (r *bookingMetrics) GetById(ctx context.Context, id int) (*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetById", time.Now())
+	return r.repo.GetById(ctx, id)
+}
+
+func (r *bookingMetrics) GetByGuestId(ctx context.Context, guestId int) ([]*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetByGuestId", time.Now())
+	return r.repo.GetByGuestId(ctx, guestId)
+}
+
+func (r *bookingMetrics) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetDeleted", time.Now())
+	return r.repo.GetDeleted(ctx)
+}
+
+func (r *bookingMetrics) GetDeletedById(ctx context.Context, id int) (*model.Booking, error) {
+	defer metrics.ObserveQuery("booking", "GetDeletedById", time.Now())
+	return r.repo.GetDeletedById(ctx, id)
+}
+
+func (r *bookingMetrics) HasOverlap(ctx context.Context, roomId int, dateStart, dateEnd time.Time) (bool, error) {
+	defer metrics.ObserveQuery("booking", "HasOverlap", time.Now())
+	return r.repo.HasOverlap(ctx, roomId, dateStart, dateEnd)
+}
+
+func (r *bookingMetrics) Restore(ctx context.Context, id int) error {
+	defer metrics.ObserveQuery("booking", "Restore", time.Now())
+	return r.repo.Restore(ctx, id)
+}
+
+func (r *bookingMetrics) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	defer metrics.ObserveQuery("booking", "Purge", time.Now())
+	return r.repo.Purge(ctx, deletedBefore)
+}
+
+type guestMetrics struct {
+	repo Guest
+}
+
+func (r *guestMetrics) Create(ctx context.Context, guest *model.Guest) (int, error) {
+	defer metrics.ObserveQuery("guest", "Create", time.Now())
+	return r.repo.Create(ctx, guest)
+}
+
+func (r *guestMetrics) GetById(ctx context.Context, id int) (*model.Guest, error) {
+	defer metrics.ObserveQuery("guest", "GetById", time.Now())
+	return r.repo.GetById(ctx, id)
+}
+
+func (r *guestMetrics) FindByContact(ctx context.Context, email, phone string) ([]*model.Guest, error) {
+	defer metrics.ObserveQuery("guest", "FindByContact", time.Now())
+	return r.repo.FindByContact(ctx, email, phone)
+}
+
+type idempotencyMetrics struct {
+	repo Idempotency
+}
+
+func (r *idempotencyMetrics) Reserve(ctx context.Context, key, requestHash string) (bool, error) {
+	defer metrics.ObserveQuery("idempotency", "Reserve", time.Now())
+	return r.repo.Reserve(ctx, key, requestHash)
+}
+
+func (r *idempotencyMetrics) GetByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	defer metrics.ObserveQuery("idempotency", "GetByKey", time.Now())
+	return r.repo.GetByKey(ctx, key)
+}
+
+func (r *idempotencyMetrics) SaveResponse(ctx context.Context, key string, statusCode int, contentType string,
+	response []byte) error {
+	defer metrics.ObserveQuery("idempotency", "SaveResponse", time.Now())
+	return r.repo.SaveResponse(ctx, key, statusCode, contentType, response)
+}
+
+func (r *idempotencyMetrics) Delete(ctx context.Context, key string) error {
+	defer metrics.ObserveQuery("idempotency", "Delete", time.Now())
+	return r.repo.Delete(ctx, key)
+}
+
+func (r *idempotencyMetrics) Purge(ctx context.Context, createdBefore time.Time) (int64, error) {
+	defer metrics.ObserveQuery("idempotency", "Purge", time.Now())
+	return r.repo.Purge(ctx, createdBefore)
+}
+
+type webhookMetrics struct {
+	repo Webhook
+}
+
+func (r *webhookMetrics) Create(ctx context.Context, webhook *model.Webhook) (int, error) {
+	defer metrics.ObserveQuery("webhook", "Create", time.Now())
+	return r.repo.Create(ctx, webhook)
+}
+
+func (r *webhookMetrics) Update(ctx context.Context, webhook *model.Webhook) error {
+	defer metrics.ObserveQuery("webhook", "Update", time.Now())
+	return r.repo.Update(ctx, webhook)
+}
+
+func (r *webhookMetrics) Delete(ctx context.Context, id int) error {
+	defer metrics.ObserveQuery("webhook", "Delete", time.Now())
+	return r.repo.Delete(ctx, id)
+}
+
+func (r *webhookMetrics) GetAll(ctx context.Context) ([]*model.Webhook, error) {
+	defer metrics.ObserveQuery("webhook", "GetAll", time.Now())
+	return r.repo.GetAll(ctx)
+}
+
+func (r *webhookMetrics) GetById(ctx context.Context, id int) (*model.Webhook, error) {
+	defer metrics.ObserveQuery("webhook", "GetById", time.Now())
+	return r.repo.GetById(ctx, id)
+}
+
+func (r *webhookMetrics) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	defer metrics.ObserveQuery("webhook", "CreateDelivery", time.Now())
+	return r.repo.CreateDelivery(ctx, delivery)
+}
+
+func (r *webhookMetrics) GetDeliveryById(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("webhook", "GetDeliveryById", time.Now())
+	return r.repo.GetDeliveryById(ctx, id)
+}
+
+func (r *webhookMetrics) GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("webhook", "GetDeliveries", time.Now())
+	return r.repo.GetDeliveries(ctx, webhookId)
+}
+
+func (r *webhookMetrics) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("webhook", "ClaimDeliveries", time.Now())
+	return r.repo.ClaimDeliveries(ctx, now, lease, limit)
+}
+
+func (r *webhookMetrics) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	defer metrics.ObserveQuery("webhook", "UpdateDelivery", time.Now())
+	return r.repo.UpdateDelivery(ctx, delivery)
+}
+
+type outboxMetrics struct {
+	repo Outbox
+}
+
+func (r *outboxMetrics) Create(ctx context.Context, event *model.OutboxEvent) (int, error) {
+	defer metrics.ObserveQuery("outbox", "Create", time.Now())
+	return r.repo.Create(ctx, event)
+}
+
+func (r *outboxMetrics) Claim(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.OutboxEvent, error) {
+	defer metrics.ObserveQuery("outbox", "Claim", time.Now())
+	return r.repo.Claim(ctx, now, lease, limit)
+}
+
+func (r *outboxMetrics) Update(ctx context.Context, event *model.OutboxEvent) error {
+	defer metrics.ObserveQuery("outbox", "Update", time.Now())
+	return r.repo.Update(ctx, event)
+}
+
+func (r *outboxMetrics) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	defer metrics.ObserveQuery("outbox", "Purge", time.Now())
+	return r.repo.Purge(ctx, publishedBefore)
+}
+
+type apiKeyMetrics struct {
+	repo APIKey
+}
+
+func (r *apiKeyMetrics) Create(ctx context.Context, key *model.APIKey) (int, error) {
+	defer metrics.ObserveQuery("api_key", "Create", time.Now())
+	return r.repo.Create(ctx, key)
+}
+
+func (r *apiKeyMetrics) GetAll(ctx context.Context) ([]*model.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetAll", time.Now())
+	return r.repo.GetAll(ctx)
+}
+
+func (r *apiKeyMetrics) GetById(ctx context.Context, id int) (*model.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetById", time.Now())
+	return r.repo.GetById(ctx, id)
+}
+
+func (r *apiKeyMetrics) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetByHash", time.Now())
+	return r.repo.GetByHash(ctx, hash)
+}
+
+func (r *apiKeyMetrics) Revoke(ctx context.Context, id int, revokedAt time.Time) error {
+	defer metrics.ObserveQuery("api_key", "Revoke", time.Now())
+	return r.repo.Revoke(ctx, id, revokedAt)
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/architectv/estate-task/scripts"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration is a schema migration with its state in the database.
+type Migration struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// Migrator applies the migrations embedded from scripts. The version is
+// kept in the schema_migrations table of golang-migrate, so databases
+// migrated with its CLI are picked up as is. On Postgres every change runs
+// under an advisory lock, so concurrently starting instances apply
+// migrations once. SQLite databases get their own set of migrations.
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+}
+
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	if db.DriverName() == SQLiteDriver {
+		return newSQLiteMigrator(db)
+	}
+
+	src, err := migrationSource(PostgresDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	// a dedicated connection keeps the pool open when the migrator closes
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithConnection(context.Background(), conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, PostgresDriver, driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	return &Migrator{migrate: m, source: src}, nil
+}
+
+func newSQLiteMigrator(db *sqlx.DB) (*Migrator, error) {
+	src, err := migrationSource(SQLiteDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlite.WithInstance(db.DB, &sqlite.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, SQLiteDriver, sharedDriver{driver})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{migrate: m, source: src}, nil
+}
+
+// migrationSource returns the embedded migrations of the driver.
+func migrationSource(driver string) (source.Driver, error) {
+	if driver == SQLiteDriver {
+		return iofs.New(scripts.SQLiteMigrations, "sqlite")
+	}
+
+	return iofs.New(scripts.Migrations, ".")
+}
+
+// latestMigration returns the version of the last embedded migration of
+// the driver.
+func latestMigration(driver string) (uint, error) {
+	src, err := migrationSource(driver)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	migrations, err := listMigrations(src, 0)
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// sharedDriver keeps the pool open when the migrator closes.
+type sharedDriver struct {
+	database.Driver
+}
+
+func (sharedDriver) Close() error {
+	return nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Down reverts the last applied migration.
+func (m *Migrator) Down() error {
+	return ignoreNoChange(m.migrate.Steps(-1))
+}
+
+// To migrates up or down to the version.
+func (m *Migrator) To(version uint) error {
+	return ignoreNoChange(m.migrate.Migrate(version))
+}
+
+// Status returns the current version, whether the last migration failed
+// half way and every known migration.
+func (m *Migrator) Status() (uint, bool, []*Migration, error) {
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil, err
+	}
+
+	migrations, err := listMigrations(m.source, version)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	return version, dirty, migrations, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.migrate.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+
+	return dbErr
+}
+
+// listMigrations walks the source marking migrations up to version as
+// applied.
+func listMigrations(src source.Driver, version uint) ([]*Migration, error) {
+	var migrations []*Migration
+
+	v, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(v)
+		if readErr != nil {
+			return nil, readErr
+		}
+		r.Close()
+
+		migrations = append(migrations, &Migration{Version: v, Name: name, Applied: v <= version})
+		v, err = src.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return migrations, nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/architectv/estate-task/scripts"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListMigrations(t *testing.T) {
+	src, err := iofs.New(scripts.Migrations, ".")
+	assert.Nil(t, err)
+
+	migrations, err := listMigrations(src, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, &Migration{Version: 1, Name: "init", Applied: true}, migrations[0])
+	for i, migration := range migrations {
+		assert.Equal(t, uint(i+1), migration.Version, "versions should have no gaps")
+		assert.Equal(t, migration.Version <= 3, migration.Applied)
+	}
+
+	// every migration can be reverted
+	for _, migration := range migrations {
+		r, _, err := src.ReadDown(migration.Version)
+		assert.Nil(t, err, "migration %d has no down script", migration.Version)
+		r.Close()
+	}
+}
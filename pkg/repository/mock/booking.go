@@ -5,7 +5,9 @@
 package mock_repository
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/architectv/estate-task/pkg/model"
 	gomock "github.com/golang/mock/gomock"
@@ -35,60 +37,164 @@ func (m *MockBooking) EXPECT() *MockBookingMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockBooking) Create(arg0 *model.Booking) (int, error) {
+func (m *MockBooking) Create(arg0 context.Context, arg1 *model.Booking) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockBookingMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockBookingMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBooking)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBooking)(nil).Create), arg0, arg1)
 }
 
 // Delete mocks base method.
-func (m *MockBooking) Delete(arg0 int) error {
+func (m *MockBooking) Delete(arg0 context.Context, arg1 int, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockBookingMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockBookingMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBooking)(nil).Delete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBooking)(nil).Delete), arg0, arg1, arg2)
+}
+
+// GetByGuestId mocks base method.
+func (m *MockBooking) GetByGuestId(arg0 context.Context, arg1 int) ([]*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByGuestId", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGuestId indicates an expected call of GetByGuestId.
+func (mr *MockBookingMockRecorder) GetByGuestId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGuestId", reflect.TypeOf((*MockBooking)(nil).GetByGuestId), arg0, arg1)
 }
 
 // GetById mocks base method.
-func (m *MockBooking) GetById(arg0 int) (*model.Booking, error) {
+func (m *MockBooking) GetById(arg0 context.Context, arg1 int) (*model.Booking, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", arg0)
+	ret := m.ctrl.Call(m, "GetById", arg0, arg1)
 	ret0, _ := ret[0].(*model.Booking)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockBookingMockRecorder) GetById(arg0 interface{}) *gomock.Call {
+func (mr *MockBookingMockRecorder) GetById(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockBooking)(nil).GetById), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockBooking)(nil).GetById), arg0, arg1)
 }
 
 // GetByRoomId mocks base method.
-func (m *MockBooking) GetByRoomId(arg0 int) ([]*model.Booking, error) {
+func (m *MockBooking) GetByRoomId(arg0 context.Context, arg1 int) ([]*model.Booking, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByRoomId", arg0)
+	ret := m.ctrl.Call(m, "GetByRoomId", arg0, arg1)
 	ret0, _ := ret[0].([]*model.Booking)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByRoomId indicates an expected call of GetByRoomId.
-func (mr *MockBookingMockRecorder) GetByRoomId(arg0 interface{}) *gomock.Call {
+func (mr *MockBookingMockRecorder) GetByRoomId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRoomId", reflect.TypeOf((*MockBooking)(nil).GetByRoomId), arg0, arg1)
+}
+
+// GetByRoomIds mocks base method.
+func (m *MockBooking) GetByRoomIds(arg0 context.Context, arg1 []int) ([]*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByRoomIds", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByRoomIds indicates an expected call of GetByRoomIds.
+func (mr *MockBookingMockRecorder) GetByRoomIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRoomIds", reflect.TypeOf((*MockBooking)(nil).GetByRoomIds), arg0, arg1)
+}
+
+// GetDeleted mocks base method.
+func (m *MockBooking) GetDeleted(arg0 context.Context) ([]*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleted", arg0)
+	ret0, _ := ret[0].([]*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleted indicates an expected call of GetDeleted.
+func (mr *MockBookingMockRecorder) GetDeleted(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleted", reflect.TypeOf((*MockBooking)(nil).GetDeleted), arg0)
+}
+
+// GetDeletedById mocks base method.
+func (m *MockBooking) GetDeletedById(arg0 context.Context, arg1 int) (*model.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedById", arg0, arg1)
+	ret0, _ := ret[0].(*model.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedById indicates an expected call of GetDeletedById.
+func (mr *MockBookingMockRecorder) GetDeletedById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedById", reflect.TypeOf((*MockBooking)(nil).GetDeletedById), arg0, arg1)
+}
+
+// HasOverlap mocks base method.
+func (m *MockBooking) HasOverlap(arg0 context.Context, arg1 int, arg2, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasOverlap", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasOverlap indicates an expected call of HasOverlap.
+func (mr *MockBookingMockRecorder) HasOverlap(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasOverlap", reflect.TypeOf((*MockBooking)(nil).HasOverlap), arg0, arg1, arg2, arg3)
+}
+
+// Purge mocks base method.
+func (m *MockBooking) Purge(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockBookingMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBooking)(nil).Purge), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockBooking) Restore(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockBookingMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByRoomId", reflect.TypeOf((*MockBooking)(nil).GetByRoomId), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockBooking)(nil).Restore), arg0, arg1)
 }
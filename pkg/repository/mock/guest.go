@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/architectv/estate-task/pkg/repository (interfaces: Guest)
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/architectv/estate-task/pkg/model"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGuest is a mock of Guest interface.
+type MockGuest struct {
+	ctrl     *gomock.Controller
+	recorder *MockGuestMockRecorder
+}
+
+// MockGuestMockRecorder is the mock recorder for MockGuest.
+type MockGuestMockRecorder struct {
+	mock *MockGuest
+}
+
+// NewMockGuest creates a new mock instance.
+func NewMockGuest(ctrl *gomock.Controller) *MockGuest {
+	mock := &MockGuest{ctrl: ctrl}
+	mock.recorder = &MockGuestMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGuest) EXPECT() *MockGuestMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockGuest) Create(arg0 context.Context, arg1 *model.Guest) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGuestMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGuest)(nil).Create), arg0, arg1)
+}
+
+// FindByContact mocks base method.
+func (m *MockGuest) FindByContact(arg0 context.Context, arg1, arg2 string) ([]*model.Guest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByContact", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.Guest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByContact indicates an expected call of FindByContact.
+func (mr *MockGuestMockRecorder) FindByContact(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByContact", reflect.TypeOf((*MockGuest)(nil).FindByContact), arg0, arg1, arg2)
+}
+
+// GetById mocks base method.
+func (m *MockGuest) GetById(arg0 context.Context, arg1 int) (*model.Guest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", arg0, arg1)
+	ret0, _ := ret[0].(*model.Guest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockGuestMockRecorder) GetById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockGuest)(nil).GetById), arg0, arg1)
+}
@@ -5,7 +5,9 @@
 package mock_repository
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/architectv/estate-task/pkg/model"
 	gomock "github.com/golang/mock/gomock"
@@ -35,60 +37,75 @@ func (m *MockRoom) EXPECT() *MockRoomMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockRoom) Create(arg0 *model.Room) (int, error) {
+func (m *MockRoom) Create(arg0 context.Context, arg1 *model.Room) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRoomMockRecorder) Create(arg0 interface{}) *gomock.Call {
+func (mr *MockRoomMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoom)(nil).Create), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRoom)(nil).Create), arg0, arg1)
 }
 
 // Delete mocks base method.
-func (m *MockRoom) Delete(arg0 int) error {
+func (m *MockRoom) Delete(arg0 context.Context, arg1 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRoomMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+func (mr *MockRoomMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoom)(nil).Delete), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRoom)(nil).Delete), arg0, arg1)
 }
 
 // GetAll mocks base method.
-func (m *MockRoom) GetAll(arg0 string, arg1 bool) ([]*model.Room, error) {
+func (m *MockRoom) GetAll(arg0 context.Context, arg1 string, arg2 bool) ([]*model.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*model.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockRoomMockRecorder) GetAll(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockRoomMockRecorder) GetAll(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRoom)(nil).GetAll), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRoom)(nil).GetAll), arg0, arg1, arg2)
+}
+
+// GetAvailable mocks base method.
+func (m *MockRoom) GetAvailable(arg0 context.Context, arg1, arg2 time.Time) ([]*model.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAvailable", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAvailable indicates an expected call of GetAvailable.
+func (mr *MockRoomMockRecorder) GetAvailable(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailable", reflect.TypeOf((*MockRoom)(nil).GetAvailable), arg0, arg1, arg2)
 }
 
 // GetById mocks base method.
-func (m *MockRoom) GetById(arg0 int) (*model.Room, error) {
+func (m *MockRoom) GetById(arg0 context.Context, arg1 int) (*model.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", arg0)
+	ret := m.ctrl.Call(m, "GetById", arg0, arg1)
 	ret0, _ := ret[0].(*model.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockRoomMockRecorder) GetById(arg0 interface{}) *gomock.Call {
+func (mr *MockRoomMockRecorder) GetById(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRoom)(nil).GetById), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockRoom)(nil).GetById), arg0, arg1)
 }
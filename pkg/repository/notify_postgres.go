@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval checks the listener connection when no
+	// notifications arrive, so that a dead one is noticed
+	listenerPingInterval = 90 * time.Second
+)
+
+type NotifierPostgres struct {
+	db *sqlx.DB
+}
+
+func NewNotifierPostgres(db *sqlx.DB) *NotifierPostgres {
+	return &NotifierPostgres{db: db}
+}
+
+func (n *NotifierPostgres) Notify(ctx context.Context, channel, payload string) error {
+	_, err := conn(ctx, n.db).ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+
+	return err
+}
+
+// ListenRoomChanges invalidates the rooms of cache changed by any instance
+// until the returned function is called. Notifications may be missed while
+// the connection is re-established, so the whole cache is dropped then.
+func ListenRoomChanges(cfg Config, cache *RoomCache) (func() error, error) {
+	listener := pq.NewListener(postgresDSN(cfg), listenerMinReconnect, listenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logrus.Warnf("room cache listener: %s", err.Error())
+			}
+		})
+	if err := listener.Listen(roomsChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case notification, ok := <-listener.Notify:
+				if !ok {
+					return
+				}
+				if notification == nil {
+					cache.InvalidateAll()
+					continue
+				}
+				id, err := strconv.Atoi(notification.Extra)
+				if err != nil {
+					cache.InvalidateAll()
+					continue
+				}
+				cache.Invalidate(id)
+			case <-time.After(listenerPingInterval):
+				go listener.Ping()
+			}
+		}
+	}()
+
+	return listener.Close, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type OutboxMemory struct {
+	store *memoryStore
+}
+
+func NewOutboxMemory(store *memoryStore) *OutboxMemory {
+	return &OutboxMemory{store: store}
+}
+
+func (r *OutboxMemory) Create(ctx context.Context, event *model.OutboxEvent) (int, error) {
+	defer r.store.lock(ctx)()
+
+	r.store.outboxSeq++
+	created := &model.OutboxEvent{
+		Id:            r.store.outboxSeq,
+		AggregateType: event.AggregateType,
+		AggregateId:   event.AggregateId,
+		EventId:       event.EventId,
+		EventType:     event.EventType,
+		Payload:       append([]byte(nil), event.Payload...),
+		NextAttemptAt: event.NextAttemptAt,
+		CreatedAt:     time.Now(),
+	}
+	r.store.outbox[created.Id] = created
+
+	return created.Id, nil
+}
+
+// Claim returns up to limit due events ordered by id and postpones them by
+// lease. Events preceded by a pending event of the same aggregate that is
+// not due are left out.
+func (r *OutboxMemory) Claim(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.OutboxEvent, error) {
+	defer r.store.lock(ctx)()
+
+	var pending []*model.OutboxEvent
+	for _, event := range r.store.outbox {
+		if event.PublishedAt == nil {
+			pending = append(pending, event)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Id < pending[j].Id })
+
+	type aggregate struct {
+		typ string
+		id  int
+	}
+	blocked := make(map[aggregate]bool)
+	var events []*model.OutboxEvent
+	for _, event := range pending {
+		key := aggregate{event.AggregateType, event.AggregateId}
+		if event.NextAttemptAt.After(now) {
+			blocked[key] = true
+			continue
+		}
+		if blocked[key] || len(events) == limit {
+			continue
+		}
+
+		event.NextAttemptAt = now.Add(lease)
+		events = append(events, copyOutboxEvent(event))
+	}
+
+	return events, nil
+}
+
+func (r *OutboxMemory) Update(ctx context.Context, event *model.OutboxEvent) error {
+	defer r.store.lock(ctx)()
+
+	found, ok := r.store.outbox[event.Id]
+	if !ok {
+		return nil
+	}
+	updated := copyOutboxEvent(found)
+	updated.Attempts = event.Attempts
+	updated.NextAttemptAt = event.NextAttemptAt
+	updated.LastError = copyString(event.LastError)
+	updated.PublishedAt = copyTime(event.PublishedAt)
+	r.store.outbox[event.Id] = updated
+
+	return nil
+}
+
+func (r *OutboxMemory) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var purged int64
+	for id, event := range r.store.outbox {
+		if event.PublishedAt != nil && event.PublishedAt.Before(publishedBefore) {
+			delete(r.store.outbox, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func copyOutboxEvent(event *model.OutboxEvent) *model.OutboxEvent {
+	copied := *event
+	copied.Payload = append([]byte(nil), event.Payload...)
+	copied.LastError = copyString(event.LastError)
+	copied.PublishedAt = copyTime(event.PublishedAt)
+
+	return &copied
+}
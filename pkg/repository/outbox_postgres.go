@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// outboxLockKey is the advisory lock taken to claim outbox events.
+const outboxLockKey = 0x6f7574626f78
+
+// claimOutboxQuery picks the due events that are not preceded by a pending
+// event of the same aggregate which is leased by another relay or waits
+// for a retry, so that events of an aggregate are never relayed out of order.
+const claimOutboxQuery = `UPDATE %[1]s SET next_attempt_at=%[3]s WHERE id IN (SELECT id FROM %[1]s o
+	WHERE published_at IS NULL AND next_attempt_at <= %[2]s
+	AND NOT EXISTS (SELECT 1 FROM %[1]s p WHERE p.aggregate_type=o.aggregate_type
+		AND p.aggregate_id=o.aggregate_id AND p.published_at IS NULL AND p.id < o.id
+		AND p.next_attempt_at > %[2]s)
+	ORDER BY id LIMIT %[4]s)
+	RETURNING *`
+
+type OutboxPostgres struct {
+	db *sqlx.DB
+}
+
+func NewOutboxPostgres(db *sqlx.DB) *OutboxPostgres {
+	return &OutboxPostgres{db: db}
+}
+
+func (r *OutboxPostgres) Create(ctx context.Context, event *model.OutboxEvent) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (aggregate_type, aggregate_id, event_id, event_type, payload, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		outboxTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, event.AggregateType, event.AggregateId,
+		event.EventId, event.EventType, []byte(event.Payload), event.NextAttemptAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Claim returns up to limit due events ordered by id and postpones them by
+// lease, so that other relays skip them while they are published. Claims
+// are made one at a time under an advisory lock: a relay claiming alongside
+// another could otherwise take the next event of an aggregate whose
+// previous event is being claimed.
+func (r *OutboxPostgres) Claim(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	err := runInTx(ctx, r.db, nil, func(ctx context.Context) error {
+		tx := conn(ctx, r.db)
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", outboxLockKey); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(claimOutboxQuery, outboxTable, "$1", "$2", "$3")
+		return tx.SelectContext(ctx, &events, query, now, now.Add(lease), limit)
+	})
+	sort.Slice(events, func(i, j int) bool { return events[i].Id < events[j].Id })
+
+	return events, err
+}
+
+func (r *OutboxPostgres) Update(ctx context.Context, event *model.OutboxEvent) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET attempts=$2, next_attempt_at=$3, last_error=$4, published_at=$5 WHERE id=$1`,
+		outboxTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, event.Id, event.Attempts,
+		event.NextAttemptAt, event.LastError, event.PublishedAt)
+
+	return err
+}
+
+func (r *OutboxPostgres) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE published_at < $1", outboxTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, publishedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestOutboxPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewOutboxPostgres(db)
+	event := &model.OutboxEvent{
+		AggregateType: "booking",
+		AggregateId:   3,
+		EventId:       "event",
+		EventType:     model.EventBookingCreated,
+		Payload:       []byte(`{}`),
+		NextAttemptAt: time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", outboxTable)).
+					WithArgs(event.AggregateType, event.AggregateId, event.EventId, event.EventType,
+						[]byte(event.Payload), event.NextAttemptAt).
+					WillReturnRows(rows)
+			},
+			want: 1,
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", outboxTable)).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.Create(context.Background(), event)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOutboxPostgres_Claim(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewOutboxPostgres(db)
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "aggregate_type", "aggregate_id", "event_id", "event_type", "payload",
+		"attempts", "next_attempt_at", "last_error", "created_at", "published_at"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WithArgs(outboxLockKey).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, "room", 1, "event", "room.deleted", []byte(`{}`), 0, now.Add(time.Minute), nil, now, nil).
+					AddRow(1, "room", 1, "event", "room.created", []byte(`{}`), 1, now.Add(time.Minute), "timeout", now, nil)
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s SET next_attempt_at=(.+) NOT EXISTS(.+)", outboxTable)).
+					WithArgs(now, now.Add(time.Minute), 10).
+					WillReturnRows(rows)
+				mock.ExpectCommit()
+			},
+			want: []int{1, 2},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT pg_advisory_xact_lock").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s", outboxTable)).
+					WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.Claim(context.Background(), now, time.Minute, 10)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, outboxIds(got))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxSQLite struct {
+	db *sqlx.DB
+}
+
+func NewOutboxSQLite(db *sqlx.DB) *OutboxSQLite {
+	return &OutboxSQLite{db: db}
+}
+
+func (r *OutboxSQLite) Create(ctx context.Context, event *model.OutboxEvent) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (aggregate_type, aggregate_id, event_id, event_type, payload, next_attempt_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7) RETURNING id`,
+		outboxTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, event.AggregateType, event.AggregateId,
+		event.EventId, event.EventType, []byte(event.Payload), event.NextAttemptAt.UTC(), time.Now().UTC())
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Claim returns up to limit due events ordered by id and postpones them by
+// lease. The update takes the write lock, so relays claim one at a time.
+func (r *OutboxSQLite) Claim(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.OutboxEvent, error) {
+	var events []*model.OutboxEvent
+	query := fmt.Sprintf(claimOutboxQuery, outboxTable, "?1", "?2", "?3")
+	err := conn(ctx, r.db).SelectContext(ctx, &events, query, now.UTC(), now.Add(lease).UTC(), limit)
+	sort.Slice(events, func(i, j int) bool { return events[i].Id < events[j].Id })
+
+	return events, err
+}
+
+func (r *OutboxSQLite) Update(ctx context.Context, event *model.OutboxEvent) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET attempts=?2, next_attempt_at=?3, last_error=?4, published_at=?5 WHERE id=?1`,
+		outboxTable)
+	var publishedAt *time.Time
+	if event.PublishedAt != nil {
+		utc := event.PublishedAt.UTC()
+		publishedAt = &utc
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, event.Id, event.Attempts,
+		event.NextAttemptAt.UTC(), event.LastError, publishedAt)
+
+	return err
+}
+
+func (r *OutboxSQLite) Purge(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE published_at < ?1", outboxTable)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, publishedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Pool tunes the connection pool of the database. Zero values keep the
+// defaults of database/sql.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (p Pool) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// PoolWatcher tells when queries had to wait for a free connection,
+// which means the pool is too small for the load.
+type PoolWatcher struct {
+	db           *sql.DB
+	waitCount    int64
+	waitDuration time.Duration
+}
+
+func NewPoolWatcher(db *sql.DB) *PoolWatcher {
+	stats := db.Stats()
+
+	return &PoolWatcher{db: db, waitCount: stats.WaitCount, waitDuration: stats.WaitDuration}
+}
+
+// Waited returns how many queries waited for a connection since the last
+// call and for how long in total, along with the current pool stats.
+func (w *PoolWatcher) Waited() (int64, time.Duration, sql.DBStats) {
+	stats := w.db.Stats()
+	count, duration := stats.WaitCount-w.waitCount, stats.WaitDuration-w.waitDuration
+	w.waitCount, w.waitDuration = stats.WaitCount, stats.WaitDuration
+
+	return count, duration, stats
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDB_pool(t *testing.T) {
+	db, err := NewDB(SQLiteDriver, Config{
+		Path: filepath.Join(t.TempDir(), "estate.db"),
+		Pool: Pool{MaxOpenConns: 1, MaxIdleConns: 1},
+	})
+	require.Nil(t, err)
+	defer db.Close()
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+
+	pool := NewPoolWatcher(db.DB)
+	waits, _, _ := pool.Waited()
+	assert.Zero(t, waits)
+
+	// the only connection is busy, so the query has to wait for it
+	conn, err := db.Conn(context.Background())
+	require.Nil(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := db.Exec("SELECT 1")
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.Nil(t, conn.Close())
+	require.Nil(t, <-done)
+
+	waits, waited, stats := pool.Waited()
+	assert.Equal(t, int64(1), waits)
+	assert.True(t, waited > 0)
+	assert.Equal(t, 1, stats.MaxOpenConnections)
+
+	waits, _, _ = pool.Waited()
+	assert.Zero(t, waits)
+}
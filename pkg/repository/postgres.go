@@ -1,15 +1,31 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 )
 
 const (
-	roomsTable    = "rooms"
-	bookingsTable = "bookings"
+	roomsTable           = "rooms"
+	bookingsTable        = "bookings"
+	idempotencyKeysTable = "idempotency_keys"
+	guestsTable          = "guests"
+	webhooksTable        = "webhooks"
+	deliveriesTable      = "webhook_deliveries"
+	outboxTable          = "outbox"
+	apiKeysTable         = "api_keys"
+)
+
+// Delays between connection attempts to Postgres, doubled after every
+// failed attempt.
+var (
+	connectBackoff    = 250 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
 )
 
 type Config struct {
@@ -19,19 +35,72 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// Path is the database file of the SQLite driver.
+	Path string
+
+	Pool Pool
+	// StatementTimeout aborts Postgres statements running longer,
+	// zero means no limit.
+	StatementTimeout time.Duration
+	// ConnectTimeout is how long to retry connecting to Postgres while it
+	// is unavailable, zero means a single attempt.
+	ConnectTimeout time.Duration
 }
 
 func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode))
+	db, err := sqlx.Open("postgres", postgresDSN(cfg))
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Ping()
+	err = retry(cfg.ConnectTimeout, db.PingContext)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
+
+func postgresDSN(cfg Config) string {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode)
+	if cfg.StatementTimeout > 0 {
+		// unknown keys are sent to the server as run-time parameters
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+
+	return dsn
+}
+
+// retry calls fn with exponential backoff until it succeeds or timeout
+// passes. Every attempt gets the time left as its deadline, so that an
+// unreachable host does not hang past it.
+func retry(timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return fn(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	backoff := connectBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Until(deadline) < backoff {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+		logrus.Warnf("database is unavailable, retrying in %s: %s", backoff, err.Error())
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > connectMaxBackoff {
+			backoff = connectMaxBackoff
+		}
+	}
+}
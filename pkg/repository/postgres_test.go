@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresRepository_conformance runs against a real database given
+// by the TEST_POSTGRES_DSN environment variable and is skipped without it.
+// The tables of the database are truncated before every test.
+func TestPostgresRepository_conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	require.Nil(t, err)
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+	require.Nil(t, migrator.Up())
+	require.Nil(t, migrator.Close())
+
+	testConformance(t, func(t *testing.T) *Repository {
+		_, err := db.Exec(fmt.Sprintf("TRUNCATE %s, %s, %s, %s, %s, %s, %s, %s RESTART IDENTITY CASCADE",
+			roomsTable, bookingsTable, guestsTable, idempotencyKeysTable, webhooksTable, deliveriesTable,
+			outboxTable, apiKeysTable))
+		require.Nil(t, err)
+
+		return NewRepository(db, TxConfig{Isolation: sql.LevelSerializable, Retries: 3}, nil)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	connectBackoff, connectMaxBackoff = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { connectBackoff, connectMaxBackoff = 250*time.Millisecond, 5*time.Second })
+	errDown := errors.New("connection refused")
+
+	t.Run("Succeeds", func(t *testing.T) {
+		attempts := 0
+		err := retry(time.Second, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errDown
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Times Out", func(t *testing.T) {
+		start := time.Now()
+		err := retry(50*time.Millisecond, func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return errDown
+		})
+		assert.ErrorIs(t, err, errDown)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("No Timeout", func(t *testing.T) {
+		attempts := 0
+		err := retry(0, func(ctx context.Context) error {
+			attempts++
+			return errDown
+		})
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestNewPostgresDB_unavailable(t *testing.T) {
+	connectBackoff, connectMaxBackoff = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { connectBackoff, connectMaxBackoff = 250*time.Millisecond, 5*time.Second })
+
+	// nothing listens on port 1
+	_, err := NewPostgresDB(Config{Host: "127.0.0.1", Port: "1", Username: "postgres", DBName: "postgres",
+		SSLMode: "disable", StatementTimeout: time.Second, ConnectTimeout: 50 * time.Millisecond})
+	assert.ErrorContains(t, err, "gave up after")
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// replicaCheckTimeout bounds the ping of a replica health check.
+const replicaCheckTimeout = 2 * time.Second
+
+type replica struct {
+	name    string
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// Replicas spreads read-only queries over read replicas of the primary
+// in turn, skipping the ones that failed their last health check.
+type Replicas struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// NewReplicas opens the replicas at addrs, given as host or host:port,
+// with the other connection settings of cfg. Replicas are unhealthy until
+// checked, so an unavailable one does not stop the app from starting.
+func NewReplicas(cfg Config, addrs []string) (*Replicas, error) {
+	r := &Replicas{}
+	for _, addr := range addrs {
+		replicaCfg := cfg
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			replicaCfg.Host, replicaCfg.Port = host, port
+		} else {
+			replicaCfg.Host = addr
+		}
+
+		db, err := sqlx.Open("postgres", postgresDSN(replicaCfg))
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		cfg.Pool.apply(db.DB)
+		r.replicas = append(r.replicas, &replica{name: addr, db: db})
+	}
+	r.Check(context.Background())
+
+	return r, nil
+}
+
+// Check pings every replica and marks it healthy if it answers.
+func (r *Replicas) Check(ctx context.Context) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+		err := replica.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				logrus.Printf("replica %s is healthy", replica.name)
+			} else {
+				logrus.Warnf("replica %s is unhealthy, reading from the primary: %s", replica.name, err.Error())
+			}
+		}
+		metrics.SetReplicaUp(replica.name, healthy)
+	}
+}
+
+// pick returns the next healthy replica, or nil if there is none.
+func (r *Replicas) pick() *sqlx.DB {
+	if r == nil {
+		return nil
+	}
+
+	for range r.replicas {
+		replica := r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+
+	return nil
+}
+
+// DBs returns the connections of the replicas by address.
+func (r *Replicas) DBs() map[string]*sqlx.DB {
+	dbs := make(map[string]*sqlx.DB, len(r.replicas))
+	for _, replica := range r.replicas {
+		dbs[replica.name] = replica.db
+	}
+
+	return dbs
+}
+
+func (r *Replicas) Close() error {
+	var firstErr error
+	for _, replica := range r.replicas {
+		if err := replica.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+type primaryKey struct{}
+
+// WithPrimary pins the queries made with the returned context to the
+// primary, so that they see every committed write.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// readConn returns where a read-only query runs: a healthy replica,
+// unless the query is part of a transaction, ctx is pinned to the primary
+// or no replica is healthy.
+func readConn(ctx context.Context, db *sqlx.DB, replicas *Replicas) queryer {
+	if ctx.Value(txKey{}) == nil && ctx.Value(primaryKey{}) == nil {
+		if replica := replicas.pick(); replica != nil {
+			return tracedQueryer{replica}
+		}
+	}
+
+	return conn(ctx, db)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.Newx(sqlmock.MonitorPingsOption(true))
+	require.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db, mock
+}
+
+func TestReplicas_routing(t *testing.T) {
+	expectRoom := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE id", roomsTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description", "price"}).AddRow(1, "Room", 100))
+	}
+
+	tests := []struct {
+		name    string
+		healthy bool
+		pinned  bool
+		inTx    bool
+		replica bool
+	}{
+		{name: "Replica", healthy: true, replica: true},
+		{name: "Unhealthy Replica"},
+		{name: "Pinned", healthy: true, pinned: true},
+		{name: "Transaction", healthy: true, inTx: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary, primaryMock := newMockDB(t)
+			replicaDB, replicaMock := newMockDB(t)
+			replicas := &Replicas{replicas: []*replica{{name: "replica", db: replicaDB}}}
+			replicas.replicas[0].healthy.Store(test.healthy)
+			r := &RoomPostgres{db: primary, replicas: replicas}
+
+			ctx := context.Background()
+			if test.pinned {
+				ctx = WithPrimary(ctx)
+			}
+			if test.inTx {
+				primaryMock.ExpectBegin()
+				tx, err := primary.Beginx()
+				require.Nil(t, err)
+				ctx = context.WithValue(ctx, txKey{}, tx)
+			}
+			if test.replica {
+				expectRoom(replicaMock)
+			} else {
+				expectRoom(primaryMock)
+			}
+
+			room, err := r.GetById(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, 100, room.Price)
+			assert.Nil(t, primaryMock.ExpectationsWereMet())
+			assert.Nil(t, replicaMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplicas_Check(t *testing.T) {
+	first, firstMock := newMockDB(t)
+	second, secondMock := newMockDB(t)
+	replicas := &Replicas{replicas: []*replica{{name: "first", db: first}, {name: "second", db: second}}}
+
+	firstMock.ExpectPing()
+	secondMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	replicas.Check(context.Background())
+
+	// only the healthy replica is picked
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, first, replicas.pick())
+	}
+
+	firstMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	secondMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	replicas.Check(context.Background())
+	assert.Nil(t, replicas.pick())
+
+	firstMock.ExpectPing()
+	secondMock.ExpectPing()
+	replicas.Check(context.Background())
+	assert.ElementsMatch(t, []*sqlx.DB{first, second}, []*sqlx.DB{replicas.pick(), replicas.pick()})
+
+	var none *Replicas
+	assert.Nil(t, none.pick())
+}
@@ -1,32 +1,162 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/jmoiron/sqlx"
 )
 
+// Drivers selectable with the db.driver config key.
+const (
+	PostgresDriver = "postgres"
+	SQLiteDriver   = "sqlite"
+	MemoryDriver   = "memory"
+)
+
 type Room interface {
-	Create(room *model.Room) (int, error)
-	Delete(id int) error
-	GetAll(sortField string, desc bool) ([]*model.Room, error)
-	GetById(id int) (*model.Room, error)
+	Create(ctx context.Context, room *model.Room) (int, error)
+	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error)
+	GetById(ctx context.Context, id int) (*model.Room, error)
+	GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error)
 }
 
 type Booking interface {
-	Create(booking *model.Booking) (int, error)
-	Delete(id int) error
-	GetByRoomId(roomId int) ([]*model.Booking, error)
-	GetById(id int) (*model.Booking, error)
+	Create(ctx context.Context, booking *model.Booking) (int, error)
+	Delete(ctx context.Context, id int, deletedBy string) error
+	GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error)
+	GetByRoomIds(ctx context.Context, roomIds []int) ([]*model.Booking, error)
+	GetById(ctx context.Context, id int) (*model.Booking, error)
+	GetByGuestId(ctx context.Context, guestId int) ([]*model.Booking, error)
+	GetDeleted(ctx context.Context) ([]*model.Booking, error)
+	GetDeletedById(ctx context.Context, id int) (*model.Booking, error)
+	HasOverlap(ctx context.Context, roomId int, dateStart, dateEnd time.Time) (bool, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+type Guest interface {
+	Create(ctx context.Context, guest *model.Guest) (int, error)
+	GetById(ctx context.Context, id int) (*model.Guest, error)
+	FindByContact(ctx context.Context, email, phone string) ([]*model.Guest, error)
+}
+
+type Idempotency interface {
+	Reserve(ctx context.Context, key, requestHash string) (bool, error)
+	GetByKey(ctx context.Context, key string) (*model.IdempotencyKey, error)
+	SaveResponse(ctx context.Context, key string, statusCode int, contentType string, response []byte) error
+	Delete(ctx context.Context, key string) error
+	Purge(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, webhook *model.Webhook) (int, error)
+	Update(ctx context.Context, webhook *model.Webhook) error
+	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context) ([]*model.Webhook, error)
+	GetById(ctx context.Context, id int) (*model.Webhook, error)
+	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (int, error)
+	GetDeliveryById(ctx context.Context, id int) (*model.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+type Outbox interface {
+	Create(ctx context.Context, event *model.OutboxEvent) (int, error)
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxEvent, error)
+	Update(ctx context.Context, event *model.OutboxEvent) error
+	Purge(ctx context.Context, publishedBefore time.Time) (int64, error)
+}
+
+type APIKey interface {
+	Create(ctx context.Context, key *model.APIKey) (int, error)
+	GetAll(ctx context.Context) ([]*model.APIKey, error)
+	GetById(ctx context.Context, id int) (*model.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	Revoke(ctx context.Context, id int, revokedAt time.Time) error
+}
+
+// Transactor runs fn in a transaction. Repository calls made with the
+// context passed to fn take part in it.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Health checks that the storage is reachable and its schema is up to date.
+type Health interface {
+	Ping(ctx context.Context) error
+	CheckMigrations(ctx context.Context) error
 }
 
 type Repository struct {
 	Room
 	Booking
+	Guest
+	Idempotency
+	Webhook
+	Outbox
+	APIKey
+	Transactor
+	Health
+}
+
+// NewDB connects to the database of the driver. Postgres uses the
+// connection settings of cfg and SQLite the file at cfg.Path. Both use
+// the pool settings of cfg.
+func NewDB(driver string, cfg Config) (*sqlx.DB, error) {
+	var db *sqlx.DB
+	var err error
+	switch driver {
+	case PostgresDriver, "":
+		db, err = NewPostgresDB(cfg)
+	case SQLiteDriver:
+		db, err = NewSQLiteDB(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown db driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg.Pool.apply(db.DB)
+
+	return db, nil
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
+// NewRepository returns the repositories working with db, picked by the
+// driver it was opened with. Postgres repositories send read-only queries
+// to replicas, which may be nil.
+func NewRepository(db *sqlx.DB, txConfig TxConfig, replicas *Replicas) *Repository {
+	if db.DriverName() == SQLiteDriver {
+		// SQLite transactions are always serializable
+		txConfig.Isolation = sql.LevelDefault
+
+		return &Repository{
+			Room:        NewRoomSQLite(db),
+			Booking:     NewBookingSQLite(db),
+			Guest:       NewGuestSQLite(db),
+			Idempotency: NewIdempotencySQLite(db),
+			Webhook:     NewWebhookSQLite(db),
+			Outbox:      NewOutboxSQLite(db),
+			APIKey:      NewAPIKeySQLite(db),
+			Transactor:  NewTransactorDB(db, txConfig),
+			Health:      NewHealthDB(db),
+		}
+	}
+
 	return &Repository{
-		Room:    NewRoomPostgres(db),
-		Booking: NewBookingPostgres(db),
+		Room:        &RoomPostgres{db: db, replicas: replicas},
+		Booking:     &BookingPostgres{db: db, replicas: replicas},
+		Guest:       &GuestPostgres{db: db, replicas: replicas},
+		Idempotency: NewIdempotencyPostgres(db),
+		Webhook:     NewWebhookPostgres(db),
+		Outbox:      NewOutboxPostgres(db),
+		APIKey:      NewAPIKeyPostgres(db),
+		Transactor:  NewTransactorDB(db, txConfig),
+		Health:      NewHealthDB(db),
 	}
 }
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+// roomsChannel is the notification channel announcing room changes,
+// its payload is the id of the changed room.
+const roomsChannel = "estate_rooms"
+
+// Notifier tells the other instances of the app about changes. Postgres
+// delivers notifications sent inside a transaction once it commits.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+}
+
+type listKey struct {
+	sortField string
+	desc      bool
+}
+
+type cachedRoom struct {
+	room    model.Room
+	expires time.Time
+}
+
+type cachedList struct {
+	rooms   []model.Room
+	expires time.Time
+}
+
+// RoomCache is a read-through cache in front of a room repository. Room
+// lookups and listings are kept for ttl and dropped when rooms are created
+// or deleted, here after the change commits and on other instances through
+// the notifier. Available rooms depend on bookings and are never cached.
+//
+// Reads inside a transaction bypass the cache: they must see the changes
+// of the transaction and the rooms it locks, and what they read may never
+// be committed.
+type RoomCache struct {
+	repo     Room
+	ttl      time.Duration
+	notifier Notifier
+
+	mu    sync.Mutex
+	rooms map[int]cachedRoom
+	lists map[listKey]cachedList
+	// generation changes on every invalidation, so that rooms read before
+	// it are not stored after it
+	generation uint64
+}
+
+// NewRoomCache caches the rooms of repo for ttl, a zero ttl only sends
+// notifications. notifier may be nil when a single instance uses the
+// database.
+func NewRoomCache(repo Room, ttl time.Duration, notifier Notifier) *RoomCache {
+	return &RoomCache{
+		repo:     repo,
+		ttl:      ttl,
+		notifier: notifier,
+		rooms:    make(map[int]cachedRoom),
+		lists:    make(map[listKey]cachedList),
+	}
+}
+
+func (c *RoomCache) Create(ctx context.Context, room *model.Room) (int, error) {
+	id, err := c.repo.Create(ctx, room)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.changed(ctx, id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (c *RoomCache) Delete(ctx context.Context, id int) error {
+	if err := c.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return c.changed(ctx, id)
+}
+
+func (c *RoomCache) GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error) {
+	if inTx(ctx) {
+		return c.repo.GetAll(ctx, sortField, desc)
+	}
+
+	key := listKey{sortField: sortField, desc: desc}
+	c.mu.Lock()
+	list, ok := c.lists[key]
+	generation := c.generation
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(list.expires) {
+		metrics.RoomCacheHits.Inc()
+		rooms := make([]*model.Room, len(list.rooms))
+		for i := range list.rooms {
+			room := list.rooms[i]
+			rooms[i] = &room
+		}
+		return rooms, nil
+	}
+
+	metrics.RoomCacheMisses.Inc()
+	rooms, err := c.repo.GetAll(c.fetchContext(ctx), sortField, desc)
+	if err != nil || !c.storable(ctx) {
+		return rooms, err
+	}
+
+	list = cachedList{rooms: make([]model.Room, len(rooms)), expires: time.Now().Add(c.ttl)}
+	for i, room := range rooms {
+		list.rooms[i] = *room
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.lists[key] = list
+	}
+	c.mu.Unlock()
+
+	return rooms, nil
+}
+
+func (c *RoomCache) GetById(ctx context.Context, id int) (*model.Room, error) {
+	if inTx(ctx) {
+		return c.repo.GetById(ctx, id)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.rooms[id]
+	generation := c.generation
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		metrics.RoomCacheHits.Inc()
+		room := cached.room
+		return &room, nil
+	}
+
+	metrics.RoomCacheMisses.Inc()
+	room, err := c.repo.GetById(c.fetchContext(ctx), id)
+	if err != nil || !c.storable(ctx) {
+		return room, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.rooms[id] = cachedRoom{room: *room, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	return room, nil
+}
+
+func (c *RoomCache) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	return c.repo.GetAvailable(ctx, dateStart, dateEnd)
+}
+
+// Invalidate drops the room with the id and every listing.
+func (c *RoomCache) Invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.rooms, id)
+	c.lists = make(map[listKey]cachedList)
+	c.generation++
+	metrics.RoomCacheInvalidations.Inc()
+}
+
+// InvalidateAll empties the cache.
+func (c *RoomCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rooms = make(map[int]cachedRoom)
+	c.lists = make(map[listKey]cachedList)
+	c.generation++
+	metrics.RoomCacheInvalidations.Inc()
+}
+
+// changed tells the other instances about the change of the room and
+// drops it here once the change commits.
+func (c *RoomCache) changed(ctx context.Context, id int) error {
+	if c.notifier != nil {
+		if err := c.notifier.Notify(ctx, roomsChannel, strconv.Itoa(id)); err != nil {
+			return err
+		}
+	}
+	AfterCommit(ctx, func() { c.Invalidate(id) })
+
+	return nil
+}
+
+// fetchContext pins reads that are stored to the primary, as a lagging
+// replica could put a changed room back in the cache.
+func (c *RoomCache) fetchContext(ctx context.Context) context.Context {
+	if c.storable(ctx) {
+		return WithPrimary(ctx)
+	}
+
+	return ctx
+}
+
+func (c *RoomCache) storable(ctx context.Context) bool {
+	return c.ttl > 0 && !inTx(ctx)
+}
+
+// inTx reports whether ctx carries a transaction of the database or of
+// the in-memory backend.
+func inTx(ctx context.Context) bool {
+	if ctx.Value(txKey{}) != nil {
+		return true
+	}
+
+	return ctx.Value(memoryTxKey{}) != nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notification struct {
+	channel, payload string
+}
+
+type fakeNotifier struct {
+	sent []notification
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, channel, payload string) error {
+	n.sent = append(n.sent, notification{channel, payload})
+	return nil
+}
+
+func TestRoomCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Lookup", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		cache := NewRoomCache(repo.Room, time.Minute, nil)
+		id := createRooms(t, repo, 100)[0]
+
+		misses := testutil.ToFloat64(metrics.RoomCacheMisses)
+		hits := testutil.ToFloat64(metrics.RoomCacheHits)
+		_, err := cache.GetById(ctx, id)
+		require.Nil(t, err)
+
+		// deleted behind the cache
+		require.Nil(t, repo.Room.Delete(ctx, id))
+		room, err := cache.GetById(ctx, id)
+		require.Nil(t, err)
+		assert.Equal(t, 100, room.Price)
+		assert.Equal(t, misses+1, testutil.ToFloat64(metrics.RoomCacheMisses))
+		assert.Equal(t, hits+1, testutil.ToFloat64(metrics.RoomCacheHits))
+
+		// callers cannot change the cached room
+		room.Price = 1
+		room, err = cache.GetById(ctx, id)
+		require.Nil(t, err)
+		assert.Equal(t, 100, room.Price)
+
+		cache.Invalidate(id)
+		_, err = cache.GetById(ctx, id)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("Listing", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		notifier := &fakeNotifier{}
+		cache := NewRoomCache(repo.Room, time.Minute, notifier)
+		createRooms(t, repo, 100, 200)
+
+		rooms, err := cache.GetAll(ctx, "price", true)
+		require.Nil(t, err)
+		assert.Len(t, rooms, 2)
+		createRooms(t, repo, 300)
+		rooms, err = cache.GetAll(ctx, "price", true)
+		require.Nil(t, err)
+		assert.Len(t, rooms, 2)
+
+		id, err := cache.Create(ctx, rooms[0])
+		require.Nil(t, err)
+		rooms, err = cache.GetAll(ctx, "price", true)
+		require.Nil(t, err)
+		assert.Len(t, rooms, 4)
+		assert.Equal(t, []notification{{roomsChannel, "4"}}, notifier.sent)
+		assert.Equal(t, 4, id)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		cache := NewRoomCache(repo.Room, time.Millisecond, nil)
+		id := createRooms(t, repo, 100)[0]
+
+		_, err := cache.GetById(ctx, id)
+		require.Nil(t, err)
+		require.Nil(t, repo.Room.Delete(ctx, id))
+		time.Sleep(5 * time.Millisecond)
+
+		_, err = cache.GetById(ctx, id)
+		assert.Equal(t, sql.ErrNoRows, err)
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		repo := newSQLiteRepository(t)
+		notifier := &fakeNotifier{}
+		cache := NewRoomCache(repo.Room, time.Minute, notifier)
+		ids := createRooms(t, repo, 100, 200)
+		_, err := cache.GetById(ctx, ids[0])
+		require.Nil(t, err)
+
+		// a rolled back delete keeps the room cached
+		errRollback := errors.New("rollback")
+		err = repo.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			require.Nil(t, cache.Delete(ctx, ids[0]))
+			return errRollback
+		})
+		assert.Equal(t, errRollback, err)
+		_, err = cache.GetById(ctx, ids[0])
+		assert.Nil(t, err)
+
+		// a committed one drops it, the transaction itself reads past the cache
+		_, err = cache.GetAll(ctx, "id", false)
+		require.Nil(t, err)
+		err = repo.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			require.Nil(t, cache.Delete(ctx, ids[0]))
+			_, err := cache.GetById(ctx, ids[0])
+			assert.Equal(t, sql.ErrNoRows, err)
+			rooms, err := cache.GetAll(ctx, "id", false)
+			assert.Nil(t, err)
+			assert.Equal(t, []int{ids[1]}, roomIds(rooms))
+			return nil
+		})
+		assert.Nil(t, err)
+		_, err = cache.GetById(ctx, ids[0])
+		assert.Equal(t, sql.ErrNoRows, err)
+
+		// rooms read in a transaction are not stored
+		err = repo.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := cache.GetById(ctx, ids[1])
+			return err
+		})
+		require.Nil(t, err)
+		misses := testutil.ToFloat64(metrics.RoomCacheMisses)
+		_, err = cache.GetById(ctx, ids[1])
+		require.Nil(t, err)
+		assert.Equal(t, misses+1, testutil.ToFloat64(metrics.RoomCacheMisses))
+	})
+}
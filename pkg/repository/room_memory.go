@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type RoomMemory struct {
+	store *memoryStore
+}
+
+func NewRoomMemory(store *memoryStore) *RoomMemory {
+	return &RoomMemory{store: store}
+}
+
+func (r *RoomMemory) Create(ctx context.Context, room *model.Room) (int, error) {
+	defer r.store.lock(ctx)()
+
+	r.store.roomSeq++
+	created := *room
+	created.Id = r.store.roomSeq
+	r.store.rooms[created.Id] = &created
+
+	return created.Id, nil
+}
+
+// Delete removes the room together with its bookings, like the
+// ON DELETE CASCADE of the bookings table.
+func (r *RoomMemory) Delete(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.rooms, id)
+	for bookingId, booking := range r.store.bookings {
+		if booking.RoomId == id {
+			delete(r.store.bookings, bookingId)
+		}
+	}
+
+	return nil
+}
+
+func (r *RoomMemory) GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error) {
+	var less func(a, b *model.Room) bool
+	switch sortField {
+	case "id":
+		less = func(a, b *model.Room) bool { return a.Id < b.Id }
+	case "price":
+		less = func(a, b *model.Room) bool { return a.Price < b.Price }
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", sortField)
+	}
+
+	unlock := r.store.rlock(ctx)
+	rooms := r.store.roomsWhere(func(*model.Room) bool { return true })
+	unlock()
+
+	sort.SliceStable(rooms, func(i, j int) bool {
+		if desc {
+			return less(rooms[j], rooms[i])
+		}
+		return less(rooms[i], rooms[j])
+	})
+
+	return rooms, nil
+}
+
+func (r *RoomMemory) GetById(ctx context.Context, id int) (*model.Room, error) {
+	defer r.store.rlock(ctx)()
+
+	room, ok := r.store.rooms[id]
+	if !ok {
+		return &model.Room{}, sql.ErrNoRows
+	}
+	found := *room
+
+	return &found, nil
+}
+
+// GetAvailable returns rooms without bookings overlapping the dates.
+func (r *RoomMemory) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	defer r.store.rlock(ctx)()
+
+	return r.store.roomsWhere(func(room *model.Room) bool {
+		return !r.store.hasOverlap(room.Id, dateStart, dateEnd)
+	}), nil
+}
+
+// roomsWhere returns copies of the matching rooms ordered by id.
+// The caller must hold the lock.
+func (s *memoryStore) roomsWhere(match func(*model.Room) bool) []*model.Room {
+	var rooms []*model.Room
+	for _, room := range s.rooms {
+		if match(room) {
+			found := *room
+			rooms = append(rooms, &found)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Id < rooms[j].Id })
+
+	return rooms
+}
@@ -1,26 +1,29 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/jmoiron/sqlx"
 )
 
 type RoomPostgres struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	replicas *Replicas
 }
 
 func NewRoomPostgres(db *sqlx.DB) *RoomPostgres {
 	return &RoomPostgres{db: db}
 }
 
-func (r *RoomPostgres) Create(room *model.Room) (int, error) {
+func (r *RoomPostgres) Create(ctx context.Context, room *model.Room) (int, error) {
 	var id int
 	query := fmt.Sprintf(
 		`INSERT INTO %s (description, price) VALUES ($1, $2) RETURNING id`,
 		roomsTable)
-	row := r.db.QueryRow(query, room.Description, room.Price)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, room.Description, room.Price)
 	if err := row.Scan(&id); err != nil {
 		return 0, err
 	}
@@ -28,29 +31,42 @@ func (r *RoomPostgres) Create(room *model.Room) (int, error) {
 	return id, nil
 }
 
-func (r *RoomPostgres) Delete(id int) error {
+func (r *RoomPostgres) Delete(ctx context.Context, id int) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", roomsTable)
-	_, err := r.db.Exec(query, id)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 
 	return err
 }
 
-func (r *RoomPostgres) GetAll(sortField string, desc bool) ([]*model.Room, error) {
+func (r *RoomPostgres) GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error) {
 	var rooms []*model.Room
 
 	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s", roomsTable, sortField)
 	if desc {
 		query += " DESC"
 	}
-	err := r.db.Select(&rooms, query)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &rooms, query)
 
 	return rooms, err
 }
 
-func (r *RoomPostgres) GetById(id int) (*model.Room, error) {
+func (r *RoomPostgres) GetById(ctx context.Context, id int) (*model.Room, error) {
 	room := &model.Room{}
 	query := fmt.Sprintf("SELECT * FROM %s WHERE id=$1", roomsTable)
-	err := r.db.Get(room, query, id)
+	err := readConn(ctx, r.db, r.replicas).GetContext(ctx, room, query, id)
 
 	return room, err
 }
+
+// GetAvailable returns rooms without bookings overlapping the dates.
+func (r *RoomPostgres) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	var rooms []*model.Room
+
+	query := fmt.Sprintf(
+		`SELECT r.* FROM %s r WHERE NOT EXISTS (SELECT 1 FROM %s b WHERE b.room_id=r.id
+		AND b.deleted_at IS NULL AND b.date_start < $2 AND b.date_end > $1) ORDER BY r.id`,
+		roomsTable, bookingsTable)
+	err := readConn(ctx, r.db, r.replicas).SelectContext(ctx, &rooms, query, dateStart, dateEnd)
+
+	return rooms, err
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
@@ -90,7 +92,7 @@ func TestRoomPostgres_Create(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			got, err := r.Create(test.input.room)
+			got, err := r.Create(context.Background(), test.input.room)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -149,7 +151,7 @@ func TestRoomPostgres_Delete(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			err := r.Delete(test.input.id)
+			err := r.Delete(context.Background(), test.input.id)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -313,7 +315,7 @@ func TestRoomPostgres_GetAll(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock()
 
-			got, err := r.GetAll(test.input.sortField, test.input.desc)
+			got, err := r.GetAll(context.Background(), test.input.sortField, test.input.desc)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -383,7 +385,102 @@ func TestRoomPostgres_GetById(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mock(test.input)
 
-			got, err := r.GetById(test.input.id)
+			got, err := r.GetById(context.Background(), test.input.id)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestRoomPostgres_GetById_cancelled(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewRoomPostgres(db)
+
+	rows := sqlmock.NewRows([]string{"id", "description", "price"}).AddRow(1, "description1", 1000)
+	mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", roomsTable)).
+		WithArgs(1).WillDelayFor(time.Second).WillReturnRows(rows)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = r.GetById(ctx, 1)
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestRoomPostgres_GetAvailable(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewRoomPostgres(db)
+
+	type args struct {
+		dateStart time.Time
+		dateEnd   time.Time
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    []*model.Room
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				dateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				rows := sqlmock.NewRows([]string{"id", "description", "price"}).
+					AddRow(2, "description2", 2000)
+
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s r WHERE NOT EXISTS (.+)", roomsTable)).
+					WithArgs(args.dateStart, args.dateEnd).WillReturnRows(rows)
+			},
+			want: []*model.Room{
+				{
+					Id:          2,
+					Description: "description2",
+					Price:       2000,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				dateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(args args) {
+				mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s r WHERE NOT EXISTS (.+)", roomsTable)).
+					WithArgs(args.dateStart, args.dateEnd).WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.GetAvailable(context.Background(), test.input.dateStart, test.input.dateEnd)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
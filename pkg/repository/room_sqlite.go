@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type RoomSQLite struct {
+	db *sqlx.DB
+}
+
+func NewRoomSQLite(db *sqlx.DB) *RoomSQLite {
+	return &RoomSQLite{db: db}
+}
+
+func (r *RoomSQLite) Create(ctx context.Context, room *model.Room) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (description, price) VALUES (?1, ?2) RETURNING id`,
+		roomsTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, room.Description, room.Price)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *RoomSQLite) Delete(ctx context.Context, id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=?1", roomsTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+func (r *RoomSQLite) GetAll(ctx context.Context, sortField string, desc bool) ([]*model.Room, error) {
+	var rooms []*model.Room
+
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s", roomsTable, sortField)
+	if desc {
+		query += " DESC"
+	}
+	err := conn(ctx, r.db).SelectContext(ctx, &rooms, query)
+
+	return rooms, err
+}
+
+func (r *RoomSQLite) GetById(ctx context.Context, id int) (*model.Room, error) {
+	room := &model.Room{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?1", roomsTable)
+	err := conn(ctx, r.db).GetContext(ctx, room, query, id)
+
+	return room, err
+}
+
+// GetAvailable returns rooms without bookings overlapping the dates.
+func (r *RoomSQLite) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	var rooms []*model.Room
+
+	query := fmt.Sprintf(
+		`SELECT r.* FROM %s r WHERE NOT EXISTS (SELECT 1 FROM %s b WHERE b.room_id=r.id
+		AND b.deleted_at IS NULL AND b.date_start < ?2 AND b.date_end > ?1) ORDER BY r.id`,
+		roomsTable, bookingsTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &rooms, query, dateStart.UTC(), dateEnd.UTC())
+
+	return rooms, err
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLiteDB opens the database file at path, creating it if needed.
+// Transactions take the write lock when they begin, so a check followed
+// by a write inside one transaction cannot interleave with another writer.
+func NewSQLiteDB(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open(SQLiteDriver, fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"+
+			"&_txlock=immediate&_time_format=sqlite", path))
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
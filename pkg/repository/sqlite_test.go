@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "estate.db"))
+	require.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+	require.Nil(t, migrator.Up())
+	require.Nil(t, migrator.Close())
+
+	return NewRepository(db, TxConfig{Isolation: sql.LevelSerializable, Retries: 3}, nil)
+}
+
+func TestSQLiteRepository_conformance(t *testing.T) {
+	testConformance(t, newSQLiteRepository)
+}
+
+func TestBookingSQLite_Restore(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	roomId := createRooms(t, repo, 100)[0]
+	deleted := createBooking(t, repo, roomId, "2021-01-01", "2021-01-05")
+	require.Nil(t, repo.Booking.Delete(context.Background(), deleted, "admin"))
+	createBooking(t, repo, roomId, "2021-01-03", "2021-01-07")
+
+	assert.Equal(t, ErrBookingConflict, repo.Booking.Restore(context.Background(), deleted))
+
+	_, err := repo.Booking.GetDeletedById(context.Background(), deleted)
+	assert.Nil(t, err)
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "estate.db"))
+	require.Nil(t, err)
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+	require.Nil(t, migrator.Up())
+
+	version, dirty, migrations, err := migrator.Status()
+	assert.Nil(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, migrations[len(migrations)-1].Version, version)
+
+	require.Nil(t, migrator.Down())
+	require.Nil(t, migrator.Close())
+
+	// closing the migrator keeps the pool usable
+	var tables int
+	assert.Nil(t, db.Get(&tables, "SELECT count(*) FROM sqlite_master WHERE name=?1", apiKeysTable))
+	assert.Equal(t, 0, tables)
+}
+
+func TestHealthDB_SQLite(t *testing.T) {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "estate.db"))
+	require.Nil(t, err)
+	defer db.Close()
+
+	health := NewHealthDB(db)
+	assert.Nil(t, health.Ping(context.Background()))
+	assert.Error(t, health.CheckMigrations(context.Background()))
+
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+	defer migrator.Close()
+	assert.EqualError(t, health.CheckMigrations(context.Background()), "no migrations applied, latest is 4")
+
+	require.Nil(t, migrator.Up())
+	assert.Nil(t, health.CheckMigrations(context.Background()))
+}
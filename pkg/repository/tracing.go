@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/architectv/estate-task/pkg/tracing"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("repository")
+
+// tracedQueryer starts a span for every statement run with it.
+type tracedQueryer struct {
+	queryer
+}
+
+func (q tracedQueryer) startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := "SQL"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", q.DriverName()),
+			attribute.String("db.statement", query),
+		))
+}
+
+// endSpan ends the span of a statement that returned err. Missing rows are
+// an expected result rather than a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (q tracedQueryer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := q.startSpan(ctx, query)
+	result, err := q.queryer.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return result, err
+}
+
+func (q tracedQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := q.startSpan(ctx, query)
+	rows, err := q.queryer.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (q tracedQueryer) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	ctx, span := q.startSpan(ctx, query)
+	rows, err := q.queryer.QueryxContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (q tracedQueryer) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	ctx, span := q.startSpan(ctx, query)
+	row := q.queryer.QueryRowxContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+func (q tracedQueryer) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := q.startSpan(ctx, query)
+	row := q.queryer.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+func (q tracedQueryer) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := q.startSpan(ctx, query)
+	err := q.queryer.GetContext(ctx, dest, query, args...)
+	endSpan(span, err)
+	return err
+}
+
+func (q tracedQueryer) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, span := q.startSpan(ctx, query)
+	err := q.queryer.SelectContext(ctx, dest, query, args...)
+	endSpan(span, err)
+	return err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedQueryer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(fmt.Sprintf("SELECT (.+) FROM %s WHERE (.+)", roomsTable)).
+		WithArgs(1).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(fmt.Sprintf("DELETE FROM %s WHERE (.+)", roomsTable)).
+		WithArgs(1).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	rooms := NewRoomPostgres(db)
+	err = NewTransactorDB(db, TxConfig{}).WithinTx(context.Background(), func(ctx context.Context) error {
+		rooms.GetById(ctx, 1)
+		return rooms.Delete(ctx, 1)
+	})
+	assert.Equal(t, sql.ErrConnDone, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	get, del, tx := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "SELECT", get.Name())
+	assert.Contains(t, get.Attributes(), attribute.String("db.statement",
+		fmt.Sprintf("SELECT * FROM %s WHERE id=$1", roomsTable)))
+	// a missing row is not a failure
+	assert.Equal(t, codes.Unset, get.Status().Code)
+
+	assert.Equal(t, "DELETE", del.Name())
+	assert.Equal(t, codes.Error, del.Status().Code)
+
+	assert.Equal(t, "transaction", tx.Name())
+	assert.Equal(t, codes.Error, tx.Status().Code)
+	for _, span := range []sdktrace.ReadOnlySpan{get, del} {
+		assert.Equal(t, tx.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Postgres error codes of transactions that may succeed when retried.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+const retryDelay = 10 * time.Millisecond
+
+// TxConfig sets up the transactions of a Transactor.
+type TxConfig struct {
+	Isolation sql.IsolationLevel
+	// Retries is how many more times a transaction is run after
+	// a serialization failure.
+	Retries int
+}
+
+// ParseIsolation parses an isolation level like "repeatable read".
+// An empty string is the default level of the database.
+func ParseIsolation(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return 0, fmt.Errorf("unknown isolation level %q", level)
+	}
+}
+
+type txKey struct{}
+
+// queryer is implemented by both *sqlx.DB and *sqlx.Tx.
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction started by a Transactor for ctx, if any,
+// and db otherwise. Statements run with it are traced.
+func conn(ctx context.Context, db *sqlx.DB) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tracedQueryer{tx}
+	}
+
+	return tracedQueryer{db}
+}
+
+// TransactorDB runs functions in database transactions.
+type TransactorDB struct {
+	db     *sqlx.DB
+	config TxConfig
+}
+
+func NewTransactorDB(db *sqlx.DB, config TxConfig) *TransactorDB {
+	return &TransactorDB{db: db, config: config}
+}
+
+// WithinTx runs fn in a transaction that is committed if fn succeeds.
+// When the transaction fails on a serialization conflict, fn is run again
+// in a new one up to config.Retries times. Calls made inside a transaction
+// join it.
+func (t *TransactorDB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runInTx(ctx, t.db, &sql.TxOptions{Isolation: t.config.Isolation}, fn)
+		if err == nil || attempt >= t.config.Retries || !isRetryable(err) {
+			return err
+		}
+		logging.FromContext(ctx).Warnf("retrying transaction: %s", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func runInTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions,
+	fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracer.Start(ctx, "transaction", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", db.DriverName())))
+	defer func() { endSpan(span, err) }()
+
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	hooks := &commitHooks{}
+	ctx = context.WithValue(ctx, commitHooksKey{}, hooks)
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, hook := range hooks.fns {
+		hook()
+	}
+
+	return nil
+}
+
+type commitHooksKey struct{}
+
+// commitHooks are run after the transaction commits. Retried attempts
+// get their own hooks, so that hooks of a failed attempt are dropped.
+type commitHooks struct {
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction of ctx is committed, or right
+// away outside of a transaction. fn is not run if the transaction fails.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+
+	fn()
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestTransactorDB_WithinTx(t *testing.T) {
+	errSerialization := &pq.Error{Code: serializationFailure}
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE (.+)", roomsTable)
+
+	tests := []struct {
+		name      string
+		retries   int
+		mock      func(mock sqlmock.Sqlmock)
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:    "Ok",
+			retries: 2,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "Rollback",
+			retries: 2,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantCalls: 1,
+			wantErr:   sql.ErrConnDone,
+		},
+		{
+			name:    "Retry",
+			retries: 2,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnError(errSerialization)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantCalls: 2,
+		},
+		{
+			name:    "Retries Exhausted",
+			retries: 1,
+			mock: func(mock sqlmock.Sqlmock) {
+				for i := 0; i < 2; i++ {
+					mock.ExpectBegin()
+					mock.ExpectExec(deleteQuery).WithArgs(1).WillReturnError(errSerialization)
+					mock.ExpectRollback()
+				}
+			},
+			wantCalls: 2,
+			wantErr:   errSerialization,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.Newx()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			test.mock(mock)
+			transactor := NewTransactorDB(db, TxConfig{Retries: test.retries})
+			rooms := NewRoomPostgres(db)
+
+			calls := 0
+			err = transactor.WithinTx(context.Background(), func(ctx context.Context) error {
+				calls++
+				// nested transactions join the outer one
+				return transactor.WithinTx(ctx, func(ctx context.Context) error {
+					return rooms.Delete(ctx, 1)
+				})
+			})
+			assert.True(t, errors.Is(err, test.wantErr), "got %v, want %v", err, test.wantErr)
+			assert.Equal(t, test.wantCalls, calls)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestParseIsolation(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    sql.IsolationLevel
+		wantErr bool
+	}{
+		{input: "", want: sql.LevelDefault},
+		{input: "read committed", want: sql.LevelReadCommitted},
+		{input: "Repeatable Read", want: sql.LevelRepeatableRead},
+		{input: "serializable", want: sql.LevelSerializable},
+		{input: "snapshot", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseIsolation(test.input)
+		if test.wantErr {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		}
+	}
+}
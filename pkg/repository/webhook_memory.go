@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+)
+
+type WebhookMemory struct {
+	store *memoryStore
+}
+
+func NewWebhookMemory(store *memoryStore) *WebhookMemory {
+	return &WebhookMemory{store: store}
+}
+
+func (r *WebhookMemory) Create(ctx context.Context, webhook *model.Webhook) (int, error) {
+	defer r.store.lock(ctx)()
+
+	r.store.webhookSeq++
+	created := copyWebhook(webhook)
+	created.Id = r.store.webhookSeq
+	r.store.webhooks[created.Id] = created
+
+	return created.Id, nil
+}
+
+func (r *WebhookMemory) Update(ctx context.Context, webhook *model.Webhook) error {
+	defer r.store.lock(ctx)()
+
+	if found, ok := r.store.webhooks[webhook.Id]; ok {
+		updated := copyWebhook(webhook)
+		updated.CreatedAt = found.CreatedAt
+		r.store.webhooks[webhook.Id] = updated
+	}
+
+	return nil
+}
+
+// Delete removes the webhook together with its deliveries, like the
+// ON DELETE CASCADE of the webhook_deliveries table.
+func (r *WebhookMemory) Delete(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.webhooks, id)
+	for deliveryId, delivery := range r.store.deliveries {
+		if delivery.WebhookId == id {
+			delete(r.store.deliveries, deliveryId)
+		}
+	}
+
+	return nil
+}
+
+func (r *WebhookMemory) GetAll(ctx context.Context) ([]*model.Webhook, error) {
+	defer r.store.rlock(ctx)()
+
+	var webhooks []*model.Webhook
+	for _, webhook := range r.store.webhooks {
+		webhooks = append(webhooks, copyWebhook(webhook))
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Id < webhooks[j].Id })
+
+	return webhooks, nil
+}
+
+func (r *WebhookMemory) GetById(ctx context.Context, id int) (*model.Webhook, error) {
+	defer r.store.rlock(ctx)()
+
+	webhook, ok := r.store.webhooks[id]
+	if !ok {
+		return &model.Webhook{}, sql.ErrNoRows
+	}
+
+	return copyWebhook(webhook), nil
+}
+
+func (r *WebhookMemory) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.webhooks[delivery.WebhookId]; !ok {
+		return 0, errForeignKey(webhooksTable, delivery.WebhookId)
+	}
+
+	r.store.deliverySeq++
+	created := &model.WebhookDelivery{
+		Id:            r.store.deliverySeq,
+		WebhookId:     delivery.WebhookId,
+		EventId:       delivery.EventId,
+		EventType:     delivery.EventType,
+		Payload:       append([]byte(nil), delivery.Payload...),
+		Status:        delivery.Status,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     time.Now(),
+	}
+	r.store.deliveries[created.Id] = created
+
+	return created.Id, nil
+}
+
+func (r *WebhookMemory) GetDeliveryById(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	defer r.store.rlock(ctx)()
+
+	delivery, ok := r.store.deliveries[id]
+	if !ok {
+		return &model.WebhookDelivery{}, sql.ErrNoRows
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+func (r *WebhookMemory) GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error) {
+	defer r.store.rlock(ctx)()
+
+	return r.store.deliveriesWhere(func(delivery *model.WebhookDelivery) bool {
+		return delivery.WebhookId == webhookId
+	}), nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due and
+// postpones them by lease.
+func (r *WebhookMemory) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.WebhookDelivery, error) {
+	defer r.store.lock(ctx)()
+
+	deliveries := r.store.deliveriesWhere(func(delivery *model.WebhookDelivery) bool {
+		return delivery.Status == model.DeliveryPending && !delivery.NextAttemptAt.After(now)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	for _, delivery := range deliveries {
+		delivery.NextAttemptAt = now.Add(lease)
+		r.store.deliveries[delivery.Id].NextAttemptAt = delivery.NextAttemptAt
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookMemory) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	defer r.store.lock(ctx)()
+
+	found, ok := r.store.deliveries[delivery.Id]
+	if !ok {
+		return nil
+	}
+	updated := copyDelivery(delivery)
+	updated.WebhookId = found.WebhookId
+	updated.EventId = found.EventId
+	updated.EventType = found.EventType
+	updated.Payload = found.Payload
+	updated.CreatedAt = found.CreatedAt
+	r.store.deliveries[delivery.Id] = updated
+
+	return nil
+}
+
+// deliveriesWhere returns copies of the matching deliveries ordered by id.
+// The caller must hold the lock.
+func (s *memoryStore) deliveriesWhere(match func(*model.WebhookDelivery) bool) []*model.WebhookDelivery {
+	var deliveries []*model.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if match(delivery) {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id < deliveries[j].Id })
+
+	return deliveries
+}
+
+func copyWebhook(webhook *model.Webhook) *model.Webhook {
+	copied := *webhook
+	copied.Events = append(model.EventList(nil), webhook.Events...)
+
+	return &copied
+}
+
+func copyDelivery(delivery *model.WebhookDelivery) *model.WebhookDelivery {
+	copied := *delivery
+	copied.Payload = append([]byte(nil), delivery.Payload...)
+	copied.LastStatusCode = copyInt(delivery.LastStatusCode)
+	copied.LastError = copyString(delivery.LastError)
+	copied.DeliveredAt = copyTime(delivery.DeliveredAt)
+
+	return &copied
+}
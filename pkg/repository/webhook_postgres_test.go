@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/stretchr/testify/assert"
+	sqlmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestWebhookPostgres_Create(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewWebhookPostgres(db)
+
+	type args struct {
+		webhook *model.Webhook
+	}
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				webhook: &model.Webhook{
+					URL:    "http://example.com/hook",
+					Secret: "secret",
+					Events: model.EventList{model.EventBookingCreated, model.EventRoomCreated},
+					Active: true,
+				},
+			},
+			mock: func(args args) {
+				webhook := args.webhook
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", webhooksTable)).
+					WithArgs(webhook.URL, webhook.Secret, "booking.created,room.created", webhook.Active,
+						webhook.CreatedAt).
+					WillReturnRows(rows)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				webhook: &model.Webhook{URL: "http://example.com/hook", Events: model.EventList{"room.created"}},
+			},
+			mock: func(args args) {
+				mock.ExpectQuery(fmt.Sprintf("INSERT INTO %s", webhooksTable)).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.input)
+
+			got, err := r.Create(context.Background(), test.input.webhook)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhookPostgres_ClaimDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.Newx()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewWebhookPostgres(db)
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts",
+		"next_attempt_at", "last_error", "last_status_code", "created_at", "delivered_at"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, 1, "event", "room.created", []byte(`{}`), "pending", 0, now.Add(time.Minute), nil, nil, now, nil).
+					AddRow(1, 1, "event", "room.created", []byte(`{}`), "pending", 1, now.Add(time.Minute), "timeout", nil, now, nil)
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s SET next_attempt_at=(.+) FOR UPDATE SKIP LOCKED(.+)", deliveriesTable)).
+					WithArgs(now, now.Add(time.Minute), model.DeliveryPending, 10).
+					WillReturnRows(rows)
+			},
+			want: []int{1, 2},
+		},
+		{
+			name: "DB Error",
+			mock: func() {
+				mock.ExpectQuery(fmt.Sprintf("UPDATE %s", deliveriesTable)).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.ClaimDeliveries(context.Background(), now, time.Minute, 10)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, deliveryIds(got))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookSQLite struct {
+	db *sqlx.DB
+}
+
+func NewWebhookSQLite(db *sqlx.DB) *WebhookSQLite {
+	return &WebhookSQLite{db: db}
+}
+
+func (r *WebhookSQLite) Create(ctx context.Context, webhook *model.Webhook) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (url, secret, events, active, created_at) VALUES (?1, ?2, ?3, ?4, ?5) RETURNING id`,
+		webhooksTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query,
+		webhook.URL, webhook.Secret, webhook.Events, webhook.Active, webhook.CreatedAt.UTC())
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *WebhookSQLite) Update(ctx context.Context, webhook *model.Webhook) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET url=?2, secret=?3, events=?4, active=?5 WHERE id=?1`,
+		webhooksTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		webhook.Id, webhook.URL, webhook.Secret, webhook.Events, webhook.Active)
+
+	return err
+}
+
+func (r *WebhookSQLite) Delete(ctx context.Context, id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=?1", webhooksTable)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+
+	return err
+}
+
+func (r *WebhookSQLite) GetAll(ctx context.Context) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY id", webhooksTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &webhooks, query)
+
+	return webhooks, err
+}
+
+func (r *WebhookSQLite) GetById(ctx context.Context, id int) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?1", webhooksTable)
+	err := conn(ctx, r.db).GetContext(ctx, webhook, query, id)
+
+	return webhook, err
+}
+
+func (r *WebhookSQLite) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7) RETURNING id`,
+		deliveriesTable)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, delivery.WebhookId, delivery.EventId,
+		delivery.EventType, []byte(delivery.Payload), delivery.Status, delivery.NextAttemptAt.UTC(),
+		time.Now().UTC())
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *WebhookSQLite) GetDeliveryById(ctx context.Context, id int) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id=?1", deliveriesTable)
+	err := conn(ctx, r.db).GetContext(ctx, delivery, query, id)
+
+	return delivery, err
+}
+
+func (r *WebhookSQLite) GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	query := fmt.Sprintf("SELECT * FROM %s WHERE webhook_id=?1 ORDER BY id", deliveriesTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &deliveries, query, webhookId)
+
+	return deliveries, err
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due and
+// postpones them by lease. The update takes the write lock, so workers
+// cannot claim the same deliveries.
+func (r *WebhookSQLite) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	query := fmt.Sprintf(
+		`UPDATE %[1]s SET next_attempt_at=?2 WHERE id IN (SELECT id FROM %[1]s
+		WHERE status=?3 AND next_attempt_at <= ?1 ORDER BY id LIMIT ?4)
+		RETURNING *`,
+		deliveriesTable)
+	err := conn(ctx, r.db).SelectContext(ctx, &deliveries, query,
+		now.UTC(), now.Add(lease).UTC(), model.DeliveryPending, limit)
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id < deliveries[j].Id })
+
+	return deliveries, err
+}
+
+func (r *WebhookSQLite) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	query := fmt.Sprintf(
+		`UPDATE %s SET status=?2, attempts=?3, next_attempt_at=?4, last_error=?5,
+		last_status_code=?6, delivered_at=?7 WHERE id=?1`,
+		deliveriesTable)
+	var deliveredAt *time.Time
+	if delivery.DeliveredAt != nil {
+		utc := delivery.DeliveredAt.UTC()
+		deliveredAt = &utc
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, delivery.Id, delivery.Status, delivery.Attempts,
+		delivery.NextAttemptAt.UTC(), delivery.LastError, delivery.LastStatusCode, deliveredAt)
+
+	return err
+}
@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeyKey is the metadata key of the API key, the gRPC counterpart of
+// the X-API-Key header. A bearer authorization entry is accepted as well.
+const (
+	apiKeyKey        = "x-api-key"
+	authorizationKey = "authorization"
+)
+
+// methodRoles is the least role the API key needs for each method, the
+// same as for the matching REST route. Methods missing here need admin.
+var methodRoles = map[string]string{
+	pb.RoomService_CreateRoom_FullMethodName:             model.RoleAdmin,
+	pb.RoomService_DeleteRoom_FullMethodName:             model.RoleAdmin,
+	pb.RoomService_ListRooms_FullMethodName:              model.RoleReadOnly,
+	pb.RoomService_GetRoom_FullMethodName:                model.RoleReadOnly,
+	pb.BookingService_CreateBooking_FullMethodName:       model.RoleFrontDesk,
+	pb.BookingService_DeleteBooking_FullMethodName:       model.RoleFrontDesk,
+	pb.BookingService_ListBookings_FullMethodName:        model.RoleReadOnly,
+	pb.BookingService_GetBooking_FullMethodName:          model.RoleReadOnly,
+	pb.BookingService_ListDeletedBookings_FullMethodName: model.RoleReadOnly,
+	pb.BookingService_RestoreBooking_FullMethodName:      model.RoleFrontDesk,
+}
+
+// authInterceptor turns away calls without an API key allowed to call
+// the method.
+func authInterceptor(keys service.APIKey) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		key := requestKey(ctx)
+		if key == "" {
+			return nil, ErrUnauthorized
+		}
+
+		found, err := keys.Authenticate(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		role, ok := methodRoles[info.FullMethod]
+		if !ok {
+			role = model.RoleAdmin
+		}
+		if !found.Allows(role) {
+			return nil, ErrForbidden
+		}
+
+		return handler(context.WithValue(ctx, apiKeyCtxKey{}, found), req)
+	}
+}
+
+type apiKeyCtxKey struct{}
+
+// requestAPIKey returns the API key the call was authenticated with,
+// or nil.
+func requestAPIKey(ctx context.Context) *model.APIKey {
+	key, _ := ctx.Value(apiKeyCtxKey{}).(*model.APIKey)
+	return key
+}
+
+func requestKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if keys := md.Get(apiKeyKey); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	if values := md.Get(authorizationKey); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		mockBehavior func(r *mock_service.MockRoom)
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			key:  testKey,
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "No Key",
+			mockBehavior: func(r *mock_service.MockRoom) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Wrong Key",
+			key:          "est_wrong",
+			mockBehavior: func(r *mock_service.MockRoom) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "Forbidden",
+			key:          readOnlyKey,
+			mockBehavior: func(r *mock_service.MockRoom) {},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room)
+
+			client := pb.NewRoomServiceClient(dialWithKey(t, &service.Service{Room: room}, test.key))
+			_, err := client.DeleteRoom(context.Background(), &pb.DeleteRoomRequest{RoomId: 1})
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestAuthInterceptor_bearer(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	room := mock_service.NewMockRoom(c)
+	room.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+
+	client := pb.NewRoomServiceClient(dialWithKey(t, &service.Service{Room: room}, ""))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer "+testKey)
+	_, err := client.DeleteRoom(ctx, &pb.DeleteRoomRequest{RoomId: 1})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	"github.com/architectv/estate-task/pkg/validation"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type bookingServer struct {
+	pb.UnimplementedBookingServiceServer
+	services *service.Service
+}
+
+func (s *bookingServer) CreateBooking(ctx context.Context, req *pb.CreateBookingRequest) (*pb.CreateBookingResponse, error) {
+	booking, err := fromCreateBookingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.services.Booking.Create(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateBookingResponse{BookingId: int64(id)}, nil
+}
+
+func (s *bookingServer) DeleteBooking(ctx context.Context, req *pb.DeleteBookingRequest) (*emptypb.Empty, error) {
+	// the booking is marked with the name of the API key that cancels it,
+	// deleted_by of the request is ignored
+	if err := s.services.Booking.Delete(ctx, int(req.BookingId), requestAPIKey(ctx).Name); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *bookingServer) ListBookings(ctx context.Context, req *pb.ListBookingsRequest) (*pb.ListBookingsResponse, error) {
+	bookings, err := s.services.Booking.GetByRoomId(ctx, int(req.RoomId))
+	if err != nil {
+		return nil, err
+	}
+
+	return toListBookingsResponse(bookings), nil
+}
+
+func (s *bookingServer) GetBooking(ctx context.Context, req *pb.GetBookingRequest) (*pb.Booking, error) {
+	booking, err := s.services.Booking.GetById(ctx, int(req.BookingId))
+	if err != nil {
+		return nil, err
+	}
+
+	return toBooking(booking), nil
+}
+
+func (s *bookingServer) ListDeletedBookings(ctx context.Context,
+	req *pb.ListDeletedBookingsRequest) (*pb.ListBookingsResponse, error) {
+	bookings, err := s.services.Booking.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toListBookingsResponse(bookings), nil
+}
+
+func (s *bookingServer) RestoreBooking(ctx context.Context, req *pb.RestoreBookingRequest) (*emptypb.Empty, error) {
+	if err := s.services.Booking.Restore(ctx, int(req.BookingId)); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// fromCreateBookingRequest parses the request dates, reporting every
+// malformed one like the REST API does.
+func fromCreateBookingRequest(req *pb.CreateBookingRequest) (*model.Booking, error) {
+	booking := &model.Booking{RoomId: int(req.RoomId)}
+	if req.GuestId != nil {
+		guestId := int(*req.GuestId)
+		booking.GuestId = &guestId
+	}
+
+	v := validation.New()
+	booking.DateStart = parseDate(v, "date_start", req.DateStart)
+	booking.DateEnd = parseDate(v, "date_end", req.DateEnd)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func parseDate(v *validation.Validator, field, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse(model.DateFormat, value)
+	v.Check(err == nil, ErrWrongDateFormat.WithField(field))
+
+	return date
+}
+
+func toBooking(booking *model.Booking) *pb.Booking {
+	resp := &pb.Booking{
+		BookingId: int64(booking.Id),
+		RoomId:    int64(booking.RoomId),
+		DateStart: booking.DateStart.Format(model.DateFormat),
+		DateEnd:   booking.DateEnd.Format(model.DateFormat),
+	}
+	if booking.GuestId != nil {
+		guestId := int64(*booking.GuestId)
+		resp.GuestId = &guestId
+	}
+	if booking.DeletedAt != nil {
+		resp.DeletedAt = timestamppb.New(*booking.DeletedAt)
+	}
+	if booking.DeletedBy != nil {
+		resp.DeletedBy = *booking.DeletedBy
+	}
+
+	return resp
+}
+
+func toListBookingsResponse(bookings []*model.Booking) *pb.ListBookingsResponse {
+	resp := &pb.ListBookingsResponse{}
+	for _, booking := range bookings {
+		resp.Bookings = append(resp.Bookings, toBooking(booking))
+	}
+
+	return resp
+}
@@ -0,0 +1,319 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestBookingServer_CreateBooking(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	guestId := 3
+	guestId64 := int64(guestId)
+
+	tests := []struct {
+		name         string
+		input        *pb.CreateBookingRequest
+		mockBehavior mockBehavior
+		expected     *pb.CreateBookingResponse
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			input: &pb.CreateBookingRequest{
+				RoomId:    1,
+				GuestId:   &guestId64,
+				DateStart: "2021-12-30",
+				DateEnd:   "2022-01-02",
+			},
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Create(gomock.Any(), &model.Booking{
+					RoomId:    1,
+					GuestId:   &guestId,
+					DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+				}).Return(1, nil)
+			},
+			expected:     &pb.CreateBookingResponse{BookingId: 1},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Date Format",
+			input: &pb.CreateBookingRequest{
+				RoomId:    1,
+				DateStart: "30.12.2021",
+				DateEnd:   "2022-01-02",
+			},
+			mockBehavior: func(r *mock_service.MockBooking) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Wrong Room Id",
+			input: &pb.CreateBookingRequest{
+				RoomId:    1,
+				DateStart: "2021-12-30",
+				DateEnd:   "2022-01-02",
+			},
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Create(gomock.Any(), gomock.Any()).Return(0, ErrWrongRoomId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(booking)
+
+			client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+			resp, err := client.CreateBooking(context.Background(), test.input)
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
+
+func TestBookingServer_DeleteBooking(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Delete(gomock.Any(), 1, "admin").Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Booking Id",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Delete(gomock.Any(), 1, "admin").Return(ErrWrongBookingId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(booking)
+
+			client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+			// the operator given by the client is replaced by the name of its API key
+			_, err := client.DeleteBooking(context.Background(),
+				&pb.DeleteBookingRequest{BookingId: 1, DeletedBy: "operator"})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestBookingServer_ListBookings(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	guestId := 3
+	guestId64 := int64(guestId)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     *pb.ListBookingsResponse
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().GetByRoomId(gomock.Any(), 1).Return([]*model.Booking{
+					{
+						Id:        1,
+						RoomId:    1,
+						GuestId:   &guestId,
+						DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+						DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expected: &pb.ListBookingsResponse{Bookings: []*pb.Booking{
+				{BookingId: 1, RoomId: 1, GuestId: &guestId64, DateStart: "2021-12-30", DateEnd: "2022-01-02"},
+			}},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Room Id",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().GetByRoomId(gomock.Any(), 1).Return(nil, ErrWrongRoomId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(booking)
+
+			client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+			resp, err := client.ListBookings(context.Background(), &pb.ListBookingsRequest{RoomId: 1})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
+
+func TestBookingServer_GetBooking(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     *pb.Booking
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(&model.Booking{
+					Id:        1,
+					RoomId:    2,
+					DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			expected:     &pb.Booking{BookingId: 1, RoomId: 2, DateStart: "2021-12-30", DateEnd: "2022-01-02"},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Booking Id",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(nil, ErrWrongBookingId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(booking)
+
+			client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+			resp, err := client.GetBooking(context.Background(), &pb.GetBookingRequest{BookingId: 1})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
+
+func TestBookingServer_ListDeletedBookings(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	deletedAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	deletedBy := "operator"
+
+	booking := mock_service.NewMockBooking(c)
+	booking.EXPECT().GetDeleted(gomock.Any()).Return([]*model.Booking{
+		{
+			Id:        1,
+			RoomId:    2,
+			DateStart: time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC),
+			DateEnd:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			DeletedAt: &deletedAt,
+			DeletedBy: &deletedBy,
+		},
+	}, nil)
+
+	client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+	resp, err := client.ListDeletedBookings(context.Background(), &pb.ListDeletedBookingsRequest{})
+
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(&pb.ListBookingsResponse{Bookings: []*pb.Booking{
+		{
+			BookingId: 1,
+			RoomId:    2,
+			DateStart: "2021-12-30",
+			DateEnd:   "2022-01-02",
+			DeletedAt: timestamppb.New(deletedAt),
+			DeletedBy: deletedBy,
+		},
+	}}, resp))
+}
+
+func TestBookingServer_RestoreBooking(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockBooking)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Restore(gomock.Any(), 1).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Booking Conflict",
+			mockBehavior: func(r *mock_service.MockBooking) {
+				r.EXPECT().Restore(gomock.Any(), 1).Return(ErrBookingConflict)
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			booking := mock_service.NewMockBooking(c)
+			test.mockBehavior(booking)
+
+			client := pb.NewBookingServiceClient(dial(t, &service.Service{Booking: booking}))
+			_, err := client.RestoreBooking(context.Background(), &pb.RestoreBookingRequest{BookingId: 1})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
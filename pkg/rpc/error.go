@@ -0,0 +1,106 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "estate-task"
+
+// notFoundErrors refer to missing entities. They are bad requests in the
+// REST API but map naturally to NotFound in gRPC.
+var notFoundErrors = []*Error{ErrWrongRoomId, ErrWrongBookingId, ErrWrongGuestId}
+
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// errorInterceptor converts errors returned by the servers to gRPC statuses.
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		st := toStatus(ContextError(ctx, err))
+		// client errors are expected in normal operation
+		switch st.Code() {
+		case codes.Internal, codes.Unknown, codes.DeadlineExceeded:
+			logging.FromContext(ctx).Error(err.Error())
+		default:
+			logging.FromContext(ctx).Warn(err.Error())
+		}
+		return nil, st.Err()
+	}
+
+	return resp, nil
+}
+
+// toStatus maps a domain error to a gRPC status carrying the error code
+// in ErrorInfo and field errors in BadRequest details. An expired context
+// is reported as DeadlineExceeded and anything else as an internal error.
+func toStatus(err error) *status.Status {
+	if _, ok := status.FromError(err); ok {
+		return status.Convert(err)
+	}
+
+	var fieldErrors []*Error
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		fieldErrors = validationErr.Errors
+	}
+
+	var domainErr *Error
+	if errors.Is(err, context.DeadlineExceeded) {
+		domainErr = ErrTimeout
+	} else if !errors.As(err, &domainErr) {
+		domainErr = ErrInternalService
+	}
+	if len(fieldErrors) == 0 && domainErr.Field != "" {
+		fieldErrors = []*Error{domainErr}
+	}
+
+	st := status.New(statusCode(domainErr), domainErr.Message)
+	details := []*errdetails.BadRequest_FieldViolation{}
+	for _, fieldErr := range fieldErrors {
+		details = append(details, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Message,
+		})
+	}
+
+	info := &errdetails.ErrorInfo{Reason: domainErr.Code, Domain: errorDomain}
+	if len(details) == 0 {
+		st, _ = st.WithDetails(info)
+	} else {
+		st, _ = st.WithDetails(info, &errdetails.BadRequest{FieldViolations: details})
+	}
+
+	return st
+}
+
+func statusCode(err *Error) codes.Code {
+	for _, notFound := range notFoundErrors {
+		if errors.Is(err, notFound) {
+			return codes.NotFound
+		}
+	}
+
+	if code, ok := statusCodes[err.Status]; ok {
+		return code
+	}
+	return codes.Unknown
+}
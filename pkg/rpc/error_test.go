@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              error
+		expectedCode       codes.Code
+		expectedMessage    string
+		expectedReason     string
+		expectedViolations []string
+	}{
+		{
+			name:            "Not Found",
+			input:           ErrWrongBookingId,
+			expectedCode:    codes.NotFound,
+			expectedMessage: ErrWrongBookingId.Message,
+			expectedReason:  ErrWrongBookingId.Code,
+			expectedViolations: []string{
+				"booking_id",
+			},
+		},
+		{
+			name:            "Conflict",
+			input:           ErrBookingConflict,
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: ErrBookingConflict.Message,
+			expectedReason:  ErrBookingConflict.Code,
+		},
+		{
+			name: "Validation",
+			input: &ValidationError{Errors: []*Error{
+				ErrRequiredField.WithField("room_id"),
+				ErrWrongDates,
+			}},
+			expectedCode:       codes.InvalidArgument,
+			expectedMessage:    ErrValidation.Message,
+			expectedReason:     ErrValidation.Code,
+			expectedViolations: []string{"room_id", "date_end"},
+		},
+		{
+			name:            "Timeout",
+			input:           fmt.Errorf("get room: %w", context.DeadlineExceeded),
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: ErrTimeout.Message,
+			expectedReason:  ErrTimeout.Code,
+		},
+		{
+			name:            "Unknown Error",
+			input:           errors.New("sql: connection refused"),
+			expectedCode:    codes.Internal,
+			expectedMessage: ErrInternalService.Message,
+			expectedReason:  ErrInternalService.Code,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st := toStatus(test.input)
+
+			assert.Equal(t, test.expectedCode, st.Code())
+			assert.Equal(t, test.expectedMessage, st.Message())
+
+			var reason string
+			var violations []string
+			for _, detail := range st.Details() {
+				switch detail := detail.(type) {
+				case *errdetails.ErrorInfo:
+					reason = detail.Reason
+				case *errdetails.BadRequest:
+					for _, violation := range detail.FieldViolations {
+						violations = append(violations, violation.Field)
+					}
+				}
+			}
+			assert.Equal(t, test.expectedReason, reason)
+			assert.Equal(t, test.expectedViolations, violations)
+		})
+	}
+}
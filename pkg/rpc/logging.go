@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata key of the request id, the gRPC
+// counterpart of the X-Request-ID header.
+const requestIDKey = "x-request-id"
+
+// requestIDInterceptor gives every call an id, taken from the metadata
+// when the client sends one. The id is returned in the response header
+// and logged with everything logged while serving the call.
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	if !logging.ValidRequestID(id) {
+		id = uuid.NewString()
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id)); err != nil {
+		return nil, err
+	}
+
+	return handler(logging.WithRequestID(ctx, id), req)
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDInterceptor(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var serverID string
+	room := mock_service.NewMockRoom(c)
+	room.EXPECT().Delete(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, id int) error {
+		serverID = logging.RequestID(ctx)
+		return nil
+	}).Times(2)
+
+	client := pb.NewRoomServiceClient(dial(t, &service.Service{Room: room}))
+
+	t.Run("Given", func(t *testing.T) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDKey, "abc-123")
+		_, err := client.DeleteRoom(ctx, &pb.DeleteRoomRequest{RoomId: 1}, grpc.Header(&header))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "abc-123", serverID)
+		assert.Equal(t, []string{"abc-123"}, header.Get(requestIDKey))
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		var header metadata.MD
+		_, err := client.DeleteRoom(context.Background(), &pb.DeleteRoomRequest{RoomId: 1}, grpc.Header(&header))
+
+		assert.Nil(t, err)
+		assert.Len(t, serverID, 36)
+		assert.Equal(t, []string{serverID}, header.Get(requestIDKey))
+	})
+}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type roomServer struct {
+	pb.UnimplementedRoomServiceServer
+	services *service.Service
+}
+
+func (s *roomServer) CreateRoom(ctx context.Context, req *pb.CreateRoomRequest) (*pb.CreateRoomResponse, error) {
+	id, err := s.services.Room.Create(ctx, &model.Room{
+		Description: req.Description,
+		Price:       int(req.Price),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateRoomResponse{RoomId: int64(id)}, nil
+}
+
+func (s *roomServer) DeleteRoom(ctx context.Context, req *pb.DeleteRoomRequest) (*emptypb.Empty, error) {
+	if err := s.services.Room.Delete(ctx, int(req.RoomId)); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *roomServer) ListRooms(ctx context.Context, req *pb.ListRoomsRequest) (*pb.ListRoomsResponse, error) {
+	rooms, err := s.services.Room.GetAll(ctx, req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListRoomsResponse{}
+	for _, room := range rooms {
+		resp.Rooms = append(resp.Rooms, toRoom(room))
+	}
+
+	return resp, nil
+}
+
+func (s *roomServer) GetRoom(ctx context.Context, req *pb.GetRoomRequest) (*pb.Room, error) {
+	room, err := s.services.Room.GetById(ctx, int(req.RoomId))
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoom(room), nil
+}
+
+func toRoom(room *model.Room) *pb.Room {
+	return &pb.Room{
+		RoomId:      int64(room.Id),
+		Description: room.Description,
+		Price:       int64(room.Price),
+	}
+}
@@ -0,0 +1,214 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRoomServer_CreateRoom(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom)
+
+	tests := []struct {
+		name         string
+		input        *pb.CreateRoomRequest
+		mockBehavior mockBehavior
+		expected     *pb.CreateRoomResponse
+		expectedCode codes.Code
+	}{
+		{
+			name:  "Ok",
+			input: &pb.CreateRoomRequest{Description: "test description", Price: 1000},
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Create(gomock.Any(), &model.Room{Description: "test description", Price: 1000}).Return(1, nil)
+			},
+			expected:     &pb.CreateRoomResponse{RoomId: 1},
+			expectedCode: codes.OK,
+		},
+		{
+			name:  "Empty Description",
+			input: &pb.CreateRoomRequest{Price: 1000},
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Create(gomock.Any(), &model.Room{Price: 1000}).
+					Return(0, &ValidationError{Errors: []*Error{ErrEmptyDescription}})
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:  "Service Error",
+			input: &pb.CreateRoomRequest{Description: "test description", Price: 1000},
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Create(gomock.Any(), &model.Room{Description: "test description", Price: 1000}).
+					Return(0, ErrInternalService)
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room)
+
+			client := pb.NewRoomServiceClient(dial(t, &service.Service{Room: room}))
+			resp, err := client.CreateRoom(context.Background(), test.input)
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
+
+func TestRoomServer_DeleteRoom(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Room Id",
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().Delete(gomock.Any(), 1).Return(ErrWrongRoomId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room)
+
+			client := pb.NewRoomServiceClient(dial(t, &service.Service{Room: room}))
+			_, err := client.DeleteRoom(context.Background(), &pb.DeleteRoomRequest{RoomId: 1})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestRoomServer_ListRooms(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom, sort string)
+
+	tests := []struct {
+		name         string
+		inputSort    string
+		mockBehavior mockBehavior
+		expected     *pb.ListRoomsResponse
+		expectedCode codes.Code
+	}{
+		{
+			name:      "Ok",
+			inputSort: "-price",
+			mockBehavior: func(r *mock_service.MockRoom, sort string) {
+				r.EXPECT().GetAll(gomock.Any(), sort).Return([]*model.Room{
+					{Id: 2, Description: "description2", Price: 5000},
+					{Id: 1, Description: "description1", Price: 1000},
+				}, nil)
+			},
+			expected: &pb.ListRoomsResponse{Rooms: []*pb.Room{
+				{RoomId: 2, Description: "description2", Price: 5000},
+				{RoomId: 1, Description: "description1", Price: 1000},
+			}},
+			expectedCode: codes.OK,
+		},
+		{
+			name:      "Wrong Sort Field",
+			inputSort: "wrong",
+			mockBehavior: func(r *mock_service.MockRoom, sort string) {
+				r.EXPECT().GetAll(gomock.Any(), sort).Return(nil, ErrWrongSortField)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room, test.inputSort)
+
+			client := pb.NewRoomServiceClient(dial(t, &service.Service{Room: room}))
+			resp, err := client.ListRooms(context.Background(), &pb.ListRoomsRequest{Sort: test.inputSort})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
+
+func TestRoomServer_GetRoom(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockRoom)
+
+	tests := []struct {
+		name         string
+		mockBehavior mockBehavior
+		expected     *pb.Room
+		expectedCode codes.Code
+	}{
+		{
+			name: "Ok",
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(&model.Room{Id: 1, Description: "description", Price: 1000}, nil)
+			},
+			expected:     &pb.Room{RoomId: 1, Description: "description", Price: 1000},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "Wrong Room Id",
+			mockBehavior: func(r *mock_service.MockRoom) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(nil, ErrWrongRoomId)
+			},
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			room := mock_service.NewMockRoom(c)
+			test.mockBehavior(room)
+
+			client := pb.NewRoomServiceClient(dial(t, &service.Service{Room: room}))
+			resp, err := client.GetRoom(context.Background(), &pb.GetRoomRequest{RoomId: 1})
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			if test.expected != nil {
+				assert.True(t, proto.Equal(test.expected, resp))
+			}
+		})
+	}
+}
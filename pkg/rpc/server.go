@@ -0,0 +1,18 @@
+package rpc
+
+import (
+	"github.com/architectv/estate-task/pkg/rpc/pb"
+	"github.com/architectv/estate-task/pkg/service"
+	"google.golang.org/grpc"
+)
+
+// NewServer returns a gRPC server exposing the room and booking services
+// to callers with an API key.
+func NewServer(services *service.Service) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(requestIDInterceptor, errorInterceptor,
+		authInterceptor(services.APIKey)))
+	pb.RegisterRoomServiceServer(server, &roomServer{services: services})
+	pb.RegisterBookingServiceServer(server, &bookingServer{services: services})
+
+	return server
+}
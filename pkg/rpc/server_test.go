@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/service"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// API keys accepted by newAPIKeys.
+const (
+	testKey     = "est_admin"
+	readOnlyKey = "est_read_only"
+)
+
+// newAPIKeys returns an API key service accepting testKey as an admin key
+// and readOnlyKey as a read-only one.
+func newAPIKeys(c *gomock.Controller) *mock_service.MockAPIKey {
+	keys := mock_service.NewMockAPIKey(c)
+	keys.EXPECT().Authenticate(gomock.Any(), testKey).AnyTimes().Return(
+		&model.APIKey{Id: 1, Name: "admin", Role: model.RoleAdmin}, nil)
+	keys.EXPECT().Authenticate(gomock.Any(), readOnlyKey).AnyTimes().Return(
+		&model.APIKey{Id: 2, Name: "auditor", Role: model.RoleReadOnly}, nil)
+	keys.EXPECT().Authenticate(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, ErrUnauthorized)
+
+	return keys
+}
+
+// dial starts a server over an in-memory connection and returns a client
+// connection to it calling with testKey.
+func dial(t *testing.T, services *service.Service) *grpc.ClientConn {
+	return dialWithKey(t, services, testKey)
+}
+
+// dialWithKey is dial calling with the given API key, none if it is empty.
+func dialWithKey(t *testing.T, services *service.Service, key string) *grpc.ClientConn {
+	if services.APIKey == nil {
+		services.APIKey = newAPIKeys(gomock.NewController(t))
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(services)
+	go server.Serve(listener)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{},
+			cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, apiKeyKey, key)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+	)
+	assert.Nil(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return conn
+}
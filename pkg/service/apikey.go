@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+// keyPrefix starts every API key so that leaked keys are easy to find,
+// keyPrefixLength is how much of a key is kept to tell keys apart.
+const (
+	keyPrefix       = "est_"
+	keyPrefixLength = 12
+)
+
+type APIKeyService struct {
+	repo repository.APIKey
+	tx   repository.Transactor
+}
+
+func NewAPIKeyService(repo repository.APIKey, tx repository.Transactor) *APIKeyService {
+	return &APIKeyService{repo: repo, tx: tx}
+}
+
+// Create generates a key with the name and role of key and returns it
+// together with the key itself, which is not shown again.
+func (s *APIKeyService) Create(ctx context.Context, key *model.APIKey) (*model.APIKey, error) {
+	if err := validation.Validate(key); err != nil {
+		return nil, err
+	}
+
+	return s.create(ctx, key.Name, key.Role)
+}
+
+func (s *APIKeyService) GetAll(ctx context.Context) ([]*model.APIKey, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// Revoke stops the key from being accepted. The last active admin key
+// cannot be revoked, as nobody could manage the keys afterwards.
+func (s *APIKeyService) Revoke(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		key, err := s.repo.GetById(ctx, id)
+		if err != nil {
+			return notFound(err, ErrWrongKeyId)
+		}
+		if key.RevokedAt != nil {
+			return nil
+		}
+
+		if key.Role == model.RoleAdmin {
+			admins, err := s.activeAdmins(ctx)
+			if err != nil {
+				return err
+			}
+			if admins <= 1 {
+				return ErrLastAdminKey
+			}
+		}
+
+		return s.repo.Revoke(ctx, id, time.Now().UTC())
+	})
+}
+
+// Authenticate returns the active key matching the given one.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*model.APIKey, error) {
+	found, err := s.repo.GetByHash(ctx, hashKey(key))
+	if err != nil {
+		return nil, notFound(err, ErrUnauthorized)
+	}
+	if found.RevokedAt != nil {
+		return nil, ErrUnauthorized
+	}
+
+	return found, nil
+}
+
+// Bootstrap creates the first admin key. It fails once an active admin
+// key exists, further keys are created with that one.
+func (s *APIKeyService) Bootstrap(ctx context.Context, name string) (*model.APIKey, error) {
+	if err := validation.Validate(&model.APIKey{Name: name, Role: model.RoleAdmin}); err != nil {
+		return nil, err
+	}
+
+	var created *model.APIKey
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		admins, err := s.activeAdmins(ctx)
+		if err != nil {
+			return err
+		}
+		if admins > 0 {
+			return ErrAdminKeyExists
+		}
+
+		created, err = s.create(ctx, name, model.RoleAdmin)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *APIKeyService) create(ctx context.Context, name, role string) (*model.APIKey, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created := &model.APIKey{
+		Name:      name,
+		Role:      role,
+		Prefix:    key[:keyPrefixLength],
+		Hash:      hashKey(key),
+		CreatedAt: time.Now().UTC(),
+	}
+	id, err := s.repo.Create(ctx, created)
+	if err != nil {
+		return nil, err
+	}
+	created.Id = id
+	created.Key = key
+
+	return created, nil
+}
+
+func (s *APIKeyService) activeAdmins(ctx context.Context) (int, error) {
+	keys, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	admins := 0
+	for _, key := range keys {
+		if key.Role == model.RoleAdmin && key.RevokedAt == nil {
+			admins++
+		}
+	}
+
+	return admins, nil
+}
+
+func newKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("cannot generate API key: %w", err)
+	}
+
+	return keyPrefix + hex.EncodeToString(key), nil
+}
+
+// hashKey is what is stored in place of the key. The keys are random, so
+// a plain SHA-256 is as hard to reverse as a slow password hash and can be
+// looked up directly.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
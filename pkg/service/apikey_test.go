@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_Create(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockAPIKey(c)
+	var stored *model.APIKey
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key *model.APIKey) (int, error) {
+			copied := *key
+			stored = &copied
+			return 1, nil
+		})
+	s := NewAPIKeyService(repo, newTransactor(c))
+
+	got, err := s.Create(context.Background(), &model.APIKey{Name: "reception", Role: model.RoleFrontDesk})
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Id)
+	assert.True(t, strings.HasPrefix(got.Key, keyPrefix))
+	assert.Len(t, got.Key, len(keyPrefix)+64)
+	assert.Equal(t, got.Key[:keyPrefixLength], stored.Prefix)
+	assert.Equal(t, hashKey(got.Key), stored.Hash)
+	assert.Empty(t, stored.Key)
+
+	_, err = s.Create(context.Background(), &model.APIKey{Name: " ", Role: "owner"})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Errors, 2)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	revokedAt := time.Now()
+
+	type mockBehavior func(r *mock_repository.MockAPIKey)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		want    *model.APIKey
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetByHash(gomock.Any(), hashKey("key")).Return(
+					&model.APIKey{Id: 1, Role: model.RoleAdmin}, nil)
+			},
+			want: &model.APIKey{Id: 1, Role: model.RoleAdmin},
+		},
+		{
+			name: "Unknown",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetByHash(gomock.Any(), hashKey("key")).Return(&model.APIKey{}, sql.ErrNoRows)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "Revoked",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetByHash(gomock.Any(), hashKey("key")).Return(
+					&model.APIKey{Id: 1, Role: model.RoleAdmin, RevokedAt: &revokedAt}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockAPIKey(c)
+			test.mock(repo)
+			s := NewAPIKeyService(repo, newTransactor(c))
+
+			got, err := s.Authenticate(context.Background(), "key")
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	revokedAt := time.Now()
+	admin := &model.APIKey{Id: 1, Role: model.RoleAdmin}
+
+	type mockBehavior func(r *mock_repository.MockAPIKey)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(admin, nil)
+				r.EXPECT().GetAll(gomock.Any()).Return([]*model.APIKey{
+					admin, {Id: 2, Role: model.RoleAdmin},
+				}, nil)
+				r.EXPECT().Revoke(gomock.Any(), 1, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Already Revoked",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(
+					&model.APIKey{Id: 1, Role: model.RoleAdmin, RevokedAt: &revokedAt}, nil)
+			},
+		},
+		{
+			name: "Last Admin",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(admin, nil)
+				r.EXPECT().GetAll(gomock.Any()).Return([]*model.APIKey{
+					admin,
+					{Id: 2, Role: model.RoleAdmin, RevokedAt: &revokedAt},
+					{Id: 3, Role: model.RoleReadOnly},
+				}, nil)
+			},
+			wantErr: ErrLastAdminKey,
+		},
+		{
+			name: "Wrong Id",
+			mock: func(r *mock_repository.MockAPIKey) {
+				r.EXPECT().GetById(gomock.Any(), 1).Return(&model.APIKey{}, sql.ErrNoRows)
+			},
+			wantErr: ErrWrongKeyId,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockAPIKey(c)
+			test.mock(repo)
+			s := NewAPIKeyService(repo, newTransactor(c))
+
+			assert.Equal(t, test.wantErr, s.Revoke(context.Background(), 1))
+		})
+	}
+}
+
+func TestAPIKeyService_Bootstrap(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockAPIKey(c)
+	s := NewAPIKeyService(repo, newTransactor(c))
+
+	repo.EXPECT().GetAll(gomock.Any()).Return([]*model.APIKey{{Id: 1, Role: model.RoleFrontDesk}}, nil)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(2, nil)
+	got, err := s.Bootstrap(context.Background(), "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Id)
+	assert.Equal(t, model.RoleAdmin, got.Role)
+	assert.NotEmpty(t, got.Key)
+
+	repo.EXPECT().GetAll(gomock.Any()).Return([]*model.APIKey{{Id: 2, Role: model.RoleAdmin}}, nil)
+	_, err = s.Bootstrap(context.Background(), "admin")
+	assert.Equal(t, ErrAdminKeyExists, err)
+}
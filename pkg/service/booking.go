@@ -1,46 +1,218 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
 	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/metrics"
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/validation"
 )
 
 type BookingService struct {
-	repo     repository.Booking
-	roomRepo repository.Room
+	repo      repository.Booking
+	roomRepo  repository.Room
+	guestRepo repository.Guest
+	tx        repository.Transactor
+	events    Publisher
 }
 
-func NewBookingService(repo repository.Booking, roomRepo repository.Room) *BookingService {
-	return &BookingService{repo: repo, roomRepo: roomRepo}
+func NewBookingService(repo repository.Booking, roomRepo repository.Room,
+	guestRepo repository.Guest, tx repository.Transactor, events Publisher) *BookingService {
+	return &BookingService{repo: repo, roomRepo: roomRepo, guestRepo: guestRepo, tx: tx, events: events}
 }
 
-func (s *BookingService) Create(booking *model.Booking) (int, error) {
-	_, err := s.roomRepo.GetById(booking.RoomId)
+func (s *BookingService) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	if booking.Guest != nil {
+		trimGuest(booking.Guest)
+	}
+	if err := validation.Validate(booking); err != nil {
+		return 0, err
+	}
+
+	// the room is checked in the same transaction, so it cannot be
+	// deleted before the booking is created
+	var id int
+	guestId := booking.GuestId
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// a guest created by a failed attempt was rolled back
+		booking.GuestId = guestId
+
+		if _, err := s.roomRepo.GetById(ctx, booking.RoomId); err != nil {
+			return notFound(err, ErrWrongRoomId)
+		}
+
+		if err := s.resolveGuest(ctx, booking); err != nil {
+			return err
+		}
+
+		var err error
+		id, err = s.repo.Create(ctx, booking)
+		if err != nil {
+			return err
+		}
+
+		created := *booking
+		created.Id = id
+		created.Guest = nil
+
+		return s.events.Publish(ctx, model.EventBookingCreated, id, model.BookingWithRoom{Booking: &created})
+	})
 	if err != nil {
-		return 0, ErrWrongRoomId
+		return 0, err
+	}
+	metrics.BookingsCreated.Inc()
+
+	return id, nil
+}
+
+// resolveGuest links the booking to a guest profile. An inline guest is
+// linked to a returning guest when its email or phone matches exactly one
+// guest whose contacts do not contradict it, and created otherwise.
+func (s *BookingService) resolveGuest(ctx context.Context, booking *model.Booking) error {
+	if booking.GuestId != nil {
+		if _, err := s.guestRepo.GetById(ctx, *booking.GuestId); err != nil {
+			return notFound(err, ErrWrongGuestId)
+		}
+		return nil
 	}
-	if !booking.DateStart.Before(booking.DateEnd) {
-		return 0, ErrWrongDates
+	if booking.Guest == nil {
+		return nil
 	}
 
-	return s.repo.Create(booking)
+	if booking.Guest.Email != "" || booking.Guest.Phone != "" {
+		guests, err := s.guestRepo.FindByContact(ctx, booking.Guest.Email, booking.Guest.Phone)
+		if err != nil {
+			return err
+		}
+		if len(guests) == 1 && sameContacts(guests[0], booking.Guest) {
+			booking.GuestId = &guests[0].Id
+			return nil
+		}
+	}
+
+	id, err := s.guestRepo.Create(ctx, booking.Guest)
+	if err != nil {
+		return err
+	}
+	booking.GuestId = &id
+
+	return nil
+}
+
+// sameContacts reports whether the contacts given for a guest agree with
+// the stored ones. A contact missing on either side does not disagree.
+func sameContacts(stored, given *model.Guest) bool {
+	if given.Email != "" && stored.Email != "" && !strings.EqualFold(given.Email, stored.Email) {
+		return false
+	}
+
+	return given.Phone == "" || stored.Phone == "" || given.Phone == stored.Phone
 }
 
-func (s *BookingService) Delete(id int) error {
-	_, err := s.repo.GetById(id)
+func (s *BookingService) Delete(ctx context.Context, id int, deletedBy string) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		booking, err := s.repo.GetById(ctx, id)
+		if err != nil {
+			return notFound(err, ErrWrongBookingId)
+		}
+
+		if err := s.repo.Delete(ctx, id, deletedBy); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		booking.DeletedAt = &now
+		booking.DeletedBy = &deletedBy
+
+		return s.events.Publish(ctx, model.EventBookingCancelled, id, model.BookingWithRoom{Booking: booking})
+	})
 	if err != nil {
-		return ErrWrongBookingId
+		return err
 	}
+	metrics.BookingsCancelled.Inc()
 
-	return s.repo.Delete(id)
+	return nil
 }
 
-func (s *BookingService) GetByRoomId(roomId int) ([]*model.Booking, error) {
-	_, err := s.roomRepo.GetById(roomId)
+func (s *BookingService) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	_, err := s.roomRepo.GetById(ctx, roomId)
 	if err != nil {
-		return nil, ErrWrongRoomId
+		return nil, notFound(err, ErrWrongRoomId)
 	}
 
-	return s.repo.GetByRoomId(roomId)
+	return s.repo.GetByRoomId(ctx, roomId)
+}
+
+// GetByRoomIds returns bookings grouped by room. Rooms are expected to be
+// loaded already, so unknown ids just have no bookings.
+func (s *BookingService) GetByRoomIds(ctx context.Context, roomIds []int) (map[int][]*model.Booking, error) {
+	bookings, err := s.repo.GetByRoomIds(ctx, roomIds)
+	if err != nil {
+		return nil, err
+	}
+
+	byRoom := make(map[int][]*model.Booking, len(roomIds))
+	for _, booking := range bookings {
+		byRoom[booking.RoomId] = append(byRoom[booking.RoomId], booking)
+	}
+
+	return byRoom, nil
+}
+
+func (s *BookingService) GetById(ctx context.Context, id int) (*model.Booking, error) {
+	booking, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWrongBookingId)
+	}
+
+	return booking, nil
+}
+
+func (s *BookingService) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	return s.repo.GetDeleted(ctx)
+}
+
+func (s *BookingService) Restore(ctx context.Context, id int) error {
+	// the dates are checked in the same transaction as the restore, so
+	// they cannot be taken in between
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		booking, err := s.repo.GetDeletedById(ctx, id)
+		if err != nil {
+			return notFound(err, ErrWrongBookingId)
+		}
+
+		// the dates may have been taken while the booking was deleted
+		overlap, err := s.repo.HasOverlap(ctx, booking.RoomId, booking.DateStart, booking.DateEnd)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return ErrBookingConflict
+		}
+
+		// the repository checks the dates again and may find them taken
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		restored := *booking
+		restored.DeletedAt = nil
+		restored.DeletedBy = nil
+
+		return s.events.Publish(ctx, model.EventBookingRestored, id, model.BookingWithRoom{Booking: &restored})
+	})
+	if errors.Is(err, ErrBookingConflict) {
+		metrics.BookingsConflicts.Inc()
+	}
+
+	return err
+}
+
+func (s *BookingService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
 }
@@ -1,28 +1,65 @@
 package service
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/metrics"
 	"github.com/architectv/estate-task/pkg/model"
 	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
+// newTransactor returns a transactor that runs functions in place.
+func newTransactor(c *gomock.Controller) *mock_repository.MockTransactor {
+	tx := mock_repository.NewMockTransactor(c)
+	tx.EXPECT().WithinTx(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+
+	return tx
+}
+
+// newPublisher returns a publisher that accepts any event.
+func newPublisher(c *gomock.Controller) *mock_service.MockPublisher {
+	events := mock_service.NewMockPublisher(c)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	return events
+}
+
+// expectEvent returns a publisher expecting a single event of the type
+// about the aggregate, or none if the type is empty.
+func expectEvent(c *gomock.Controller, eventType string, aggregateId int) *mock_service.MockPublisher {
+	events := mock_service.NewMockPublisher(c)
+	if eventType != "" {
+		events.EXPECT().Publish(gomock.Any(), eventType, aggregateId, gomock.Any()).Return(nil)
+	}
+
+	return events
+}
+
 func TestBookingService_Create(t *testing.T) {
 	type args struct {
 		booking *model.Booking
 	}
 	type mockBehavior func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args)
 
+	guestId := 7
 	tests := []struct {
-		name    string
-		mock    mockBehavior
-		input   args
-		want    int
-		wantErr bool
+		name      string
+		mock      mockBehavior
+		input     args
+		want      int
+		wantEvent string
+		wantErr   bool
 	}{
 		{
 			name: "Ok",
@@ -34,11 +71,12 @@ func TestBookingService_Create(t *testing.T) {
 				},
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.booking.RoomId).Return(&model.Room{}, nil)
-				repo.EXPECT().Create(args.booking).Return(1, nil)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.booking.RoomId).Return(&model.Room{}, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
 			},
-			want:    1,
-			wantErr: false,
+			want:      1,
+			wantEvent: model.EventBookingCreated,
+			wantErr:   false,
 		},
 		{
 			name: "Wrong Room Id",
@@ -50,7 +88,7 @@ func TestBookingService_Create(t *testing.T) {
 				},
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.booking.RoomId).Return(nil, ErrWrongRoomId)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.booking.RoomId).Return(nil, ErrWrongRoomId)
 			},
 			wantErr: true,
 		},
@@ -63,9 +101,21 @@ func TestBookingService_Create(t *testing.T) {
 					DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
 				},
 			},
-			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.booking.RoomId).Return(&model.Room{}, nil)
+			mock:    func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {},
+			wantErr: true,
+		},
+		{
+			name: "Guest And Guest Id",
+			input: args{
+				booking: &model.Booking{
+					RoomId:    1,
+					DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+					DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+					GuestId:   &guestId,
+					Guest:     &model.Guest{Name: "Jane Doe", Phone: "+10000000000"},
+				},
 			},
+			mock:    func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {},
 			wantErr: true,
 		},
 		{
@@ -78,8 +128,8 @@ func TestBookingService_Create(t *testing.T) {
 				},
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.booking.RoomId).Return(&model.Room{}, nil)
-				repo.EXPECT().Create(args.booking).Return(0, ErrInternalService)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.booking.RoomId).Return(&model.Room{}, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(0, ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -93,9 +143,10 @@ func TestBookingService_Create(t *testing.T) {
 			repo := mock_repository.NewMockBooking(c)
 			roomRepo := mock_repository.NewMockRoom(c)
 			test.mock(repo, roomRepo, test.input)
-			s := &BookingService{repo: repo, roomRepo: roomRepo}
+			s := &BookingService{repo: repo, roomRepo: roomRepo, tx: newTransactor(c),
+				events: expectEvent(c, test.wantEvent, test.want)}
 
-			got, err := s.Create(test.input.booking)
+			got, err := s.Create(context.Background(), test.input.booking)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -108,45 +159,51 @@ func TestBookingService_Create(t *testing.T) {
 
 func TestBookingService_Delete(t *testing.T) {
 	type args struct {
-		id int
+		id        int
+		deletedBy string
 	}
 	type mockBehavior func(r *mock_repository.MockBooking, args args)
 
 	tests := []struct {
-		name    string
-		mock    mockBehavior
-		input   args
-		wantErr bool
+		name      string
+		mock      mockBehavior
+		input     args
+		wantEvent string
+		wantErr   bool
 	}{
 		{
 			name: "Ok",
 			input: args{
-				id: 1,
+				id:        1,
+				deletedBy: "operator",
 			},
 			mock: func(r *mock_repository.MockBooking, args args) {
-				r.EXPECT().GetById(args.id).Return(&model.Booking{}, nil)
-				r.EXPECT().Delete(args.id).Return(nil)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Booking{}, nil)
+				r.EXPECT().Delete(gomock.Any(), args.id, args.deletedBy).Return(nil)
 			},
-			wantErr: false,
+			wantEvent: model.EventBookingCancelled,
+			wantErr:   false,
 		},
 		{
 			name: "Wrong Booking Id",
 			input: args{
-				id: 1,
+				id:        1,
+				deletedBy: "operator",
 			},
 			mock: func(r *mock_repository.MockBooking, args args) {
-				r.EXPECT().GetById(args.id).Return(nil, ErrWrongBookingId)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, ErrWrongBookingId)
 			},
 			wantErr: true,
 		},
 		{
 			name: "DB Error",
 			input: args{
-				id: 1,
+				id:        1,
+				deletedBy: "operator",
 			},
 			mock: func(r *mock_repository.MockBooking, args args) {
-				r.EXPECT().GetById(args.id).Return(&model.Booking{}, nil)
-				r.EXPECT().Delete(args.id).Return(ErrInternalService)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Booking{}, nil)
+				r.EXPECT().Delete(gomock.Any(), args.id, args.deletedBy).Return(ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -160,9 +217,10 @@ func TestBookingService_Delete(t *testing.T) {
 			repo := mock_repository.NewMockBooking(c)
 			roomRepo := mock_repository.NewMockRoom(c)
 			test.mock(repo, test.input)
-			s := &BookingService{repo: repo, roomRepo: roomRepo}
+			s := &BookingService{repo: repo, roomRepo: roomRepo, tx: newTransactor(c),
+				events: expectEvent(c, test.wantEvent, test.input.id)}
 
-			err := s.Delete(test.input.id)
+			err := s.Delete(context.Background(), test.input.id, test.input.deletedBy)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -172,6 +230,130 @@ func TestBookingService_Delete(t *testing.T) {
 	}
 }
 
+func TestBookingService_GetByRoomIds(t *testing.T) {
+	type args struct {
+		roomIds []int
+	}
+	type mockBehavior func(r *mock_repository.MockBooking, args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    map[int][]*model.Booking
+		wantErr bool
+	}{
+		{
+			name: "Ok",
+			input: args{
+				roomIds: []int{1, 2, 3},
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetByRoomIds(gomock.Any(), args.roomIds).Return([]*model.Booking{
+					{Id: 1, RoomId: 1},
+					{Id: 2, RoomId: 1},
+					{Id: 3, RoomId: 2},
+				}, nil)
+			},
+			want: map[int][]*model.Booking{
+				1: {{Id: 1, RoomId: 1}, {Id: 2, RoomId: 1}},
+				2: {{Id: 3, RoomId: 2}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				roomIds: []int{1, 2, 3},
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetByRoomIds(gomock.Any(), args.roomIds).Return(nil, ErrInternalService)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockBooking(c)
+			test.mock(repo, test.input)
+			s := &BookingService{repo: repo}
+
+			got, err := s.GetByRoomIds(context.Background(), test.input.roomIds)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestBookingService_GetById(t *testing.T) {
+	type args struct {
+		id int
+	}
+	type mockBehavior func(r *mock_repository.MockBooking, args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    *model.Booking
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Booking{Id: 1, RoomId: 2}, nil)
+			},
+			want: &model.Booking{Id: 1, RoomId: 2},
+		},
+		{
+			name: "Wrong Booking Id",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: ErrWrongBookingId,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, ErrInternalService)
+			},
+			wantErr: ErrInternalService,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockBooking(c)
+			test.mock(repo, test.input)
+			s := &BookingService{repo: repo}
+
+			got, err := s.GetById(context.Background(), test.input.id)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
 func TestRoomService_GetByRoomId(t *testing.T) {
 	type args struct {
 		roomId int
@@ -191,7 +373,7 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 				roomId: 1,
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.roomId).Return(&model.Room{}, nil)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.roomId).Return(&model.Room{}, nil)
 				bookings := []*model.Booking{
 					{
 						Id:        1,
@@ -206,7 +388,7 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 						DateEnd:   time.Date(2021, time.January, 28, 0, 0, 0, 0, time.UTC),
 					},
 				}
-				repo.EXPECT().GetByRoomId(args.roomId).Return(bookings, nil)
+				repo.EXPECT().GetByRoomId(gomock.Any(), args.roomId).Return(bookings, nil)
 			},
 			want: []*model.Booking{
 				{
@@ -230,7 +412,7 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 				roomId: 1,
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.roomId).Return(nil, ErrWrongRoomId)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.roomId).Return(nil, ErrWrongRoomId)
 			},
 			wantErr: true,
 		},
@@ -240,8 +422,8 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 				roomId: 1,
 			},
 			mock: func(repo *mock_repository.MockBooking, roomRepo *mock_repository.MockRoom, args args) {
-				roomRepo.EXPECT().GetById(args.roomId).Return(&model.Room{}, nil)
-				repo.EXPECT().GetByRoomId(args.roomId).Return(nil, ErrInternalService)
+				roomRepo.EXPECT().GetById(gomock.Any(), args.roomId).Return(&model.Room{}, nil)
+				repo.EXPECT().GetByRoomId(gomock.Any(), args.roomId).Return(nil, ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -257,7 +439,7 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 			test.mock(repo, roomRepo, test.input)
 			s := &BookingService{repo: repo, roomRepo: roomRepo}
 
-			got, err := s.GetByRoomId(test.input.roomId)
+			got, err := s.GetByRoomId(context.Background(), test.input.roomId)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -267,3 +449,300 @@ func TestRoomService_GetByRoomId(t *testing.T) {
 		})
 	}
 }
+
+func TestBookingService_Restore(t *testing.T) {
+	type args struct {
+		id int
+	}
+	type mockBehavior func(r *mock_repository.MockBooking, args args)
+
+	booking := &model.Booking{
+		Id:        1,
+		RoomId:    1,
+		DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+		DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		mock          mockBehavior
+		input         args
+		wantEvent     string
+		wantErr       error
+		wantConflicts float64
+	}{
+		{
+			name: "Ok",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetDeletedById(gomock.Any(), args.id).Return(booking, nil)
+				r.EXPECT().HasOverlap(gomock.Any(), booking.RoomId, booking.DateStart, booking.DateEnd).Return(false, nil)
+				r.EXPECT().Restore(gomock.Any(), args.id).Return(nil)
+			},
+			wantEvent: model.EventBookingRestored,
+			wantErr:   nil,
+		},
+		{
+			name: "Wrong Booking Id",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetDeletedById(gomock.Any(), args.id).Return(nil, ErrWrongBookingId)
+			},
+			wantErr: ErrWrongBookingId,
+		},
+		{
+			name: "Conflict",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetDeletedById(gomock.Any(), args.id).Return(booking, nil)
+				r.EXPECT().HasOverlap(gomock.Any(), booking.RoomId, booking.DateStart, booking.DateEnd).Return(true, nil)
+			},
+			wantErr:       ErrBookingConflict,
+			wantConflicts: 1,
+		},
+		{
+			name: "Conflict On Restore",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetDeletedById(gomock.Any(), args.id).Return(booking, nil)
+				r.EXPECT().HasOverlap(gomock.Any(), booking.RoomId, booking.DateStart, booking.DateEnd).Return(false, nil)
+				r.EXPECT().Restore(gomock.Any(), args.id).Return(ErrBookingConflict)
+			},
+			wantErr:       ErrBookingConflict,
+			wantConflicts: 1,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetDeletedById(gomock.Any(), args.id).Return(booking, nil)
+				r.EXPECT().HasOverlap(gomock.Any(), booking.RoomId, booking.DateStart, booking.DateEnd).Return(false, nil)
+				r.EXPECT().Restore(gomock.Any(), args.id).Return(ErrInternalService)
+			},
+			wantErr: ErrInternalService,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockBooking(c)
+			roomRepo := mock_repository.NewMockRoom(c)
+			test.mock(repo, test.input)
+
+			s := &BookingService{repo: repo, roomRepo: roomRepo, tx: newTransactor(c),
+				events: expectEvent(c, test.wantEvent, test.input.id)}
+
+			conflicts := testutil.ToFloat64(metrics.BookingsConflicts)
+			err := s.Restore(context.Background(), test.input.id)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantConflicts, testutil.ToFloat64(metrics.BookingsConflicts)-conflicts)
+		})
+	}
+}
+
+func TestBookingService_Purge(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockBooking(c)
+	roomRepo := mock_repository.NewMockRoom(c)
+
+	retention := 24 * time.Hour
+	repo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, deletedBefore time.Time) (int64, error) {
+		assert.WithinDuration(t, time.Now().Add(-retention), deletedBefore, time.Minute)
+		return 2, nil
+	})
+
+	s := &BookingService{repo: repo, roomRepo: roomRepo}
+
+	got, err := s.Purge(context.Background(), retention)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got)
+}
+
+func TestBookingService_CreateWithGuest(t *testing.T) {
+	type args struct {
+		booking *model.Booking
+	}
+	type mockBehavior func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args)
+
+	guestId := 7
+	newBooking := func() *model.Booking {
+		return &model.Booking{
+			RoomId:    1,
+			DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+			DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	withGuestId := newBooking()
+	withGuestId.GuestId = &guestId
+	withGuest := newBooking()
+	withGuest.Guest = &model.Guest{Name: "John Smith", Email: "john@example.com"}
+	withNewGuest := newBooking()
+	withNewGuest.Guest = &model.Guest{Name: "Jane Doe", Phone: "+10000000000"}
+	withContacts := func() *model.Booking {
+		booking := newBooking()
+		booking.Guest = &model.Guest{Name: "Jane Doe", Email: "jane@example.com", Phone: "+10000000000"}
+		return booking
+	}
+	withSharedPhone := withContacts()
+	withTwoMatches := withContacts()
+
+	tests := []struct {
+		name        string
+		mock        mockBehavior
+		input       args
+		wantGuestId int
+		wantErr     error
+	}{
+		{
+			name: "Existing Guest Id",
+			input: args{
+				booking: withGuestId,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().GetById(gomock.Any(), guestId).Return(&model.Guest{Id: guestId}, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
+			},
+			wantGuestId: guestId,
+		},
+		{
+			name: "Returning Guest",
+			input: args{
+				booking: withGuest,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().FindByContact(gomock.Any(), "john@example.com", "").
+					Return([]*model.Guest{{Id: guestId, Email: "John@Example.com", Phone: "+10000000001"}}, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
+			},
+			wantGuestId: guestId,
+		},
+		{
+			name: "New Guest",
+			input: args{
+				booking: withNewGuest,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().FindByContact(gomock.Any(), "", "+10000000000").Return(nil, nil)
+				guestRepo.EXPECT().Create(gomock.Any(), args.booking.Guest).Return(guestId, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
+			},
+			wantGuestId: guestId,
+		},
+		{
+			name: "Contradicting Contacts",
+			input: args{
+				booking: withSharedPhone,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().FindByContact(gomock.Any(), "jane@example.com", "+10000000000").
+					Return([]*model.Guest{{Id: guestId, Email: "john@example.com", Phone: "+10000000000"}}, nil)
+				guestRepo.EXPECT().Create(gomock.Any(), args.booking.Guest).Return(8, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
+			},
+			wantGuestId: 8,
+		},
+		{
+			name: "Ambiguous Contacts",
+			input: args{
+				booking: withTwoMatches,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().FindByContact(gomock.Any(), "jane@example.com", "+10000000000").
+					Return([]*model.Guest{{Id: guestId, Email: "jane@example.com"}, {Id: 8, Phone: "+10000000000"}}, nil)
+				guestRepo.EXPECT().Create(gomock.Any(), args.booking.Guest).Return(9, nil)
+				repo.EXPECT().Create(gomock.Any(), args.booking).Return(1, nil)
+			},
+			wantGuestId: 9,
+		},
+		{
+			name: "Wrong Guest Id",
+			input: args{
+				booking: withGuestId,
+			},
+			mock: func(repo *mock_repository.MockBooking, guestRepo *mock_repository.MockGuest, args args) {
+				guestRepo.EXPECT().GetById(gomock.Any(), guestId).Return(nil, ErrWrongGuestId)
+			},
+			wantErr: ErrWrongGuestId,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockBooking(c)
+			roomRepo := mock_repository.NewMockRoom(c)
+			guestRepo := mock_repository.NewMockGuest(c)
+			roomRepo.EXPECT().GetById(gomock.Any(), test.input.booking.RoomId).Return(&model.Room{}, nil)
+			test.mock(repo, guestRepo, test.input)
+
+			s := &BookingService{repo: repo, roomRepo: roomRepo, guestRepo: guestRepo, tx: newTransactor(c),
+				events: newPublisher(c)}
+
+			_, err := s.Create(context.Background(), test.input.booking)
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, test.wantGuestId, *test.input.booking.GuestId)
+			}
+		})
+	}
+}
+
+func TestBookingService_CreateRetried(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	booking := &model.Booking{
+		RoomId:    1,
+		DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+		DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+		Guest:     &model.Guest{Name: "Jane Doe", Phone: "+10000000000"},
+	}
+
+	repo := mock_repository.NewMockBooking(c)
+	roomRepo := mock_repository.NewMockRoom(c)
+	guestRepo := mock_repository.NewMockGuest(c)
+	tx := mock_repository.NewMockTransactor(c)
+
+	// the first attempt fails after creating the guest and is run again
+	tx.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			if err := fn(ctx); err == nil {
+				t.Fatal("the first attempt was expected to fail")
+			}
+			return fn(ctx)
+		})
+	roomRepo.EXPECT().GetById(gomock.Any(), booking.RoomId).Return(&model.Room{}, nil).Times(2)
+	guestRepo.EXPECT().FindByContact(gomock.Any(), "", "+10000000000").Return(nil, nil).Times(2)
+	gomock.InOrder(
+		guestRepo.EXPECT().Create(gomock.Any(), booking.Guest).Return(7, nil),
+		guestRepo.EXPECT().Create(gomock.Any(), booking.Guest).Return(8, nil),
+	)
+	gomock.InOrder(
+		repo.EXPECT().Create(gomock.Any(), booking).Return(0, ErrInternalService),
+		repo.EXPECT().Create(gomock.Any(), booking).Return(1, nil),
+	)
+
+	s := &BookingService{repo: repo, roomRepo: roomRepo, guestRepo: guestRepo, tx: tx, events: newPublisher(c)}
+
+	id, err := s.Create(context.Background(), booking)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, 8, *booking.GuestId)
+}
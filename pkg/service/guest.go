@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+type GuestService struct {
+	repo        repository.Guest
+	bookingRepo repository.Booking
+}
+
+func NewGuestService(repo repository.Guest, bookingRepo repository.Booking) *GuestService {
+	return &GuestService{repo: repo, bookingRepo: bookingRepo}
+}
+
+func (s *GuestService) Create(ctx context.Context, guest *model.Guest) (int, error) {
+	trimGuest(guest)
+	if err := validation.Validate(guest); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Create(ctx, guest)
+}
+
+func (s *GuestService) GetById(ctx context.Context, id int) (*model.Guest, error) {
+	guest, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWrongGuestId)
+	}
+
+	return guest, nil
+}
+
+func (s *GuestService) Find(ctx context.Context, email, phone string) ([]*model.Guest, error) {
+	email, phone = strings.TrimSpace(email), strings.TrimSpace(phone)
+	if email == "" && phone == "" {
+		return nil, ErrEmptyGuestContact
+	}
+
+	return s.repo.FindByContact(ctx, email, phone)
+}
+
+func (s *GuestService) GetBookings(ctx context.Context, guestId int) ([]*model.Booking, error) {
+	_, err := s.repo.GetById(ctx, guestId)
+	if err != nil {
+		return nil, notFound(err, ErrWrongGuestId)
+	}
+
+	return s.bookingRepo.GetByGuestId(ctx, guestId)
+}
+
+func trimGuest(guest *model.Guest) {
+	guest.Name = strings.TrimSpace(guest.Name)
+	guest.Email = strings.TrimSpace(guest.Email)
+	guest.Phone = strings.TrimSpace(guest.Phone)
+}
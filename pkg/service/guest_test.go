@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestService_Create(t *testing.T) {
+	type args struct {
+		guest *model.Guest
+	}
+	type mockBehavior func(r *mock_repository.MockGuest, args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    int
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				guest: &model.Guest{
+					Name:  "John Smith",
+					Email: "john@example.com",
+				},
+			},
+			mock: func(r *mock_repository.MockGuest, args args) {
+				r.EXPECT().Create(gomock.Any(), args.guest).Return(1, nil)
+			},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "Empty Name",
+			input: args{
+				guest: &model.Guest{
+					Name:  "  ",
+					Email: "john@example.com",
+				},
+			},
+			mock:    func(r *mock_repository.MockGuest, args args) {},
+			wantErr: ErrEmptyGuestName,
+		},
+		{
+			name: "Wrong Email",
+			input: args{
+				guest: &model.Guest{
+					Name:  "John Smith",
+					Email: "john",
+				},
+			},
+			mock:    func(r *mock_repository.MockGuest, args args) {},
+			wantErr: ErrWrongEmail,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockGuest(c)
+			bookingRepo := mock_repository.NewMockBooking(c)
+			test.mock(repo, test.input)
+
+			s := &GuestService{repo: repo, bookingRepo: bookingRepo}
+
+			got, err := s.Create(context.Background(), test.input.guest)
+			if test.wantErr != nil {
+				assert.True(t, errors.Is(err, test.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGuestService_Find(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockGuest(c)
+	bookingRepo := mock_repository.NewMockBooking(c)
+
+	guests := []*model.Guest{{Id: 1, Name: "John Smith", Phone: "+10000000000"}}
+	repo.EXPECT().FindByContact(gomock.Any(), "", "+10000000000").Return(guests, nil)
+
+	s := &GuestService{repo: repo, bookingRepo: bookingRepo}
+
+	got, err := s.Find(context.Background(), "", " +10000000000 ")
+	assert.NoError(t, err)
+	assert.Equal(t, guests, got)
+
+	_, err = s.Find(context.Background(), "", "")
+	assert.Equal(t, ErrEmptyGuestContact, err)
+}
+
+func TestGuestService_GetBookings(t *testing.T) {
+	type args struct {
+		guestId int
+	}
+	type mockBehavior func(r *mock_repository.MockGuest, bookingRepo *mock_repository.MockBooking, args args)
+
+	bookings := []*model.Booking{
+		{
+			Id:        1,
+			RoomId:    2,
+			DateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+			DateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    []*model.Booking
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				guestId: 1,
+			},
+			mock: func(r *mock_repository.MockGuest, bookingRepo *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.guestId).Return(&model.Guest{}, nil)
+				bookingRepo.EXPECT().GetByGuestId(gomock.Any(), args.guestId).Return(bookings, nil)
+			},
+			want:    bookings,
+			wantErr: nil,
+		},
+		{
+			name: "Wrong Guest Id",
+			input: args{
+				guestId: 1,
+			},
+			mock: func(r *mock_repository.MockGuest, bookingRepo *mock_repository.MockBooking, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.guestId).Return(nil, ErrWrongGuestId)
+			},
+			wantErr: ErrWrongGuestId,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockGuest(c)
+			bookingRepo := mock_repository.NewMockBooking(c)
+			test.mock(repo, bookingRepo, test.input)
+
+			s := &GuestService{repo: repo, bookingRepo: bookingRepo}
+
+			got, err := s.GetBookings(context.Background(), test.input.guestId)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+)
+
+// Names of the readiness checks.
+const (
+	databaseCheck   = "database"
+	migrationsCheck = "migrations"
+	shutdownCheck   = "shutdown"
+)
+
+var errShuttingDown = errors.New("shutting down")
+
+type HealthService struct {
+	repo     repository.Health
+	draining atomic.Bool
+}
+
+func NewHealthService(repo repository.Health) *HealthService {
+	return &HealthService{repo: repo}
+}
+
+// Ready checks every dependency needed to serve requests. It fails once
+// Drain is called, while the other checks keep being reported.
+func (s *HealthService) Ready(ctx context.Context) *model.Health {
+	health := &model.Health{Status: model.StatusOk, Checks: map[string]*model.Check{}}
+	record := func(name string, err error) {
+		if err != nil {
+			health.Status = model.StatusFail
+			health.Checks[name] = &model.Check{Status: model.StatusFail, Error: err.Error()}
+			return
+		}
+		health.Checks[name] = &model.Check{Status: model.StatusOk}
+	}
+
+	if s.draining.Load() {
+		record(shutdownCheck, errShuttingDown)
+	}
+
+	err := s.repo.Ping(ctx)
+	record(databaseCheck, err)
+	if err != nil {
+		// the schema cannot be read without the database
+		record(migrationsCheck, err)
+	} else {
+		record(migrationsCheck, s.repo.CheckMigrations(ctx))
+	}
+
+	return health
+}
+
+// Drain makes the app report that it is not ready, so that load balancers
+// stop sending new requests before it shuts down.
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
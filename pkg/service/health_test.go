@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_Ready(t *testing.T) {
+	errConn := errors.New("connection refused")
+	errSchema := errors.New("schema version is 4, latest is 5")
+
+	tests := []struct {
+		name     string
+		mock     func(r *mock_repository.MockHealth)
+		draining bool
+		want     *model.Health
+	}{
+		{
+			name: "Ok",
+			mock: func(r *mock_repository.MockHealth) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().CheckMigrations(gomock.Any()).Return(nil)
+			},
+			want: &model.Health{Status: model.StatusOk, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusOk},
+				"migrations": {Status: model.StatusOk},
+			}},
+		},
+		{
+			name: "Database Down",
+			mock: func(r *mock_repository.MockHealth) {
+				r.EXPECT().Ping(gomock.Any()).Return(errConn)
+			},
+			want: &model.Health{Status: model.StatusFail, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusFail, Error: errConn.Error()},
+				"migrations": {Status: model.StatusFail, Error: errConn.Error()},
+			}},
+		},
+		{
+			name: "Pending Migrations",
+			mock: func(r *mock_repository.MockHealth) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().CheckMigrations(gomock.Any()).Return(errSchema)
+			},
+			want: &model.Health{Status: model.StatusFail, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusOk},
+				"migrations": {Status: model.StatusFail, Error: errSchema.Error()},
+			}},
+		},
+		{
+			name: "Draining",
+			mock: func(r *mock_repository.MockHealth) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().CheckMigrations(gomock.Any()).Return(nil)
+			},
+			draining: true,
+			want: &model.Health{Status: model.StatusFail, Checks: map[string]*model.Check{
+				"database":   {Status: model.StatusOk},
+				"migrations": {Status: model.StatusOk},
+				"shutdown":   {Status: model.StatusFail, Error: "shutting down"},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockHealth(c)
+			test.mock(repo)
+
+			s := NewHealthService(repo)
+			if test.draining {
+				s.Drain()
+			}
+
+			assert.Equal(t, test.want, s.Ready(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+)
+
+type IdempotencyService struct {
+	repo repository.Idempotency
+}
+
+func NewIdempotencyService(repo repository.Idempotency) *IdempotencyService {
+	return &IdempotencyService{repo: repo}
+}
+
+// Begin reserves the key for a request. It returns nil if the request
+// should be processed, or the stored key if the request is a retry and
+// its original response should be replayed.
+func (s *IdempotencyService) Begin(ctx context.Context, key, requestHash string) (*model.IdempotencyKey, error) {
+	reserved, err := s.repo.Reserve(ctx, key, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := s.repo.GetByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !stored.Completed() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return stored, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, contentType string, response []byte) error {
+	return s.repo.SaveResponse(ctx, key, statusCode, contentType, response)
+}
+
+// Release frees a reserved key so that the request can be retried.
+func (s *IdempotencyService) Release(ctx context.Context, key string) error {
+	return s.repo.Delete(ctx, key)
+}
+
+func (s *IdempotencyService) Purge(ctx context.Context, ttl time.Duration) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-ttl))
+}
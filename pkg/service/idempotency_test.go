@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	type args struct {
+		key         string
+		requestHash string
+	}
+	type mockBehavior func(r *mock_repository.MockIdempotency, args args)
+
+	statusCode := 200
+	completed := &model.IdempotencyKey{
+		Key:         "key",
+		RequestHash: "hash",
+		StatusCode:  &statusCode,
+		Response:    []byte(`{"booking_id":1}`),
+	}
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    *model.IdempotencyKey
+		wantErr error
+	}{
+		{
+			name: "New Key",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(r *mock_repository.MockIdempotency, args args) {
+				r.EXPECT().Reserve(gomock.Any(), args.key, args.requestHash).Return(true, nil)
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name: "Replay",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(r *mock_repository.MockIdempotency, args args) {
+				r.EXPECT().Reserve(gomock.Any(), args.key, args.requestHash).Return(false, nil)
+				r.EXPECT().GetByKey(gomock.Any(), args.key).Return(completed, nil)
+			},
+			want:    completed,
+			wantErr: nil,
+		},
+		{
+			name: "Different Request",
+			input: args{
+				key:         "key",
+				requestHash: "other hash",
+			},
+			mock: func(r *mock_repository.MockIdempotency, args args) {
+				r.EXPECT().Reserve(gomock.Any(), args.key, args.requestHash).Return(false, nil)
+				r.EXPECT().GetByKey(gomock.Any(), args.key).Return(completed, nil)
+			},
+			wantErr: ErrIdempotencyKeyReused,
+		},
+		{
+			name: "In Progress",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(r *mock_repository.MockIdempotency, args args) {
+				r.EXPECT().Reserve(gomock.Any(), args.key, args.requestHash).Return(false, nil)
+				r.EXPECT().GetByKey(gomock.Any(), args.key).Return(&model.IdempotencyKey{
+					Key:         "key",
+					RequestHash: "hash",
+				}, nil)
+			},
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				key:         "key",
+				requestHash: "hash",
+			},
+			mock: func(r *mock_repository.MockIdempotency, args args) {
+				r.EXPECT().Reserve(gomock.Any(), args.key, args.requestHash).Return(false, ErrInternalService)
+			},
+			wantErr: ErrInternalService,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockIdempotency(c)
+			test.mock(repo, test.input)
+
+			s := &IdempotencyService{repo: repo}
+
+			got, err := s.Begin(context.Background(), test.input.key, test.input.requestHash)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/broker"
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/google/uuid"
+)
+
+// OutboxConfig sets up the relay of outbox events.
+type OutboxConfig struct {
+	// Sinks name the sinks events are relayed to, like SinkWebhook.
+	Sinks []string
+	// Broker receives the events of the broker sink. Events go to an
+	// in-process broker when it is nil.
+	Broker broker.Broker
+	// Backoff is the delay after the first failed attempt, doubled after
+	// every next one up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Lease is how long claimed events are hidden from other relays.
+	Lease     time.Duration
+	BatchSize int
+}
+
+type OutboxService struct {
+	repo   repository.Outbox
+	tx     repository.Transactor
+	sinks  []Sink
+	config OutboxConfig
+}
+
+func NewOutboxService(repo repository.Outbox, tx repository.Transactor, sinks []Sink,
+	config OutboxConfig) *OutboxService {
+	return &OutboxService{repo: repo, tx: tx, sinks: sinks, config: config}
+}
+
+// Publish saves the event in the outbox. Called inside the transaction of
+// the change, the event is only relayed if it commits.
+func (s *OutboxService) Publish(ctx context.Context, eventType string, aggregateId int, data interface{}) error {
+	event := &model.Event{Id: uuid.NewString(), Type: eventType, CreatedAt: time.Now().UTC(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.Create(ctx, &model.OutboxEvent{
+		AggregateType: model.Aggregate(eventType),
+		AggregateId:   aggregateId,
+		EventId:       event.Id,
+		EventType:     eventType,
+		Payload:       payload,
+		NextAttemptAt: event.CreatedAt,
+	})
+
+	return err
+}
+
+// Relay publishes a batch of due events to every sink and returns how many
+// were claimed. An event is marked published in the transaction the sinks
+// run in, so sinks writing to the database get it exactly once and others
+// at least once. A failed event is retried with exponential backoff and
+// holds back the later events of its aggregate until it is published.
+func (s *OutboxService) Relay(ctx context.Context) (int, error) {
+	events, err := s.repo.Claim(ctx, time.Now(), s.config.Lease, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := make(map[string]bool)
+	for _, event := range events {
+		aggregate := event.AggregateKey()
+		if failed[aggregate] {
+			// claimed again after the failed event once it is due
+			continue
+		}
+
+		event.Attempts++
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.publish(ctx, event); err != nil {
+				return err
+			}
+
+			// event keeps its state for the update after a rollback
+			publishedAt := time.Now()
+			published := *event
+			published.LastError = nil
+			published.PublishedAt = &publishedAt
+			return s.repo.Update(ctx, &published)
+		})
+		if err == nil {
+			metrics.ObserveOutboxEvent("published")
+			continue
+		}
+
+		failed[aggregate] = true
+		lastError := err.Error()
+		event.LastError = &lastError
+		event.NextAttemptAt = time.Now().Add(backoff(s.config.Backoff, s.config.MaxBackoff, event.Attempts))
+		metrics.ObserveOutboxEvent("failed")
+		logging.FromContext(ctx).Warnf("failed to relay outbox event %d of %s after %d attempts: %s",
+			event.Id, aggregate, event.Attempts, lastError)
+		if err := s.repo.Update(ctx, event); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+func (s *OutboxService) publish(ctx context.Context, event *model.OutboxEvent) error {
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Purge removes the events published longer than retention ago.
+func (s *OutboxService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/broker"
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	mock_service "github.com/architectv/estate-task/pkg/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOutboxConfig = OutboxConfig{
+	Backoff:    time.Second,
+	MaxBackoff: time.Minute,
+	Lease:      time.Minute,
+	BatchSize:  10,
+}
+
+func TestOutboxService_Publish(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockOutbox(c)
+	var created *model.OutboxEvent
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *model.OutboxEvent) (int, error) {
+			created = event
+			return 1, nil
+		})
+	s := NewOutboxService(repo, newTransactor(c), nil, testOutboxConfig)
+
+	booking := &model.Booking{Id: 7, RoomId: 2}
+	err := s.Publish(context.Background(), model.EventBookingCreated, 7, booking)
+	require.NoError(t, err)
+	assert.Equal(t, "booking", created.AggregateType)
+	assert.Equal(t, 7, created.AggregateId)
+	assert.Equal(t, model.EventBookingCreated, created.EventType)
+	assert.WithinDuration(t, time.Now(), created.NextAttemptAt, time.Minute)
+
+	var event struct {
+		Id   string          `json:"event_id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(created.Payload, &event))
+	assert.Equal(t, created.EventId, event.Id)
+	assert.Equal(t, model.EventBookingCreated, event.Type)
+	data, err := json.Marshal(booking)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(event.Data))
+}
+
+func TestOutboxService_Relay(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	events := []*model.OutboxEvent{
+		{Id: 1, AggregateType: "room", AggregateId: 1, EventType: model.EventRoomCreated},
+		{Id: 2, AggregateType: "booking", AggregateId: 1, EventType: model.EventBookingCreated, Attempts: 2},
+		{Id: 3, AggregateType: "room", AggregateId: 2, EventType: model.EventRoomCreated},
+		{Id: 4, AggregateType: "booking", AggregateId: 1, EventType: model.EventBookingCancelled},
+	}
+
+	repo := mock_repository.NewMockOutbox(c)
+	repo.EXPECT().Claim(gomock.Any(), gomock.Any(), time.Minute, 10).Return(events, nil)
+
+	sink := mock_service.NewMockSink(c)
+	sink.EXPECT().Name().AnyTimes().Return("test")
+	var published []int
+	sink.EXPECT().Publish(gomock.Any(), gomock.Any()).Times(3).DoAndReturn(
+		func(_ context.Context, event *model.OutboxEvent) error {
+			if event.Id == 2 {
+				return errors.New("unavailable")
+			}
+			published = append(published, event.Id)
+			return nil
+		})
+
+	updated := make(map[int]*model.OutboxEvent)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(3).DoAndReturn(
+		func(_ context.Context, event *model.OutboxEvent) error {
+			updated[event.Id] = event
+			return nil
+		})
+
+	s := NewOutboxService(repo, newTransactor(c), []Sink{sink}, testOutboxConfig)
+	claimed, err := s.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, claimed)
+	assert.Equal(t, []int{1, 3}, published)
+
+	require.NotNil(t, updated[1].PublishedAt)
+	assert.Equal(t, 1, updated[1].Attempts)
+
+	// the failed event waits for a retry, the next event of its booking
+	// is left alone
+	failed := updated[2]
+	assert.Nil(t, failed.PublishedAt)
+	assert.Equal(t, 3, failed.Attempts)
+	assert.Equal(t, "test sink: unavailable", *failed.LastError)
+	assert.WithinDuration(t, time.Now().Add(4*time.Second), failed.NextAttemptAt, time.Second)
+	assert.NotContains(t, updated, 4)
+}
+
+func TestOutboxService_RelayUpdateError(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockOutbox(c)
+	repo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		[]*model.OutboxEvent{{Id: 1, AggregateType: "room", AggregateId: 1}}, nil)
+	sink := mock_service.NewMockSink(c)
+	sink.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+
+	// marking the event published failed, so it is retried as not published
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(ErrInternalService)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *model.OutboxEvent) error {
+			assert.Nil(t, event.PublishedAt)
+			assert.Equal(t, ErrInternalService.Error(), *event.LastError)
+			return nil
+		})
+
+	s := NewOutboxService(repo, newTransactor(c), []Sink{sink}, testOutboxConfig)
+	claimed, err := s.Relay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+}
+
+func TestBrokerSink_Publish(t *testing.T) {
+	b := broker.NewLocal()
+	var got *broker.Message
+	b.Subscribe(model.EventRoomDeleted, func(_ context.Context, message *broker.Message) error {
+		got = message
+		return nil
+	})
+
+	sink := NewBrokerSink(b)
+	err := sink.Publish(context.Background(), &model.OutboxEvent{
+		AggregateType: "room",
+		AggregateId:   3,
+		EventType:     model.EventRoomDeleted,
+		Payload:       []byte(`{"type":"room.deleted"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &broker.Message{
+		Topic: model.EventRoomDeleted,
+		Key:   "room:3",
+		Data:  []byte(`{"type":"room.deleted"}`),
+	}, got)
+}
@@ -1,40 +1,66 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/validation"
 )
 
 type RoomService struct {
-	repo repository.Room
+	repo   repository.Room
+	tx     repository.Transactor
+	events Publisher
 }
 
-func NewRoomService(repo repository.Room) *RoomService {
-	return &RoomService{repo: repo}
+func NewRoomService(repo repository.Room, tx repository.Transactor, events Publisher) *RoomService {
+	return &RoomService{repo: repo, tx: tx, events: events}
 }
 
-func (s *RoomService) Create(room *model.Room) (int, error) {
-	if room.Description == "" {
-		return 0, ErrEmptyDescription
-	}
-	if room.Price <= 0 {
-		return 0, ErrNotPositivePrice
+func (s *RoomService) Create(ctx context.Context, room *model.Room) (int, error) {
+	if err := validation.Validate(room); err != nil {
+		return 0, err
 	}
 
-	return s.repo.Create(room)
-}
+	var id int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.repo.Create(ctx, room)
+		if err != nil {
+			return err
+		}
+
+		created := *room
+		created.Id = id
 
-func (s *RoomService) Delete(id int) error {
-	_, err := s.repo.GetById(id)
+		return s.events.Publish(ctx, model.EventRoomCreated, id, &created)
+	})
 	if err != nil {
-		return ErrWrongRoomId
+		return 0, err
 	}
 
-	return s.repo.Delete(id)
+	return id, nil
+}
+
+func (s *RoomService) Delete(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		room, err := s.repo.GetById(ctx, id)
+		if err != nil {
+			return notFound(err, ErrWrongRoomId)
+		}
+
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, model.EventRoomDeleted, id, room)
+	})
 }
 
-func (s *RoomService) GetAll(sortField string) ([]*model.Room, error) {
+func (s *RoomService) GetAll(ctx context.Context, sortField string) ([]*model.Room, error) {
 	const (
 		idField    = "id"
 		priceField = "price"
@@ -55,5 +81,22 @@ func (s *RoomService) GetAll(sortField string) ([]*model.Room, error) {
 		return nil, ErrWrongSortField
 	}
 
-	return s.repo.GetAll(sortField, desc)
+	return s.repo.GetAll(ctx, sortField, desc)
+}
+
+func (s *RoomService) GetById(ctx context.Context, id int) (*model.Room, error) {
+	room, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWrongRoomId)
+	}
+
+	return room, nil
+}
+
+func (s *RoomService) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	if !dateStart.Before(dateEnd) {
+		return nil, ErrWrongDates
+	}
+
+	return s.repo.GetAvailable(ctx, dateStart, dateEnd)
 }
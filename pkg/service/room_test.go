@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	. "github.com/architectv/estate-task/pkg/error"
 	"github.com/architectv/estate-task/pkg/model"
@@ -17,11 +21,12 @@ func TestRoomService_Create(t *testing.T) {
 	type mockBehavior func(r *mock_repository.MockRoom, args args)
 
 	tests := []struct {
-		name    string
-		mock    mockBehavior
-		input   args
-		want    int
-		wantErr bool
+		name      string
+		mock      mockBehavior
+		input     args
+		want      int
+		wantEvent string
+		wantErr   bool
 	}{
 		{
 			name: "Ok",
@@ -32,10 +37,11 @@ func TestRoomService_Create(t *testing.T) {
 				},
 			},
 			mock: func(r *mock_repository.MockRoom, args args) {
-				r.EXPECT().Create(args.room).Return(1, nil)
+				r.EXPECT().Create(gomock.Any(), args.room).Return(1, nil)
 			},
-			want:    1,
-			wantErr: false,
+			want:      1,
+			wantEvent: model.EventRoomCreated,
+			wantErr:   false,
 		},
 		{
 			name: "Empty Description",
@@ -68,7 +74,7 @@ func TestRoomService_Create(t *testing.T) {
 				},
 			},
 			mock: func(r *mock_repository.MockRoom, args args) {
-				r.EXPECT().Create(args.room).Return(0, ErrInternalService)
+				r.EXPECT().Create(gomock.Any(), args.room).Return(0, ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -81,9 +87,9 @@ func TestRoomService_Create(t *testing.T) {
 
 			repo := mock_repository.NewMockRoom(c)
 			test.mock(repo, test.input)
-			s := &RoomService{repo: repo}
+			s := &RoomService{repo: repo, tx: newTransactor(c), events: expectEvent(c, test.wantEvent, test.want)}
 
-			got, err := s.Create(test.input.room)
+			got, err := s.Create(context.Background(), test.input.room)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -101,10 +107,11 @@ func TestRoomService_Delete(t *testing.T) {
 	type mockBehavior func(r *mock_repository.MockRoom, args args)
 
 	tests := []struct {
-		name    string
-		mock    mockBehavior
-		input   args
-		wantErr bool
+		name      string
+		mock      mockBehavior
+		input     args
+		wantEvent string
+		wantErr   bool
 	}{
 		{
 			name: "Ok",
@@ -112,10 +119,11 @@ func TestRoomService_Delete(t *testing.T) {
 				id: 1,
 			},
 			mock: func(r *mock_repository.MockRoom, args args) {
-				r.EXPECT().GetById(args.id).Return(&model.Room{}, nil)
-				r.EXPECT().Delete(args.id).Return(nil)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Room{}, nil)
+				r.EXPECT().Delete(gomock.Any(), args.id).Return(nil)
 			},
-			wantErr: false,
+			wantEvent: model.EventRoomDeleted,
+			wantErr:   false,
 		},
 		{
 			name: "Wrong Room Id",
@@ -123,7 +131,7 @@ func TestRoomService_Delete(t *testing.T) {
 				id: 1,
 			},
 			mock: func(r *mock_repository.MockRoom, args args) {
-				r.EXPECT().GetById(args.id).Return(nil, ErrWrongRoomId)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, ErrWrongRoomId)
 			},
 			wantErr: true,
 		},
@@ -133,8 +141,8 @@ func TestRoomService_Delete(t *testing.T) {
 				id: 1,
 			},
 			mock: func(r *mock_repository.MockRoom, args args) {
-				r.EXPECT().GetById(args.id).Return(&model.Room{}, nil)
-				r.EXPECT().Delete(args.id).Return(ErrInternalService)
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Room{}, nil)
+				r.EXPECT().Delete(gomock.Any(), args.id).Return(ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -147,9 +155,9 @@ func TestRoomService_Delete(t *testing.T) {
 
 			repo := mock_repository.NewMockRoom(c)
 			test.mock(repo, test.input)
-			s := &RoomService{repo: repo}
+			s := &RoomService{repo: repo, tx: newTransactor(c), events: expectEvent(c, test.wantEvent, test.input.id)}
 
-			err := s.Delete(test.input.id)
+			err := s.Delete(context.Background(), test.input.id)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -159,6 +167,77 @@ func TestRoomService_Delete(t *testing.T) {
 	}
 }
 
+func TestRoomService_GetById(t *testing.T) {
+	type args struct {
+		id int
+	}
+	type mockBehavior func(r *mock_repository.MockRoom, args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    *model.Room
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockRoom, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(&model.Room{Id: 1, Description: "description", Price: 1000}, nil)
+			},
+			want: &model.Room{Id: 1, Description: "description", Price: 1000},
+		},
+		{
+			name: "Wrong Room Id",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockRoom, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, sql.ErrNoRows)
+			},
+			wantErr: ErrWrongRoomId,
+		},
+		{
+			name: "DB Error",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockRoom, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, ErrInternalService)
+			},
+			wantErr: ErrInternalService,
+		},
+		{
+			name: "Timeout",
+			input: args{
+				id: 1,
+			},
+			mock: func(r *mock_repository.MockRoom, args args) {
+				r.EXPECT().GetById(gomock.Any(), args.id).Return(nil, context.DeadlineExceeded)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockRoom(c)
+			test.mock(repo, test.input)
+			s := &RoomService{repo: repo}
+
+			got, err := s.GetById(context.Background(), test.input.id)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
 func TestRoomService_GetAll(t *testing.T) {
 	type args struct {
 		sortField string
@@ -183,7 +262,7 @@ func TestRoomService_GetAll(t *testing.T) {
 					{Id: 2, Description: "description2", Price: 5000},
 					{Id: 3, Description: "description3", Price: 3000},
 				}
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(rooms, nil)
 			},
 			want: []*model.Room{
 				{Id: 1, Description: "description1", Price: 1000},
@@ -203,7 +282,7 @@ func TestRoomService_GetAll(t *testing.T) {
 					{Id: 2, Description: "description2", Price: 5000},
 					{Id: 3, Description: "description3", Price: 3000},
 				}
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(rooms, nil)
 			},
 			want: []*model.Room{
 				{Id: 1, Description: "description1", Price: 1000},
@@ -223,7 +302,7 @@ func TestRoomService_GetAll(t *testing.T) {
 					{Id: 3, Description: "description3", Price: 3000},
 					{Id: 2, Description: "description2", Price: 5000},
 				}
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(rooms, nil)
 			},
 			want: []*model.Room{
 				{Id: 1, Description: "description1", Price: 1000},
@@ -243,7 +322,7 @@ func TestRoomService_GetAll(t *testing.T) {
 					{Id: 3, Description: "description2", Price: 3000},
 					{Id: 1, Description: "description1", Price: 1000},
 				}
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(rooms, nil)
 			},
 			want: []*model.Room{
 				{Id: 2, Description: "description3", Price: 5000},
@@ -263,7 +342,7 @@ func TestRoomService_GetAll(t *testing.T) {
 					{Id: 2, Description: "description2", Price: 5000},
 					{Id: 3, Description: "description3", Price: 3000},
 				}
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(rooms, nil)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(rooms, nil)
 			},
 			want: []*model.Room{
 				{Id: 1, Description: "description1", Price: 1000},
@@ -302,7 +381,7 @@ func TestRoomService_GetAll(t *testing.T) {
 				sortField: "id",
 			},
 			mock: func(r *mock_repository.MockRoom) {
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, ErrInternalService)
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, ErrInternalService)
 			},
 			wantErr: true,
 		},
@@ -317,7 +396,7 @@ func TestRoomService_GetAll(t *testing.T) {
 			test.mock(repo)
 			s := &RoomService{repo: repo}
 
-			got, err := s.GetAll(test.input.sortField)
+			got, err := s.GetAll(context.Background(), test.input.sortField)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -327,3 +406,72 @@ func TestRoomService_GetAll(t *testing.T) {
 		})
 	}
 }
+
+func TestRoomService_GetAvailable(t *testing.T) {
+	type args struct {
+		dateStart time.Time
+		dateEnd   time.Time
+	}
+	type mockBehavior func(r *mock_repository.MockRoom, args args)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		input   args
+		want    []*model.Room
+		wantErr error
+	}{
+		{
+			name: "Ok",
+			input: args{
+				dateStart: time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(r *mock_repository.MockRoom, args args) {
+				r.EXPECT().GetAvailable(gomock.Any(), args.dateStart, args.dateEnd).Return([]*model.Room{{Id: 1}}, nil)
+			},
+			want: []*model.Room{{Id: 1}},
+		},
+		{
+			name: "Wrong Dates",
+			input: args{
+				dateStart: time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+				dateEnd:   time.Date(2021, time.January, 8, 0, 0, 0, 0, time.UTC),
+			},
+			mock:    func(r *mock_repository.MockRoom, args args) {},
+			wantErr: ErrWrongDates,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockRoom(c)
+			test.mock(repo, test.input)
+			s := &RoomService{repo: repo}
+
+			got, err := s.GetAvailable(context.Background(), test.input.dateStart, test.input.dateEnd)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRoomService_CreateCollectsErrors(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockRoom(c)
+	s := &RoomService{repo: repo}
+
+	_, err := s.Create(context.Background(), &model.Room{Description: "", Price: -1})
+
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Len(t, validationErr.Errors, 2)
+	}
+	assert.True(t, errors.Is(err, ErrEmptyDescription))
+	assert.True(t, errors.Is(err, ErrNotPositivePrice))
+}
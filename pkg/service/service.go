@@ -1,30 +1,126 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
 	"github.com/architectv/estate-task/pkg/model"
 	"github.com/architectv/estate-task/pkg/repository"
 )
 
 type Room interface {
-	Create(room *model.Room) (int, error)
-	Delete(id int) error
-	GetAll(sortField string) ([]*model.Room, error)
+	Create(ctx context.Context, room *model.Room) (int, error)
+	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context, sortField string) ([]*model.Room, error)
+	GetById(ctx context.Context, id int) (*model.Room, error)
+	GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error)
 }
 
 type Booking interface {
-	Create(booking *model.Booking) (int, error)
-	Delete(id int) error
-	GetByRoomId(roomId int) ([]*model.Booking, error)
+	Create(ctx context.Context, booking *model.Booking) (int, error)
+	Delete(ctx context.Context, id int, deletedBy string) error
+	GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error)
+	GetByRoomIds(ctx context.Context, roomIds []int) (map[int][]*model.Booking, error)
+	GetById(ctx context.Context, id int) (*model.Booking, error)
+	GetDeleted(ctx context.Context) ([]*model.Booking, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type Guest interface {
+	Create(ctx context.Context, guest *model.Guest) (int, error)
+	GetById(ctx context.Context, id int) (*model.Guest, error)
+	Find(ctx context.Context, email, phone string) ([]*model.Guest, error)
+	GetBookings(ctx context.Context, guestId int) ([]*model.Booking, error)
+}
+
+type Idempotency interface {
+	Begin(ctx context.Context, key, requestHash string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, response []byte) error
+	Release(ctx context.Context, key string) error
+	Purge(ctx context.Context, ttl time.Duration) (int64, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error)
+	Update(ctx context.Context, webhook *model.Webhook) error
+	Delete(ctx context.Context, id int) error
+	GetAll(ctx context.Context) ([]*model.Webhook, error)
+	GetById(ctx context.Context, id int) (*model.Webhook, error)
+	GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error)
+	Replay(ctx context.Context, deliveryId int) (int, error)
+	Deliver(ctx context.Context) (int, error)
+}
+
+// Publisher announces changes to subscribers. It is called inside the
+// transaction of the change, so that nothing is announced for changes
+// that are rolled back. The id is that of the room or booking changed.
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, aggregateId int, data interface{}) error
+}
+
+type Outbox interface {
+	Relay(ctx context.Context) (int, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type APIKey interface {
+	Create(ctx context.Context, key *model.APIKey) (*model.APIKey, error)
+	GetAll(ctx context.Context) ([]*model.APIKey, error)
+	Revoke(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, key string) (*model.APIKey, error)
+	Bootstrap(ctx context.Context, name string) (*model.APIKey, error)
+}
+
+type Health interface {
+	Ready(ctx context.Context) *model.Health
+	Drain()
 }
 
 type Service struct {
 	Room
 	Booking
+	Guest
+	Idempotency
+	Webhook
+	Outbox
+	APIKey
+	Health
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, webhookConfig WebhookConfig, outboxConfig OutboxConfig) *Service {
+	webhooks := NewWebhookService(repos.Webhook, repos.Transactor, webhookConfig)
+	outbox := NewOutboxService(repos.Outbox, repos.Transactor, newSinks(outboxConfig, webhooks), outboxConfig)
+
 	return &Service{
-		Room:    NewRoomService(repos.Room),
-		Booking: NewBookingService(repos.Booking, repos.Room),
+		Room: &roomTracing{NewRoomService(repos.Room, repos.Transactor, outbox)},
+		Booking: &bookingTracing{NewBookingService(repos.Booking, repos.Room, repos.Guest,
+			repos.Transactor, outbox)},
+		Guest:       NewGuestService(repos.Guest, repos.Booking),
+		Idempotency: NewIdempotencyService(repos.Idempotency),
+		Webhook:     webhooks,
+		Outbox:      outbox,
+		APIKey:      NewAPIKeyService(repos.APIKey, repos.Transactor),
+		Health:      NewHealthService(repos.Health),
 	}
 }
+
+// notFound reports a lookup that found no row as the wrong id error. Other
+// errors, such as serialization failures the transaction retries on, are
+// returned unchanged.
+func notFound(err, wrongId error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return wrongId
+	}
+
+	return err
+}
+
+// ReadFromPrimary makes the calls made with the returned context read
+// from the primary database rather than a replica, so that they see the
+// writes made just before.
+func ReadFromPrimary(ctx context.Context) context.Context {
+	return repository.WithPrimary(ctx)
+}
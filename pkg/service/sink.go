@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/architectv/estate-task/pkg/broker"
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+// Names of the sinks outbox events can be relayed to.
+const (
+	SinkLog     = "log"
+	SinkWebhook = "webhook"
+	SinkBroker  = "broker"
+)
+
+// SinkNames lists every sink name.
+var SinkNames = []string{SinkLog, SinkWebhook, SinkBroker}
+
+// Sink receives the events relayed from the outbox. An error makes the
+// relay publish the event to every sink again later.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event *model.OutboxEvent) error
+}
+
+// LogSink writes events to the log.
+type LogSink struct{}
+
+func (LogSink) Name() string {
+	return SinkLog
+}
+
+func (LogSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	logging.FromContext(ctx).WithFields(logrus.Fields{
+		"event_id":       event.EventId,
+		"event_type":     event.EventType,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateId,
+	}).Info("event published")
+
+	return nil
+}
+
+// WebhookSink queues events for the webhooks subscribed to them.
+type WebhookSink struct {
+	webhooks *WebhookService
+}
+
+func NewWebhookSink(webhooks *WebhookService) *WebhookSink {
+	return &WebhookSink{webhooks: webhooks}
+}
+
+func (s *WebhookSink) Name() string {
+	return SinkWebhook
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	return s.webhooks.Enqueue(ctx, event)
+}
+
+// BrokerSink publishes events to a message broker. The topic is the event
+// type and the key is the aggregate, so that brokers keep the events of
+// an aggregate in order.
+type BrokerSink struct {
+	broker broker.Broker
+}
+
+func NewBrokerSink(b broker.Broker) *BrokerSink {
+	return &BrokerSink{broker: b}
+}
+
+func (s *BrokerSink) Name() string {
+	return SinkBroker
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event *model.OutboxEvent) error {
+	return s.broker.Publish(ctx, &broker.Message{
+		Topic: event.EventType,
+		Key:   event.AggregateKey(),
+		Data:  event.Payload,
+	})
+}
+
+// newSinks returns the sinks named by the config, which must have been
+// validated.
+func newSinks(config OutboxConfig, webhooks *WebhookService) []Sink {
+	sinks := make([]Sink, 0, len(config.Sinks))
+	for _, name := range config.Sinks {
+		switch name {
+		case SinkLog:
+			sinks = append(sinks, LogSink{})
+		case SinkWebhook:
+			sinks = append(sinks, NewWebhookSink(webhooks))
+		case SinkBroker:
+			b := config.Broker
+			if b == nil {
+				b = broker.NewLocal()
+			}
+			sinks = append(sinks, NewBrokerSink(b))
+		default:
+			panic(fmt.Sprintf("unknown outbox sink %q", name))
+		}
+	}
+
+	return sinks
+}
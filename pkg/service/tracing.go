@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("service")
+
+// endSpan ends a span of a service call that returned err.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// roomTracing starts a span for every call of the room service.
+type roomTracing struct {
+	service Room
+}
+
+func (s *roomTracing) Create(ctx context.Context, room *model.Room) (int, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.Create")
+	id, err := s.service.Create(ctx, room)
+	endSpan(span, err)
+	return id, err
+}
+
+func (s *roomTracing) Delete(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "RoomService.Delete")
+	err := s.service.Delete(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (s *roomTracing) GetAll(ctx context.Context, sortField string) ([]*model.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetAll")
+	rooms, err := s.service.GetAll(ctx, sortField)
+	endSpan(span, err)
+	return rooms, err
+}
+
+func (s *roomTracing) GetById(ctx context.Context, id int) (*model.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetById")
+	room, err := s.service.GetById(ctx, id)
+	endSpan(span, err)
+	return room, err
+}
+
+func (s *roomTracing) GetAvailable(ctx context.Context, dateStart, dateEnd time.Time) ([]*model.Room, error) {
+	ctx, span := tracer.Start(ctx, "RoomService.GetAvailable")
+	rooms, err := s.service.GetAvailable(ctx, dateStart, dateEnd)
+	endSpan(span, err)
+	return rooms, err
+}
+
+// bookingTracing starts a span for every call of the booking service.
+type bookingTracing struct {
+	service Booking
+}
+
+func (s *bookingTracing) Create(ctx context.Context, booking *model.Booking) (int, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.Create")
+	id, err := s.service.Create(ctx, booking)
+	endSpan(span, err)
+	return id, err
+}
+
+func (s *bookingTracing) Delete(ctx context.Context, id int, deletedBy string) error {
+	ctx, span := tracer.Start(ctx, "BookingService.Delete")
+	err := s.service.Delete(ctx, id, deletedBy)
+	endSpan(span, err)
+	return err
+}
+
+func (s *bookingTracing) GetByRoomId(ctx context.Context, roomId int) ([]*model.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetByRoomId")
+	bookings, err := s.service.GetByRoomId(ctx, roomId)
+	endSpan(span, err)
+	return bookings, err
+}
+
+func (s *bookingTracing) GetByRoomIds(ctx context.Context, roomIds []int) (map[int][]*model.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetByRoomIds")
+	bookings, err := s.service.GetByRoomIds(ctx, roomIds)
+	endSpan(span, err)
+	return bookings, err
+}
+
+func (s *bookingTracing) GetById(ctx context.Context, id int) (*model.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetById")
+	booking, err := s.service.GetById(ctx, id)
+	endSpan(span, err)
+	return booking, err
+}
+
+func (s *bookingTracing) GetDeleted(ctx context.Context) ([]*model.Booking, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.GetDeleted")
+	bookings, err := s.service.GetDeleted(ctx)
+	endSpan(span, err)
+	return bookings, err
+}
+
+func (s *bookingTracing) Restore(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "BookingService.Restore")
+	err := s.service.Restore(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (s *bookingTracing) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := tracer.Start(ctx, "BookingService.Purge")
+	purged, err := s.service.Purge(ctx, retention)
+	endSpan(span, err)
+	return purged, err
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/logging"
+	"github.com/architectv/estate-task/pkg/metrics"
+	"github.com/architectv/estate-task/pkg/model"
+	"github.com/architectv/estate-task/pkg/repository"
+	"github.com/architectv/estate-task/pkg/validation"
+)
+
+// Headers of webhook requests. The signature is the hex encoded
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed by the secret
+// of the webhook, prefixed with "sha256=".
+const (
+	EventHeader     = "X-Estate-Event"
+	EventIdHeader   = "X-Estate-Event-Id"
+	DeliveryHeader  = "X-Estate-Delivery"
+	TimestampHeader = "X-Estate-Timestamp"
+	SignatureHeader = "X-Estate-Signature"
+)
+
+// maxResponseBody is how much of a webhook response is read so that the
+// connection can be reused.
+const maxResponseBody = 64 << 10
+
+// WebhookConfig sets up the delivery of webhooks.
+type WebhookConfig struct {
+	// MaxAttempts is how many times a delivery is sent before it is
+	// marked dead.
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt, doubled after
+	// every next one up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Lease is how long claimed deliveries are hidden from other workers.
+	Lease     time.Duration
+	BatchSize int
+	// Timeout limits every request to a webhook.
+	Timeout time.Duration
+}
+
+type WebhookService struct {
+	repo   repository.Webhook
+	tx     repository.Transactor
+	config WebhookConfig
+	client *http.Client
+}
+
+func NewWebhookService(repo repository.Webhook, tx repository.Transactor, config WebhookConfig) *WebhookService {
+	return &WebhookService{repo: repo, tx: tx, config: config, client: &http.Client{Timeout: config.Timeout}}
+}
+
+// Create saves the webhook and returns it with its secret, which is not
+// shown again. A secret is generated unless one is given.
+func (s *WebhookService) Create(ctx context.Context, webhook *model.Webhook) (*model.Webhook, error) {
+	if err := validation.Validate(webhook); err != nil {
+		return nil, err
+	}
+
+	created := *webhook
+	created.CreatedAt = time.Now().UTC()
+	if created.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		created.Secret = secret
+	}
+
+	id, err := s.repo.Create(ctx, &created)
+	if err != nil {
+		return nil, err
+	}
+	created.Id = id
+
+	return &created, nil
+}
+
+// Update replaces the webhook. Its secret is kept unless a new one is given.
+func (s *WebhookService) Update(ctx context.Context, webhook *model.Webhook) error {
+	if err := validation.Validate(webhook); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		found, err := s.repo.GetById(ctx, webhook.Id)
+		if err != nil {
+			return notFound(err, ErrWrongWebhookId)
+		}
+
+		updated := *webhook
+		if updated.Secret == "" {
+			updated.Secret = found.Secret
+		}
+
+		return s.repo.Update(ctx, &updated)
+	})
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.GetById(ctx, id); err != nil {
+			return notFound(err, ErrWrongWebhookId)
+		}
+
+		return s.repo.Delete(ctx, id)
+	})
+}
+
+func (s *WebhookService) GetAll(ctx context.Context) ([]*model.Webhook, error) {
+	webhooks, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) GetById(ctx context.Context, id int) (*model.Webhook, error) {
+	webhook, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWrongWebhookId)
+	}
+	webhook.Secret = ""
+
+	return webhook, nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookId int) ([]*model.WebhookDelivery, error) {
+	if _, err := s.repo.GetById(ctx, webhookId); err != nil {
+		return nil, notFound(err, ErrWrongWebhookId)
+	}
+
+	return s.repo.GetDeliveries(ctx, webhookId)
+}
+
+// Replay queues the event of a delivery to be sent again, whatever became
+// of it, and returns the id of the new delivery.
+func (s *WebhookService) Replay(ctx context.Context, deliveryId int) (int, error) {
+	delivery, err := s.repo.GetDeliveryById(ctx, deliveryId)
+	if err != nil {
+		return 0, notFound(err, ErrWrongDeliveryId)
+	}
+
+	return s.repo.CreateDelivery(ctx, &model.WebhookDelivery{
+		WebhookId:     delivery.WebhookId,
+		EventId:       delivery.EventId,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        model.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// Enqueue queues the outbox event for every active webhook subscribed
+// to it.
+func (s *WebhookService) Enqueue(ctx context.Context, event *model.OutboxEvent) error {
+	webhooks, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event.EventType) {
+			continue
+		}
+
+		_, err := s.repo.CreateDelivery(ctx, &model.WebhookDelivery{
+			WebhookId:     webhook.Id,
+			EventId:       event.EventId,
+			EventType:     event.EventType,
+			Payload:       event.Payload,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver sends a batch of due deliveries and returns how many were
+// attempted. Failed deliveries are retried with exponential backoff until
+// they run out of attempts and are marked dead.
+func (s *WebhookService) Deliver(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDeliveries(ctx, time.Now(), s.config.Lease, s.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[int]*model.Webhook)
+	for i, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookId]
+		if !ok {
+			webhook, err = s.repo.GetById(ctx, delivery.WebhookId)
+			if err != nil {
+				return i, err
+			}
+			webhooks[delivery.WebhookId] = webhook
+		}
+
+		if webhook.Active {
+			s.attempt(ctx, webhook, delivery)
+		} else {
+			lastError := "webhook is inactive"
+			delivery.Status = model.DeliveryDead
+			delivery.LastError = &lastError
+		}
+
+		if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+			return i + 1, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and records the outcome in it.
+func (s *WebhookService) attempt(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) {
+	statusCode, err := s.send(ctx, webhook, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	case delivery.Attempts >= s.config.MaxAttempts:
+		lastError := err.Error()
+		delivery.Status = model.DeliveryDead
+		delivery.LastError = &lastError
+		logging.FromContext(ctx).Errorf("webhook delivery %d is dead after %d attempts: %s",
+			delivery.Id, delivery.Attempts, lastError)
+	default:
+		lastError := err.Error()
+		delivery.LastError = &lastError
+		delivery.NextAttemptAt = now.Add(backoff(s.config.Backoff, s.config.MaxBackoff, delivery.Attempts))
+	}
+	metrics.ObserveWebhookDelivery(delivery.Status)
+}
+
+// send posts the payload of the delivery to the webhook and returns the
+// status code of the response, if any.
+func (s *WebhookService) send(ctx context.Context, webhook *model.Webhook,
+	delivery *model.WebhookDelivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(EventIdHeader, delivery.EventId)
+	request.Header.Set(DeliveryHeader, strconv.Itoa(delivery.Id))
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, Signature(webhook.Secret, timestamp, delivery.Payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBody))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given
+// number of failed ones, doubling base up to max.
+func backoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// Signature signs a webhook request body sent at the unix timestamp.
+func Signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("cannot generate webhook secret: %w", err)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/architectv/estate-task/pkg/model"
+	mock_repository "github.com/architectv/estate-task/pkg/repository/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWebhookConfig = WebhookConfig{
+	MaxAttempts: 3,
+	Backoff:     10 * time.Second,
+	MaxBackoff:  time.Minute,
+	Lease:       time.Minute,
+	BatchSize:   10,
+	Timeout:     time.Second,
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	type mockBehavior func(r *mock_repository.MockWebhook)
+
+	tests := []struct {
+		name       string
+		mock       mockBehavior
+		input      *model.Webhook
+		wantSecret string
+		wantErr    bool
+	}{
+		{
+			name: "Generated Secret",
+			input: &model.Webhook{
+				URL:    "https://example.com/hook",
+				Events: model.EventList{model.EventBookingCreated},
+				Active: true,
+			},
+			mock: func(r *mock_repository.MockWebhook) {
+				r.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
+			},
+		},
+		{
+			name: "Given Secret",
+			input: &model.Webhook{
+				URL:    "https://example.com/hook",
+				Secret: "secret",
+				Events: model.EventList{model.EventBookingCreated},
+			},
+			mock: func(r *mock_repository.MockWebhook) {
+				r.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
+			},
+			wantSecret: "secret",
+		},
+		{
+			name: "Wrong URL",
+			input: &model.Webhook{
+				URL:    "ftp://example.com/hook",
+				Events: model.EventList{model.EventBookingCreated},
+			},
+			mock:    func(r *mock_repository.MockWebhook) {},
+			wantErr: true,
+		},
+		{
+			name: "Unknown Event",
+			input: &model.Webhook{
+				URL:    "https://example.com/hook",
+				Events: model.EventList{"booking.updated"},
+			},
+			mock:    func(r *mock_repository.MockWebhook) {},
+			wantErr: true,
+		},
+		{
+			name: "DB Error",
+			input: &model.Webhook{
+				URL:    "https://example.com/hook",
+				Events: model.EventList{model.EventBookingCreated},
+			},
+			mock: func(r *mock_repository.MockWebhook) {
+				r.EXPECT().Create(gomock.Any(), gomock.Any()).Return(0, ErrInternalService)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_repository.NewMockWebhook(c)
+			test.mock(repo)
+			s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+
+			got, err := s.Create(context.Background(), test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, got.Id)
+			assert.WithinDuration(t, time.Now(), got.CreatedAt, time.Minute)
+			if test.wantSecret != "" {
+				assert.Equal(t, test.wantSecret, got.Secret)
+			} else {
+				assert.Len(t, got.Secret, 64)
+			}
+		})
+	}
+}
+
+func TestWebhookService_Update(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockWebhook(c)
+	s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+	webhook := &model.Webhook{Id: 1, URL: "https://example.com/hook", Events: model.EventList{model.EventRoomCreated}}
+
+	repo.EXPECT().GetById(gomock.Any(), 1).Return(&model.Webhook{Id: 1, Secret: "secret"}, nil)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, updated *model.Webhook) error {
+			assert.Equal(t, "secret", updated.Secret)
+			assert.Equal(t, webhook.URL, updated.URL)
+			return nil
+		})
+	assert.NoError(t, s.Update(context.Background(), webhook))
+
+	repo.EXPECT().GetById(gomock.Any(), 1).Return(nil, sql.ErrNoRows)
+	assert.Equal(t, ErrWrongWebhookId, s.Update(context.Background(), webhook))
+}
+
+func TestWebhookService_GetAll(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockWebhook(c)
+	repo.EXPECT().GetAll(gomock.Any()).Return([]*model.Webhook{{Id: 1, Secret: "secret"}}, nil)
+	s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+
+	webhooks, err := s.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Webhook{{Id: 1}}, webhooks)
+}
+
+func TestWebhookService_Enqueue(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockWebhook(c)
+	repo.EXPECT().GetAll(gomock.Any()).Return([]*model.Webhook{
+		{Id: 1, Events: model.EventList{model.EventRoomCreated}, Active: true},
+		{Id: 2, Events: model.EventList{model.EventRoomDeleted}, Active: true},
+		{Id: 3, Events: model.EventList{model.EventRoomCreated}, Active: false},
+		{Id: 4, Events: model.EventList{model.EventRoomDeleted, model.EventRoomCreated}, Active: true},
+	}, nil)
+
+	var deliveries []*model.WebhookDelivery
+	repo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, delivery *model.WebhookDelivery) (int, error) {
+			deliveries = append(deliveries, delivery)
+			return len(deliveries), nil
+		})
+	s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+
+	event := &model.OutboxEvent{
+		Id:            9,
+		AggregateType: "room",
+		AggregateId:   5,
+		EventId:       "4b1b4c1e-5a0b-4bb0-a6b5-8a3e0b4b2f1d",
+		EventType:     model.EventRoomCreated,
+		Payload:       []byte(`{"event_id":"4b1b4c1e-5a0b-4bb0-a6b5-8a3e0b4b2f1d"}`),
+	}
+	require.NoError(t, s.Enqueue(context.Background(), event))
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, 1, deliveries[0].WebhookId)
+	assert.Equal(t, 4, deliveries[1].WebhookId)
+	for _, delivery := range deliveries {
+		assert.Equal(t, event.EventId, delivery.EventId)
+		assert.Equal(t, event.EventType, delivery.EventType)
+		assert.Equal(t, event.Payload, delivery.Payload)
+		assert.Equal(t, model.DeliveryPending, delivery.Status)
+	}
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+
+	tests := []struct {
+		name         string
+		status       int
+		attempts     int
+		active       bool
+		wantStatus   string
+		wantAttempts int
+		wantBackoff  time.Duration
+		wantRequest  bool
+	}{
+		{
+			name:         "Delivered",
+			status:       http.StatusNoContent,
+			active:       true,
+			wantStatus:   model.DeliveryDelivered,
+			wantAttempts: 1,
+			wantRequest:  true,
+		},
+		{
+			name:         "Failed",
+			status:       http.StatusInternalServerError,
+			attempts:     1,
+			active:       true,
+			wantStatus:   model.DeliveryPending,
+			wantAttempts: 2,
+			wantBackoff:  20 * time.Second,
+			wantRequest:  true,
+		},
+		{
+			name:         "Dead",
+			status:       http.StatusInternalServerError,
+			attempts:     2,
+			active:       true,
+			wantStatus:   model.DeliveryDead,
+			wantAttempts: 3,
+			wantRequest:  true,
+		},
+		{
+			name:       "Inactive",
+			status:     http.StatusOK,
+			active:     false,
+			wantStatus: model.DeliveryDead,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			var requests []request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				requests = append(requests, request{header: r.Header, body: body})
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			payload := []byte(`{"event_id":"event","type":"room.created"}`)
+			delivery := &model.WebhookDelivery{
+				Id:        7,
+				WebhookId: 1,
+				EventId:   "event",
+				EventType: model.EventRoomCreated,
+				Payload:   payload,
+				Status:    model.DeliveryPending,
+				Attempts:  test.attempts,
+			}
+			webhook := &model.Webhook{Id: 1, URL: server.URL, Secret: "secret", Active: test.active}
+
+			repo := mock_repository.NewMockWebhook(c)
+			repo.EXPECT().ClaimDeliveries(gomock.Any(), gomock.Any(), testWebhookConfig.Lease, testWebhookConfig.BatchSize).
+				Return([]*model.WebhookDelivery{delivery}, nil)
+			repo.EXPECT().GetById(gomock.Any(), 1).Return(webhook, nil)
+			var updated *model.WebhookDelivery
+			repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, delivery *model.WebhookDelivery) error {
+					updated = delivery
+					return nil
+				})
+			s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+
+			attempted, err := s.Deliver(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, attempted)
+			assert.Equal(t, test.wantStatus, updated.Status)
+			assert.Equal(t, test.wantAttempts, updated.Attempts)
+			if test.wantBackoff != 0 {
+				assert.WithinDuration(t, time.Now().Add(test.wantBackoff), updated.NextAttemptAt, time.Second)
+			}
+			if test.wantStatus == model.DeliveryDelivered {
+				assert.NotNil(t, updated.DeliveredAt)
+				assert.Nil(t, updated.LastError)
+			} else {
+				assert.NotNil(t, updated.LastError)
+			}
+
+			if !test.wantRequest {
+				assert.Empty(t, requests)
+				return
+			}
+			require.Len(t, requests, 1)
+			header := requests[0].header
+			assert.Equal(t, payload, requests[0].body)
+			assert.Equal(t, model.EventRoomCreated, header.Get(EventHeader))
+			assert.Equal(t, "event", header.Get(EventIdHeader))
+			assert.Equal(t, "7", header.Get(DeliveryHeader))
+			assert.Equal(t, Signature("secret", header.Get(TimestampHeader), payload), header.Get(SignatureHeader))
+			timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+			assert.Equal(t, &test.status, updated.LastStatusCode)
+		})
+	}
+}
+
+func Test_backoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{50, time.Minute},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, backoff(10*time.Second, time.Minute, test.attempts), "after %d attempts", test.attempts)
+	}
+}
+
+func TestWebhookService_Replay(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := mock_repository.NewMockWebhook(c)
+	s := NewWebhookService(repo, newTransactor(c), testWebhookConfig)
+	lastError := "unexpected status 500"
+	dead := &model.WebhookDelivery{
+		Id: 3, WebhookId: 1, EventId: "event", EventType: model.EventRoomCreated,
+		Payload: []byte(`{}`), Status: model.DeliveryDead, Attempts: 3, LastError: &lastError,
+	}
+
+	repo.EXPECT().GetDeliveryById(gomock.Any(), 3).Return(dead, nil)
+	repo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, delivery *model.WebhookDelivery) (int, error) {
+			assert.Equal(t, 1, delivery.WebhookId)
+			assert.Equal(t, "event", delivery.EventId)
+			assert.Equal(t, model.DeliveryPending, delivery.Status)
+			assert.Equal(t, 0, delivery.Attempts)
+			assert.Nil(t, delivery.LastError)
+			return 4, nil
+		})
+	id, err := s.Replay(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	repo.EXPECT().GetDeliveryById(gomock.Any(), 5).Return(nil, sql.ErrNoRows)
+	_, err = s.Replay(context.Background(), 5)
+	assert.Equal(t, ErrWrongDeliveryId, err)
+}
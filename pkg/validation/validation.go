@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	. "github.com/architectv/estate-task/pkg/error"
+)
+
+// Validatable is implemented by models that check their own fields.
+type Validatable interface {
+	Validate(v *Validator)
+}
+
+// Bindable is a Validatable that can be decoded from a JSON object field
+// by field. Fields maps every accepted JSON key to its destination pointer.
+type Bindable interface {
+	Validatable
+	Fields() map[string]interface{}
+}
+
+// Validator collects field errors instead of stopping at the first one.
+type Validator struct {
+	prefix string
+	result *result
+}
+
+type result struct {
+	errors []*Error
+	failed map[string]bool
+}
+
+func New() *Validator {
+	return &Validator{result: &result{failed: make(map[string]bool)}}
+}
+
+// Add records err for its field. Only the first error of a field is kept,
+// so that a malformed value is not also reported as missing.
+func (v *Validator) Add(err *Error) {
+	field := v.prefix + err.Field
+	if v.result.failed[field] {
+		return
+	}
+	v.result.failed[field] = true
+	v.result.errors = append(v.result.errors, err.WithField(field))
+}
+
+// Check records err unless ok holds.
+func (v *Validator) Check(ok bool, err *Error) {
+	if !ok {
+		v.Add(err)
+	}
+}
+
+// Nested returns a validator for an embedded object whose fields are
+// reported with the given prefix, e.g. "guest.".
+func (v *Validator) Nested(prefix string) *Validator {
+	return &Validator{prefix: v.prefix + prefix, result: v.result}
+}
+
+// Err returns a *ValidationError with every collected error, or nil.
+func (v *Validator) Err() error {
+	if len(v.result.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.result.errors}
+}
+
+// Validate runs the checks of item.
+func Validate(item Validatable) error {
+	v := New()
+	item.Validate(v)
+	return v.Err()
+}
+
+// Decode fills item from a JSON object, reporting unknown fields and
+// fields of a wrong type.
+func Decode(data []byte, item Bindable) error {
+	v := New()
+	if err := decode(data, item, v); err != nil {
+		return err
+	}
+	return v.Err()
+}
+
+// Bind decodes item like Decode and validates it, reporting all
+// decoding and validation errors together.
+func Bind(data []byte, item Bindable) error {
+	v := New()
+	if err := decode(data, item, v); err != nil {
+		return err
+	}
+	item.Validate(v)
+	return v.Err()
+}
+
+func decode(data []byte, item Bindable, v *Validator) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(bytes.TrimSpace(data), &raw); err != nil {
+		return ErrWrongBody.WithDetail("reason", err.Error())
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := item.Fields()
+	for _, key := range keys {
+		dest, ok := fields[key]
+		if !ok {
+			v.Add(ErrUnknownField.WithField(key))
+			continue
+		}
+		if nested, ok := nestedItem(raw[key], dest); ok {
+			// errors of an embedded object are reported per field, e.g. "guest.name"
+			if err := decode(raw[key], nested, v.Nested(key+".")); err != nil {
+				v.Add(ErrWrongFieldType.WithField(key))
+			}
+			continue
+		}
+		if err := json.Unmarshal(raw[key], dest); err != nil {
+			var fieldErr *Error
+			if errors.As(err, &fieldErr) {
+				v.Add(fieldErr.WithField(key))
+			} else {
+				v.Add(ErrWrongFieldType.WithField(key))
+			}
+		}
+	}
+
+	return nil
+}
+
+var bindableType = reflect.TypeOf((*Bindable)(nil)).Elem()
+
+// nestedItem returns the Bindable an embedded JSON object is decoded into
+// when dest points to one, allocating it if dest is a pointer to a nil
+// pointer. Values other than objects are left to json.Unmarshal.
+func nestedItem(data json.RawMessage, dest interface{}) (Bindable, bool) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil, false
+	}
+	if item, ok := dest.(Bindable); ok {
+		return item, true
+	}
+
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Ptr ||
+		!value.Elem().Type().Implements(bindableType) {
+		return nil, false
+	}
+	if value.Elem().IsNil() {
+		value.Elem().Set(reflect.New(value.Elem().Type().Elem()))
+	}
+
+	return value.Elem().Interface().(Bindable), true
+}
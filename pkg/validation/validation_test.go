@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/architectv/estate-task/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	Name  string
+	Count int
+	Child *testItem
+}
+
+func (i *testItem) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"name":  &i.Name,
+		"count": &i.Count,
+		"child": &i.Child,
+	}
+}
+
+func (i *testItem) Validate(v *Validator) {
+	v.Check(i.Name != "", ErrRequiredField.WithField("name"))
+	v.Check(i.Count > 0, ErrRequiredField.WithField("count"))
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		want       *testItem
+		wantErrs   []FieldError
+		wantBodyOk bool
+	}{
+		{
+			name:       "Ok",
+			input:      `{"name": "test", "count": 2}`,
+			want:       &testItem{Name: "test", Count: 2},
+			wantBodyOk: true,
+		},
+		{
+			name:  "All Errors",
+			input: `{"count": "two", "size": 1}`,
+			wantErrs: []FieldError{
+				{Field: "count", Code: "wrong_type", Message: "field has wrong type"},
+				{Field: "size", Code: "unknown_field", Message: "unknown field"},
+				{Field: "name", Code: "required", Message: "field is required"},
+			},
+			wantBodyOk: true,
+		},
+		{
+			name:  "Nested Errors",
+			input: `{"name": "test", "count": 2, "child": {"name": 1, "size": 1}}`,
+			wantErrs: []FieldError{
+				{Field: "child.name", Code: "wrong_type", Message: "field has wrong type"},
+				{Field: "child.size", Code: "unknown_field", Message: "unknown field"},
+			},
+			wantBodyOk: true,
+		},
+		{
+			name:       "Nested Ok",
+			input:      `{"name": "test", "count": 2, "child": {"name": "inner"}}`,
+			want:       &testItem{Name: "test", Count: 2, Child: &testItem{Name: "inner"}},
+			wantBodyOk: true,
+		},
+		{
+			name:       "Not An Object",
+			input:      `[1, 2]`,
+			wantBodyOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := &testItem{}
+			err := Bind([]byte(test.input), item)
+
+			if !test.wantBodyOk {
+				assert.True(t, errors.Is(err, ErrWrongBody))
+				return
+			}
+			if test.wantErrs == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, item)
+				return
+			}
+
+			var domainErr *Error
+			if assert.True(t, errors.As(err, &domainErr)) {
+				assert.Equal(t, ErrValidation.Code, domainErr.Code)
+				assert.Equal(t, test.wantErrs, domainErr.Details["errors"])
+			}
+		})
+	}
+}
+
+func TestValidator_Nested(t *testing.T) {
+	v := New()
+	nested := v.Nested("guest.")
+	nested.Add(ErrRequiredField.WithField("name"))
+	nested.Add(ErrWrongEmail)
+	nested.Add(ErrRequiredField.WithField("email"))
+
+	err := v.Err()
+
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Len(t, validationErr.Errors, 2)
+		assert.Equal(t, "guest.name", validationErr.Errors[0].Field)
+		assert.Equal(t, "guest.email", validationErr.Errors[1].Field)
+	}
+	assert.True(t, errors.Is(err, ErrWrongEmail))
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS bookings_deleted_at_index;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE bookings DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+ALTER TABLE bookings ADD COLUMN deleted_at timestamptz;
+ALTER TABLE bookings ADD COLUMN deleted_by text;
+
+CREATE INDEX bookings_deleted_at_index ON bookings (deleted_at);
@@ -0,0 +1,10 @@
+CREATE TABLE idempotency_keys (
+    key text PRIMARY KEY,
+    request_hash text NOT NULL,
+    status_code int,
+    content_type text,
+    response bytea,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idempotency_keys_created_at_index ON idempotency_keys (created_at);
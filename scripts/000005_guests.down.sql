@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS bookings_guest_id_index;
+ALTER TABLE bookings DROP COLUMN IF EXISTS guest_id;
+
+DROP TABLE IF EXISTS guests;
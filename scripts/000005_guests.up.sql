@@ -0,0 +1,15 @@
+CREATE TABLE guests (
+    id serial PRIMARY KEY,
+    name text NOT NULL,
+    email text NOT NULL DEFAULT '',
+    phone text NOT NULL DEFAULT '',
+    document_type text NOT NULL DEFAULT '',
+    document_number text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX guests_email_index ON guests (lower(email));
+CREATE INDEX guests_phone_index ON guests (phone);
+
+ALTER TABLE bookings ADD COLUMN guest_id int REFERENCES guests (id) ON DELETE SET NULL;
+
+CREATE INDEX bookings_guest_id_index ON bookings (guest_id);
@@ -0,0 +1,26 @@
+CREATE TABLE webhooks (
+    id serial PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+    id serial PRIMARY KEY,
+    webhook_id int REFERENCES webhooks (id) ON DELETE CASCADE NOT NULL,
+    event_id text NOT NULL,
+    event_type text NOT NULL,
+    payload bytea NOT NULL,
+    status text NOT NULL,
+    attempts int NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_error text,
+    last_status_code int,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    delivered_at timestamptz
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_index ON webhook_deliveries (webhook_id);
+CREATE INDEX webhook_deliveries_due_index ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    id serial PRIMARY KEY,
+    name text NOT NULL,
+    role text NOT NULL,
+    prefix text NOT NULL,
+    hash text NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    revoked_at timestamptz
+);
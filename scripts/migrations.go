@@ -0,0 +1,14 @@
+// Package scripts embeds the SQL migrations into the binary.
+package scripts
+
+import "embed"
+
+// Migrations are the Postgres migrations.
+//
+//go:embed *.sql
+var Migrations embed.FS
+
+// SQLiteMigrations are the SQLite migrations, kept in the sqlite directory.
+//
+//go:embed sqlite/*.sql
+var SQLiteMigrations embed.FS
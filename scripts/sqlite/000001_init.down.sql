@@ -0,0 +1,4 @@
+DROP TABLE idempotency_keys;
+DROP TABLE bookings;
+DROP TABLE guests;
+DROP TABLE rooms;
@@ -0,0 +1,42 @@
+CREATE TABLE rooms (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    description text NOT NULL,
+    price integer NOT NULL CHECK (price > 0)
+);
+
+CREATE TABLE guests (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    email text NOT NULL DEFAULT '',
+    phone text NOT NULL DEFAULT '',
+    document_type text NOT NULL DEFAULT '',
+    document_number text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX guests_email_index ON guests (lower(email));
+CREATE INDEX guests_phone_index ON guests (phone);
+
+CREATE TABLE bookings (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    room_id integer NOT NULL REFERENCES rooms (id) ON DELETE CASCADE,
+    date_start date NOT NULL,
+    date_end date NOT NULL CHECK (date_start < date_end),
+    deleted_at timestamp,
+    deleted_by text,
+    guest_id integer REFERENCES guests (id) ON DELETE SET NULL
+);
+
+CREATE INDEX bookings_room_id_index ON bookings (room_id);
+CREATE INDEX bookings_deleted_at_index ON bookings (deleted_at);
+CREATE INDEX bookings_guest_id_index ON bookings (guest_id);
+
+CREATE TABLE idempotency_keys (
+    key text PRIMARY KEY,
+    request_hash text NOT NULL,
+    status_code integer,
+    content_type text,
+    response blob,
+    created_at timestamp NOT NULL
+);
+
+CREATE INDEX idempotency_keys_created_at_index ON idempotency_keys (created_at);
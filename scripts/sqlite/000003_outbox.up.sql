@@ -0,0 +1,16 @@
+CREATE TABLE outbox (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    aggregate_type text NOT NULL,
+    aggregate_id integer NOT NULL,
+    event_id text NOT NULL,
+    event_type text NOT NULL,
+    payload blob NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp NOT NULL,
+    last_error text,
+    created_at timestamp NOT NULL,
+    published_at timestamp
+);
+
+CREATE INDEX outbox_pending_index ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_index ON outbox (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    role text NOT NULL,
+    prefix text NOT NULL,
+    hash text NOT NULL UNIQUE,
+    created_at timestamp NOT NULL,
+    revoked_at timestamp
+);